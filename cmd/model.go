@@ -8,7 +8,8 @@ import (
 
 type (
 	modelFlags struct {
-		opts model.Opts
+		opts    model.Opts
+		dialect string
 	}
 )
 
@@ -48,6 +49,11 @@ column_name refers to the column name defined in the model. The go field type
 must also be the same between the model and the query. sql_type is optional and
 ignored for queries. Likewise, fields without a "model" tag are ignored.
 
+A query field may instead be a pointer to the go field type of the model, in
+which case it is optional. Only patcheq queries may be specified on a query
+struct with optional fields, and they only update the columns of optional
+fields which are not nil.
+
 A separate schema file (model.json by default) is used to specify additional
 constraints, conditions, and queries. The schema is as follows:
 
@@ -60,7 +66,16 @@ constraints, conditions, and queries. The schema is as follows:
           ],
           "indicies": [
             {"columns": ["col1", "etc"]}
-          ]
+          ],
+          "returning": ["col1", "etc"],
+          "filter": false,
+          "softDelete": "col1",
+          "version": "col1",
+          "autoTime": {
+            "insert": ["col1", "etc"],
+            "update": ["col1", "etc"],
+            "source": "clock (default)/sql"
+          }
         },
         "queries": {
           "StructName": [
@@ -68,17 +83,134 @@ constraints, conditions, and queries. The schema is as follows:
               "kind": "getoneeq/getgroup/etc.",
               "name": "QueryName",
               "conditions": [
-                {"col": "col1", "cond": "eq (default)/neq/etc."}
+                {"col": "col1", "cond": "eq (default)/neq/etc."},
+                {"any": [{"col": "col2"}, {"all": [{"col": "col3"}]}]}
               ],
               "order": [
                 {"col": "col1", "dir": "empty/ASC/DESC/etc."}
-              ]
+              ],
+              "sort": [
+                {"name": "SortName", "order": [{"col": "col1", "dir": "DESC"}]}
+              ],
+              "conflict": ["col1", "etc"],
+              "update": ["col2", "etc"],
+              "stream": false,
+              "returning": "StructName",
+              "affected": "count/notfound",
+              "having": [
+                {"col": "count(*)", "cond": "gt"}
+              ],
+              "sql": "SELECT {{columns}} FROM {{table}} WHERE {{col1}} = :name",
+              "sqlfile": "query.sql",
+              "params": [
+                {"name": "name", "col": "col1"}
+              ],
+              "many": false,
+              "missing": false,
+              "lock": {"mode": "update/share", "wait": "empty/nowait/skiplocked"}
+            }
+          ]
+        }
+      }
+    }
+
+A join query struct selects columns of multiple models joined by their fields.
+It is specified by the following comment directive:
+
+    //forge:model:join joinPrefix
+    JoinStruct struct {}
+
+Its fields have a "model" tag with the following syntax:
+
+    modelPrefix.column_name[,sql_type]
+
+Join queries are specified in the top level "joins" of the schema file:
+
+    {
+      "models": {},
+      "joins": {
+        "joinPrefix": {
+          "from": "modelPrefix1",
+          "join": [
+            {
+              "model": "modelPrefix2",
+              "kind": "inner (default)/left",
+              "on": [{"col": "modelPrefix2.col1", "ref": "modelPrefix1.col1"}]
+            }
+          ],
+          "queries": [
+            {
+              "kind": "getoneeq/getgroup/etc.",
+              "name": "QueryName",
+              "conditions": [{"col": "modelPrefix2.col2"}]
             }
           ]
         }
       }
     }
 
+The tables are aliased t1, t2, etc. in order, and a {joinPrefix}ModelTable
+struct is generated with a {ModelPrefix}TableName field for the table name of
+each model. col of on must be a field of the joined model, and ref must be a
+field of a model before it. Fields of the query struct, conditions, and order
+refer to columns by modelPrefix.column_name. The input of a condition is
+prefixed by its model, e.g. postUserid, if its field shares a name with a field
+of another model in the conditions. Only getoneeq, getgroup, getgroupeq, count,
+counteq, existseq, and aggregate are valid for joins. A query field may be a
+pointer to the go field type of the model for columns which may be null, such as
+those of a left join.
+
+returning of the model lists columns which are generated by the database, such
+as DEFAULT or serial columns. They are omitted from inserts, and Insert scans
+their generated values back into the model. InsertBulk does not. returning is
+not supported by the mysql dialect.
+
+If filter of the model is true, a Filter method of the model table returns a
+query builder for conditions on the model fields which are only known at
+runtime. It has Where{Field}{Cond} methods for the conditions Eq, Neq, Lt, Leq,
+Gt, Geq, In, Like (string fields only), IsNull, and NotNull, which are joined
+with AND, and Order{Field}, Limit, and Offset methods. A Get{Struct} method for
+each query struct of the model selects the matching rows into that struct.
+Inputs are always bound as query parameters. The input of Like is a raw LIKE
+pattern with an ESCAPE of sqldb.LikeEscape, and literal text within it may be
+escaped by sqldb.EscapeLike. A negative Limit is not applied.
+
+softDelete of the model names a column which is stamped when a row is soft
+deleted, and must have a go field type of *time.Time or *int64 (unix seconds).
+Rows where the column is not null are deleted. deleq instead updates the column
+of rows which are not deleted, and a HardDel{Name} method deletes the rows.
+getoneeq, getgroup, getgroupeq, getgroupkeyset, getmapin, count, counteq,
+existseq, and aggregate exclude deleted rows, and an additional
+{Name}IncludingDeleted variant of each includes them. The deleted rows of joined
+models are excluded by their join condition. The Filter query builder excludes
+deleted rows unless its IncludingDeleted method is called. Other query kinds,
+including raw, do not exclude deleted rows.
+
+version of the model names a column of go field type int or int64 which is
+incremented by every updeq. The query struct of an updeq must include the
+version column, and the update only affects rows whose version equals the
+version of the input. The version of the input is incremented on success, and
+sqldb.ErrConflict is returned if no rows are affected, i.e. the row has been
+updated concurrently or does not exist. Versioned updeq may not have returning
+or affected. increq, patcheq, and upsert with update columns are not valid for
+a versioned model, since they would update rows without checking the version.
+
+insert and update of autoTime of the model list columns which are set to the
+current time by Insert and InsertBulk, and by updeq, increq, and patcheq
+respectively. Update time columns are set by every update whether or not they
+are fields of its query struct, and are set rather than incremented by increq.
+upsert inserts both insert and update time columns with the current time whether
+or not they are fields of its query struct, and on conflict sets update time
+columns and keeps insert time columns, unless it updates no columns. If source
+is clock, the current time is from the Clock of the model table, which is
+time.Now if nil, and is assigned to the fields of the input of inserts, upsert,
+updeq, and increq before the query. patcheq ignores the fields of its input for
+update time columns. The go field type must be time.Time or int64 (unix
+seconds). If source is sql, the columns are set to CURRENT_TIMESTAMP by the
+database, the go field type must be time.Time, and the fields of the input are
+unchanged. Soft deletes are also stamped from the Clock of the model table.
+Other query kinds do not set auto time columns.
+
 Valid query kinds are:
 
 - getoneeq: gets a single row where the equal field(s) are equal to the input
@@ -86,6 +218,107 @@ Valid query kinds are:
 - getgroupeq: gets all rows where the field(s) are equal to the input
 - updeq: updates all rows where the fields(s) are equal to the input
 - deleq: deletes all rows where the fields(s) are equal to the input
+- upsert: inserts rows, and updates the update field(s) of rows which conflict
+  on the conflict field(s)
+- count: counts all rows
+- counteq: counts all rows where the field(s) are equal to the input
+- existseq: returns whether any row exists where the field(s) are equal to the
+  input
+- getgroupkeyset: gets a page of rows where the optional field(s) are equal to
+  the input, following the last row of the previous page in the order
+- patcheq: updates the set fields of all rows where the field(s) are equal to
+  the input
+- increq: adds the input deltas to the fields of all rows where the field(s)
+  are equal to the input. The incremented fields must be numeric, and may not
+  be fields of the conditions
+- aggregate: gets the aggregate fields of all rows where the optional field(s)
+  are equal to the input, grouped by the other fields
+- raw: gets all rows of the specified sql
+- getmapin: gets all rows where the field(s) are equal to the input, keyed by
+  the field of the in condition
+
+conflict and update are only valid for upsert. conflict is required, and update
+defaults to all query fields not in conflict. If update is an empty list,
+conflicting rows are left unchanged.
+
+getgroupkeyset requires an order whose fields are fields of the query struct
+with a dir of ASC or DESC. The order should end with a unique field such that
+it is total. A Get{Struct}{Name} method returns the first page, and a
+Get{Struct}{Name}After method returns the page following a row.
+
+sort is only valid for getgroup and getgroupeq, and may not be specified with
+order. It lists named orders from which the order of the query is selected at
+runtime. A {Struct}{Name}Sort type is generated with a
+{Struct}{Name}Sort{SortName} constant for each sort, and the generated methods
+take a sort param of that type. An unknown sort uses the first sort.
+
+A field of a query struct used by aggregate may instead have a "model" tag of
+the form aggregate(column_name) where aggregate is one of count, sum, min, max,
+or avg, and column_name may be * for count. The go field type must be int or
+int64 for count, the column type, int64, or float64 for sum, the column type for
+min and max, and float64 for avg. sum and avg are only valid on numeric columns.
+Aggregates other than count are null if all values of the column are null, and
+their go field type may be a pointer. Query structs with aggregate fields may
+only be used by aggregate, which groups rows by the other fields of the struct.
+having is only valid for aggregate, and is a list of conditions applied to the
+groups. having and order may refer to aggregate fields by their tag, e.g.
+count(*).
+
+lock is only valid for getoneeq, getgroup, and getgroupeq, and locks the
+selected rows within a transaction. mode is one of:
+
+- update: FOR UPDATE
+- share: FOR SHARE, or LOCK IN SHARE MODE for the mysql dialect
+
+wait by default waits for rows locked by other transactions, and may be one of:
+
+- nowait: NOWAIT, which errors on locked rows
+- skiplocked: SKIP LOCKED, which skips locked rows
+
+lock is not supported by the sqlite dialect, and the mysql dialect does not
+support wait for a share lock. A streamed query takes the same lock.
+
+getmapin requires exactly one top level in condition on a field of the query
+struct, and returns a map from the values of the field to rows. If the input of
+the in condition is empty, it returns an empty map without a query. many and
+missing are only valid for getmapin. If many is true, each key maps to a list of
+rows in order, and otherwise to the last row with the key. If missing is true,
+the keys of the input without any rows are also returned.
+
+sql, sqlfile, and params are only valid for raw, which requires exactly one of
+sql or sqlfile. sqlfile is the path of a file containing the sql relative to
+the schema file. The sql may contain the following substitutions:
+
+- {{table}}: the table of the model
+- {{columns}}: the columns of the query struct in order
+- {{column_name}}: a column of the model
+
+Named params of the form :name are bound to the input of the generated method,
+and are rewritten into the placeholders of the dialect. The input of a param is
+named with a v prefix, e.g. vName for :name, such that it does not collide with
+go keywords or the generated code. A param takes the go field type of the column
+of the same name, unless it is listed in params with a col. Columns referenced
+by substitutions and params must exist in the model. The sql must select the
+columns of the query struct in order, and it is otherwise not validated. Line
+comments are removed and whitespace outside of quotes is collapsed.
+
+stream is only valid for getgroup, getgroupeq, and getgroupkeyset. If true, a
+Stream{Struct}{Name} method is additionally generated which calls a function
+on every row matching the query in order without a limit. Rows are scanned one
+at a time. Iteration stops at the first error returned by the function, which
+is then returned by the method.
+
+returning is only valid for updeq, increq, patcheq, and deleq. It names a
+query struct of the same model into which the affected rows are scanned and
+returned. A query struct which is only used by returning does not require its
+own queries.
+
+affected is only valid for updeq, increq, patcheq, and deleq without
+returning. If count, the generated method returns the number of affected rows.
+If notfound, the generated method returns sqldb.ErrNotFound when no rows are
+affected. MySQL by default counts only rows whose values are changed by an
+update, so the mysql dialect only supports notfound for deleq, and count for
+updates excludes unchanged rows unless the client sets clientFoundRows=true.
 
 field by default has a condition of eq, but it may be explicitly specified.
 cond may be one of:
@@ -98,6 +331,55 @@ cond may be one of:
 - geq: column value greater than or equal to the input
 - in: column value equals one of the values of the input set
 - like: column value like the input
+- isnull: column value is null, and takes no input
+- notnull: column value is not null, and takes no input
+- between: column value is between the start and end inputs inclusive
+- prefix: column value starts with the input
+- suffix: column value ends with the input
+- contains: column value contains the input
+- iprefix, isuffix, icontains: case insensitive prefix, suffix, and contains
+- arrcontains: array column value contains all elements of the input array
+- arroverlap: array column value has an element in common with the input array
+- eqany: column value equals any element of the input array
+- jsoncontains: json column value contains the input json value
+- jsonhaskey: json column value has the input string as a top level key
+
+The input of prefix, suffix, contains, and their case insensitive variants is
+escaped with sqldb.EscapeLike such that it is matched literally, and must be a
+string. The input of like is used as the pattern verbatim.
+
+like, prefix, suffix, and contains use the LIKE of the database, whose case
+sensitivity differs between dialects:
+
+- postgres: case sensitive
+- sqlite: case insensitive for ascii characters only
+- mysql: depends on the collation of the column, and is case insensitive for
+  the default collations
+
+The case insensitive variants are case insensitive on all dialects. They use
+ILIKE on postgres, and compare LOWER of the column and the pattern otherwise.
+
+arrcontains and arroverlap must be on a field whose go type is a slice, and
+take an input of the same type. eqany must be on a field whose go type is not a
+slice, and takes a slice of the go field type. jsoncontains and jsonhaskey must
+be on a field whose sql type is JSON or JSONB, and jsoncontains takes an input
+of the go field type. These conditions are only supported by the postgres
+dialect.
+
+Conditions are joined with AND. A condition may instead be a group of
+conditions specified by "any" or "all", which are joined with OR or AND
+respectively. Groups may be nested.
+
+The generated SQL targets the dialect specified by --dialect. Valid dialects
+are:
+
+- postgres (default): PostgreSQL
+- sqlite: SQLite
+- mysql: MySQL and MariaDB
+
+upsert with update columns under the mysql dialect references the inserted row
+with VALUES(), which is supported by MariaDB and by MySQL, where it is
+deprecated since MySQL 8.0.20.
 `,
 		Run:               c.execModel,
 		DisableAutoGenTag: true,
@@ -108,13 +390,21 @@ cond may be one of:
 	modelCmd.PersistentFlags().StringVar(&c.modelFlags.opts.Ignore, "ignore", "", "regex for filenames of files that should be ignored")
 	modelCmd.PersistentFlags().StringVar(&c.modelFlags.opts.ModelDirective, "model-directive", "forge:model", "comment directive of types that are models")
 	modelCmd.PersistentFlags().StringVar(&c.modelFlags.opts.QueryDirective, "query-directive", "forge:model:query", "comment directive of types that are model queries")
+	modelCmd.PersistentFlags().StringVar(&c.modelFlags.opts.JoinDirective, "join-directive", "forge:model:join", "comment directive of types that are join queries")
 	modelCmd.PersistentFlags().StringVar(&c.modelFlags.opts.ModelTag, "model-tag", "model", "go struct tag for defining model fields")
-	modelCmd.PersistentFlags().StringVar(&c.modelFlags.opts.PlaceholderPrefix, "placeholder-prefix", "$", "query numeric placeholder prefix")
+	modelCmd.PersistentFlags().StringVar(&c.modelFlags.opts.PlaceholderPrefix, "placeholder-prefix", "$", "query numeric placeholder prefix of the postgres dialect")
+	modelCmd.PersistentFlags().StringVar(&c.modelFlags.dialect, "dialect", "postgres", "sql dialect of generated queries")
 	return modelCmd
 }
 
 func (c *Cmd) execModel(cmd *cobra.Command, args []string) {
 	log := c.log.Logger.Sublogger("", klog.AString("cmd", "model"))
+	dialect, err := model.ParseDialect(c.modelFlags.dialect, c.modelFlags.opts.PlaceholderPrefix)
+	if err != nil {
+		c.logFatal(err)
+		return
+	}
+	c.modelFlags.opts.Dialect = dialect
 	if err := model.Execute(
 		log,
 		c.version,
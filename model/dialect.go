@@ -0,0 +1,456 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+
+	"xorkevin.dev/kerrors"
+)
+
+type (
+	// Dialect renders the SQL fragments of generated queries that differ
+	// between databases.
+	//
+	// SQL fragments are embedded in interpreted go string literals of the
+	// generated code, and must be escaped accordingly. Arguments may contain go
+	// expressions which are concatenated at runtime such as "+t.TableName+".
+	// These must be included verbatim in the returned fragments.
+	Dialect interface {
+		// Name returns the name of the dialect
+		Name() string
+		// Positional returns true if query parameters are bound in the order in
+		// which their placeholders appear, rather than by number
+		Positional() bool
+		// Placeholder returns a fmt format string of a query placeholder which
+		// takes the 1-indexed parameter number as an operand, or no operands if
+		// the dialect is positional
+		Placeholder() string
+		// Ident returns a quoted identifier
+		Ident(name string) string
+		// InListElem returns a fmt format string of an element of an IN list
+		// following the same conventions as Placeholder
+		InListElem() string
+		// InList returns an IN predicate of a column over the list elements
+		InList(col string, elems string) string
+		// ILike returns a case insensitive LIKE predicate of a column over a
+		// pattern. Note that the case sensitivity of a plain LIKE differs
+		// between databases.
+		ILike(col string, pattern string) string
+		// ContainerCond returns a predicate of an array or json operator between
+		// a column and a placeholder, and false if unsupported
+		ContainerCond(op ContainerOp, col string, param string) (string, bool)
+		// Lock returns the clause appended to a SELECT to lock the selected rows,
+		// and false if unsupported
+		Lock(mode LockMode, wait LockWait) (string, bool)
+		// Limit returns a pagination clause. offset is empty if the query does
+		// not take an offset.
+		Limit(limit, offset string) string
+		// UpdateSet returns the assignments of an UPDATE SET clause
+		UpdateSet(cols []string, vals []string) string
+		// OnConflictDoNothing returns the clause appended to an INSERT to ignore
+		// conflicting rows given the inserted columns
+		OnConflictDoNothing(cols []string) string
+		// Upsert returns the clause appended to an INSERT to update the update
+		// columns of rows which conflict on the conflict columns. update may be
+		// empty, in which case conflicting rows are ignored.
+		Upsert(conflict []string, update []string) string
+		// Returning returns the clause appended to a statement to return the
+		// columns of affected rows, and false if unsupported
+		Returning(cols []string) (string, bool)
+		// AffectedMatched returns true if the affected rows of an UPDATE include
+		// matched rows whose values are unchanged
+		AffectedMatched() bool
+		// Setup returns the statements that create a table and its indicies
+		Setup(table string, defs []string, indicies []SQLIndex) []string
+	}
+
+	// ContainerOp is an array or json operator of a condition
+	ContainerOp int
+
+	// LockMode is the strength of a row lock
+	LockMode int
+
+	// LockWait is the behavior of a row lock on rows locked by other
+	// transactions
+	LockWait int
+
+	// SQLIndex is a table index
+	SQLIndex struct {
+		Name    string
+		Columns string
+	}
+
+	// DialectPostgres is the PostgreSQL dialect
+	DialectPostgres struct {
+		PlaceholderPrefix string
+	}
+
+	// DialectSQLite is the SQLite dialect
+	DialectSQLite struct{}
+
+	// DialectMySQL is the MySQL and MariaDB dialect
+	DialectMySQL struct{}
+)
+
+const (
+	ContainerOpUnknown ContainerOp = iota
+	// ContainerOpArrContains is an array column containing all elements of an
+	// array
+	ContainerOpArrContains
+	// ContainerOpArrOverlap is an array column having an element in common with
+	// an array
+	ContainerOpArrOverlap
+	// ContainerOpEqAny is a column equal to any element of an array
+	ContainerOpEqAny
+	// ContainerOpJSONContains is a json column containing a json value
+	ContainerOpJSONContains
+	// ContainerOpJSONHasKey is a json column having a top level key
+	ContainerOpJSONHasKey
+)
+
+const (
+	LockModeUnknown LockMode = iota
+	// LockModeUpdate is an exclusive lock of rows to be updated
+	LockModeUpdate
+	// LockModeShare is a shared lock of rows which prevents their update
+	LockModeShare
+)
+
+const (
+	// LockWaitDefault waits for rows locked by other transactions
+	LockWaitDefault LockWait = iota
+	// LockWaitNoWait errors on rows locked by other transactions
+	LockWaitNoWait
+	// LockWaitSkipLocked skips rows locked by other transactions
+	LockWaitSkipLocked
+)
+
+// ParseDialect returns a builtin dialect by name
+func ParseDialect(name string, placeholderPrefix string) (Dialect, error) {
+	switch name {
+	case "", "postgres":
+		return DialectPostgres{
+			PlaceholderPrefix: placeholderPrefix,
+		}, nil
+	case "sqlite":
+		return DialectSQLite{}, nil
+	case "mysql":
+		return DialectMySQL{}, nil
+	default:
+		return nil, kerrors.WithKind(nil, ErrInvalidDialect, fmt.Sprintf("Unknown dialect %s", name))
+	}
+}
+
+func (d DialectPostgres) Name() string {
+	return "postgres"
+}
+
+func (d DialectPostgres) Positional() bool {
+	return false
+}
+
+func (d DialectPostgres) Placeholder() string {
+	return d.PlaceholderPrefix + "%d"
+}
+
+func (d DialectPostgres) Ident(name string) string {
+	return name
+}
+
+func (d DialectPostgres) InListElem() string {
+	return "(" + d.PlaceholderPrefix + "%d)"
+}
+
+func (d DialectPostgres) InList(col string, elems string) string {
+	return fmt.Sprintf("%s IN (VALUES %s)", col, elems)
+}
+
+func (d DialectPostgres) ILike(col string, pattern string) string {
+	return fmt.Sprintf("%s ILIKE %s", col, pattern)
+}
+
+func (d DialectPostgres) ContainerCond(op ContainerOp, col string, param string) (string, bool) {
+	switch op {
+	case ContainerOpArrContains, ContainerOpJSONContains:
+		return fmt.Sprintf("%s @> %s", col, param), true
+	case ContainerOpArrOverlap:
+		return fmt.Sprintf("%s && %s", col, param), true
+	case ContainerOpEqAny:
+		return fmt.Sprintf("%s = ANY(%s)", col, param), true
+	case ContainerOpJSONHasKey:
+		return fmt.Sprintf("%s ? %s", col, param), true
+	default:
+		return "", false
+	}
+}
+
+func (d DialectPostgres) Lock(mode LockMode, wait LockWait) (string, bool) {
+	return lockFor(mode, wait)
+}
+
+func (d DialectPostgres) Limit(limit, offset string) string {
+	return limitOffset(limit, offset)
+}
+
+func (d DialectPostgres) UpdateSet(cols []string, vals []string) string {
+	if len(cols) == 1 {
+		return fmt.Sprintf("%s = %s", cols[0], vals[0])
+	}
+	return fmt.Sprintf("(%s) = (%s)", strings.Join(cols, ", "), strings.Join(vals, ", "))
+}
+
+func (d DialectPostgres) OnConflictDoNothing(cols []string) string {
+	return " ON CONFLICT DO NOTHING"
+}
+
+func (d DialectPostgres) Upsert(conflict []string, update []string) string {
+	return upsertExcluded(conflict, update)
+}
+
+func (d DialectPostgres) Returning(cols []string) (string, bool) {
+	return " RETURNING " + strings.Join(cols, ", "), true
+}
+
+func (d DialectPostgres) AffectedMatched() bool {
+	return true
+}
+
+func (d DialectPostgres) Setup(table string, defs []string, indicies []SQLIndex) []string {
+	return setupCreateIndex(d, table, defs, indicies)
+}
+
+func (d DialectSQLite) Name() string {
+	return "sqlite"
+}
+
+func (d DialectSQLite) Positional() bool {
+	return false
+}
+
+func (d DialectSQLite) Placeholder() string {
+	return "?%d"
+}
+
+func (d DialectSQLite) Ident(name string) string {
+	return name
+}
+
+func (d DialectSQLite) InListElem() string {
+	return "?%d"
+}
+
+func (d DialectSQLite) InList(col string, elems string) string {
+	return fmt.Sprintf("%s IN (%s)", col, elems)
+}
+
+func (d DialectSQLite) ILike(col string, pattern string) string {
+	return lowerLike(col, pattern)
+}
+
+func (d DialectSQLite) ContainerCond(op ContainerOp, col string, param string) (string, bool) {
+	return "", false
+}
+
+func (d DialectSQLite) Lock(mode LockMode, wait LockWait) (string, bool) {
+	// sqlite locks the entire database rather than rows
+	return "", false
+}
+
+func (d DialectSQLite) Limit(limit, offset string) string {
+	return limitOffset(limit, offset)
+}
+
+func (d DialectSQLite) UpdateSet(cols []string, vals []string) string {
+	return updateSetAssign(cols, vals)
+}
+
+func (d DialectSQLite) OnConflictDoNothing(cols []string) string {
+	return " ON CONFLICT DO NOTHING"
+}
+
+func (d DialectSQLite) Upsert(conflict []string, update []string) string {
+	return upsertExcluded(conflict, update)
+}
+
+func (d DialectSQLite) Returning(cols []string) (string, bool) {
+	return " RETURNING " + strings.Join(cols, ", "), true
+}
+
+func (d DialectSQLite) AffectedMatched() bool {
+	return true
+}
+
+func (d DialectSQLite) Setup(table string, defs []string, indicies []SQLIndex) []string {
+	return setupCreateIndex(d, table, defs, indicies)
+}
+
+func (d DialectMySQL) Name() string {
+	return "mysql"
+}
+
+func (d DialectMySQL) Positional() bool {
+	return true
+}
+
+func (d DialectMySQL) Placeholder() string {
+	return "?"
+}
+
+func (d DialectMySQL) Ident(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+func (d DialectMySQL) InListElem() string {
+	return "?"
+}
+
+func (d DialectMySQL) InList(col string, elems string) string {
+	return fmt.Sprintf("%s IN (%s)", col, elems)
+}
+
+func (d DialectMySQL) ILike(col string, pattern string) string {
+	return lowerLike(col, pattern)
+}
+
+func (d DialectMySQL) ContainerCond(op ContainerOp, col string, param string) (string, bool) {
+	// mysql has no array type, and its json functions do not map to these
+	// operators
+	return "", false
+}
+
+func (d DialectMySQL) Lock(mode LockMode, wait LockWait) (string, bool) {
+	// mariadb does not support FOR SHARE, and LOCK IN SHARE MODE does not take
+	// NOWAIT or SKIP LOCKED in mysql
+	if mode == LockModeShare {
+		if wait != LockWaitDefault {
+			return "", false
+		}
+		return " LOCK IN SHARE MODE", true
+	}
+	return lockFor(mode, wait)
+}
+
+func (d DialectMySQL) Limit(limit, offset string) string {
+	return limitOffset(limit, offset)
+}
+
+func (d DialectMySQL) UpdateSet(cols []string, vals []string) string {
+	return updateSetAssign(cols, vals)
+}
+
+func (d DialectMySQL) OnConflictDoNothing(cols []string) string {
+	// assigning a column to itself leaves a conflicting row unchanged without
+	// also suppressing unrelated errors as INSERT IGNORE would
+	return fmt.Sprintf(" ON DUPLICATE KEY UPDATE %s = %s", cols[0], cols[0])
+}
+
+func (d DialectMySQL) Upsert(conflict []string, update []string) string {
+	// mysql does not take a conflict target, and instead updates rows which
+	// conflict on any unique index
+	if len(update) == 0 {
+		return d.OnConflictDoNothing(conflict)
+	}
+	// the inserted row is referenced by VALUES() rather than a row alias,
+	// since mariadb does not support row aliases, and mysql only deprecates
+	// VALUES() since 8.0.20
+	assignments := make([]string, 0, len(update))
+	for _, i := range update {
+		assignments = append(assignments, fmt.Sprintf("%s = VALUES(%s)", i, i))
+	}
+	return " ON DUPLICATE KEY UPDATE " + strings.Join(assignments, ", ")
+}
+
+func (d DialectMySQL) Returning(cols []string) (string, bool) {
+	// mysql does not support RETURNING, and mariadb only supports it on INSERT
+	// and DELETE
+	return "", false
+}
+
+func (d DialectMySQL) AffectedMatched() bool {
+	// mysql by default only counts changed rows unless the client sets
+	// CLIENT_FOUND_ROWS
+	return false
+}
+
+func (d DialectMySQL) Setup(table string, defs []string, indicies []SQLIndex) []string {
+	// mysql does not support CREATE INDEX IF NOT EXISTS, so indicies are
+	// instead declared with the table
+	k := make([]string, 0, len(defs)+len(indicies))
+	k = append(k, defs...)
+	for _, i := range indicies {
+		k = append(k, fmt.Sprintf("INDEX %s (%s)", d.Ident(i.Name), i.Columns))
+	}
+	return []string{
+		fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)", d.Ident(table), strings.Join(k, ", ")),
+	}
+}
+
+func lowerLike(col string, pattern string) string {
+	// LIKE is only case insensitive for ascii characters in sqlite and depends
+	// on the collation of the column in mysql
+	return fmt.Sprintf("LOWER(%s) LIKE LOWER(%s)", col, pattern)
+}
+
+func lockFor(mode LockMode, wait LockWait) (string, bool) {
+	var clause string
+	switch mode {
+	case LockModeUpdate:
+		clause = " FOR UPDATE"
+	case LockModeShare:
+		clause = " FOR SHARE"
+	default:
+		return "", false
+	}
+	switch wait {
+	case LockWaitDefault:
+	case LockWaitNoWait:
+		clause += " NOWAIT"
+	case LockWaitSkipLocked:
+		clause += " SKIP LOCKED"
+	default:
+		return "", false
+	}
+	return clause, true
+}
+
+func limitOffset(limit, offset string) string {
+	if offset == "" {
+		return fmt.Sprintf("LIMIT %s", limit)
+	}
+	return fmt.Sprintf("LIMIT %s OFFSET %s", limit, offset)
+}
+
+func upsertExcluded(conflict []string, update []string) string {
+	if len(update) == 0 {
+		return fmt.Sprintf(" ON CONFLICT (%s) DO NOTHING", strings.Join(conflict, ", "))
+	}
+	assignments := make([]string, 0, len(update))
+	for _, i := range update {
+		assignments = append(assignments, fmt.Sprintf("%s = EXCLUDED.%s", i, i))
+	}
+	return fmt.Sprintf(" ON CONFLICT (%s) DO UPDATE SET %s", strings.Join(conflict, ", "), strings.Join(assignments, ", "))
+}
+
+func updateSetAssign(cols []string, vals []string) string {
+	assignments := make([]string, 0, len(cols))
+	for n, i := range cols {
+		assignments = append(assignments, fmt.Sprintf("%s = %s", i, vals[n]))
+	}
+	return strings.Join(assignments, ", ")
+}
+
+func setupCreateIndex(d Dialect, table string, defs []string, indicies []SQLIndex) []string {
+	stmts := make([]string, 0, 1+len(indicies))
+	stmts = append(stmts, fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)", d.Ident(table), strings.Join(defs, ", ")))
+	for _, i := range indicies {
+		stmts = append(stmts, fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s (%s)", d.Ident(i.Name), d.Ident(table), i.Columns))
+	}
+	return stmts
+}
+
+func placeholder(d Dialect, n int) string {
+	if d.Positional() {
+		return d.Placeholder()
+	}
+	return fmt.Sprintf(d.Placeholder(), n)
+}
@@ -6,9 +6,9 @@ package {{.Package}}
 
 import (
 	"context"
-	"errors"
-	"fmt"
-	"strings"
+	{{- range .Imports }}
+	"{{.}}"
+	{{- end }}
 
 	"xorkevin.dev/forge/model/sqldb"
 )
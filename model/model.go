@@ -12,13 +12,16 @@ import (
 	"go/token"
 	"io/fs"
 	"os"
+	"path"
 	"reflect"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"text/template"
 
 	"xorkevin.dev/forge/gopackages"
+	"xorkevin.dev/forge/model/sqldb"
 	"xorkevin.dev/kerrors"
 	"xorkevin.dev/kfs"
 	"xorkevin.dev/klog"
@@ -29,6 +32,20 @@ const (
 	generatedFileFlag = os.O_WRONLY | os.O_TRUNC | os.O_CREATE
 )
 
+const (
+	queryAffectedCount    = "count"
+	queryAffectedNotFound = "notfound"
+)
+
+const (
+	// sqlTableName is the go string expression of the table name within a
+	// generated sql string
+	sqlTableName = `"+t.TableName+"`
+	// sqlCurrentTime is the sql expression of the current time of auto time
+	// columns set by the database
+	sqlCurrentTime = "CURRENT_TIMESTAMP"
+)
+
 var (
 	// ErrEnv is returned when validation is run outside of go generate
 	ErrEnv errEnv
@@ -38,13 +55,17 @@ var (
 	ErrInvalidFile errInvalidFile
 	// ErrInvalidModel is returned when checking an invalid model
 	ErrInvalidModel errInvalidModel
+	// ErrInvalidDialect is returned when requesting an unknown sql dialect or a
+	// feature which the dialect does not support
+	ErrInvalidDialect errInvalidDialect
 )
 
 type (
-	errEnv           struct{}
-	errInvalidFile   struct{}
-	errInvalidModel  struct{}
-	errInvalidSchema struct{}
+	errEnv            struct{}
+	errInvalidFile    struct{}
+	errInvalidModel   struct{}
+	errInvalidSchema  struct{}
+	errInvalidDialect struct{}
 )
 
 func (e errEnv) Error() string {
@@ -63,6 +84,10 @@ func (e errInvalidSchema) Error() string {
 	return "Invalid schema"
 }
 
+func (e errInvalidDialect) Error() string {
+	return "Invalid dialect"
+}
+
 type (
 	dirObjPair struct {
 		Dir gopackages.DirectiveInstance
@@ -90,15 +115,28 @@ type (
 		Columns []string `json:"columns"`
 	}
 
+	modelAutoTimeOpts struct {
+		Insert []string `json:"insert"`
+		Update []string `json:"update"`
+		Source string   `json:"source"`
+	}
+
 	modelOpts struct {
 		Setup       string                `json:"setup"`
 		Constraints []modelConstraintOpts `json:"constraints"`
 		Indicies    []modelIndexOpts      `json:"indicies"`
+		Returning   []string              `json:"returning"`
+		Filter      bool                  `json:"filter"`
+		SoftDelete  string                `json:"softDelete"`
+		Version     string                `json:"version"`
+		AutoTime    modelAutoTimeOpts     `json:"autoTime"`
 	}
 
 	queryCondOpt struct {
-		Col  string `json:"col"`
-		Cond string `json:"cond"`
+		Col  string         `json:"col"`
+		Cond string         `json:"cond"`
+		Any  []queryCondOpt `json:"any"`
+		All  []queryCondOpt `json:"all"`
 	}
 
 	queryOrderOpt struct {
@@ -106,11 +144,39 @@ type (
 		Dir string `json:"dir"`
 	}
 
+	querySortOpt struct {
+		Name  string          `json:"name"`
+		Order []queryOrderOpt `json:"order"`
+	}
+
 	queryOpts struct {
 		Kind       string          `json:"kind"`
 		Name       string          `json:"name"`
 		Conditions []queryCondOpt  `json:"conditions"`
 		Order      []queryOrderOpt `json:"order"`
+		Sort       []querySortOpt  `json:"sort"`
+		Conflict   []string        `json:"conflict"`
+		Update     []string        `json:"update"`
+		Stream     bool            `json:"stream"`
+		Returning  string          `json:"returning"`
+		Affected   string          `json:"affected"`
+		Having     []queryCondOpt  `json:"having"`
+		SQL        string          `json:"sql"`
+		SQLFile    string          `json:"sqlfile"`
+		Params     []queryParamOpt `json:"params"`
+		Many       bool            `json:"many"`
+		Missing    bool            `json:"missing"`
+		Lock       *queryLockOpt   `json:"lock"`
+	}
+
+	queryLockOpt struct {
+		Mode string `json:"mode"`
+		Wait string `json:"wait"`
+	}
+
+	queryParamOpt struct {
+		Name string `json:"name"`
+		Col  string `json:"col"`
 	}
 
 	modelConfig struct {
@@ -118,8 +184,26 @@ type (
 		Queries map[string][]queryOpts `json:"queries"`
 	}
 
+	joinOnOpts struct {
+		Col string `json:"col"`
+		Ref string `json:"ref"`
+	}
+
+	joinOpts struct {
+		Model string       `json:"model"`
+		Kind  string       `json:"kind"`
+		On    []joinOnOpts `json:"on"`
+	}
+
+	joinConfig struct {
+		From    string      `json:"from"`
+		Join    []joinOpts  `json:"join"`
+		Queries []queryOpts `json:"queries"`
+	}
+
 	modelSchema struct {
 		Models map[string]modelConfig `json:"models"`
+		Joins  map[string]joinConfig  `json:"joins"`
 	}
 
 	modelDef struct {
@@ -128,8 +212,19 @@ type (
 		Fields      []modelField
 		Constraints []modelConstraint
 		Indicies    []modelIndexDef
-		opts        modelOpts
-		fieldMap    map[string]modelField
+		Returning   []modelField
+		// SoftDel is the column stamped when a row is soft deleted
+		SoftDel *modelField
+		// Version is the column incremented by each update of a row
+		Version *modelField
+		// InsertTime and UpdateTime are the columns set to the current time by
+		// inserts and updates respectively, which is from the database if
+		// TimeSQL is true, and otherwise from the clock of the model table
+		InsertTime []modelField
+		UpdateTime []modelField
+		TimeSQL    bool
+		opts       modelOpts
+		fieldMap   map[string]modelField
 	}
 
 	modelField struct {
@@ -138,6 +233,13 @@ type (
 		DBName string
 		DBType string
 		Num    int
+		// Alias is the alias of the table of the field in a join, and Model is
+		// the prefix of its model
+		Alias string
+		Model string
+		// Agg is the aggregate function over the column of an aggregate query
+		// field
+		Agg string
 	}
 
 	modelConstraint struct {
@@ -155,29 +257,152 @@ type (
 		Dir   string
 	}
 
+	modelFilterTemplateData struct {
+		Prefix string
+		SQL    modelFilterSQLStrings
+	}
+
+	modelFilterSQLStrings struct {
+		Positional bool
+		// TableExpr is the go expression of the table name
+		TableExpr      string
+		Placeholder    string
+		ArrPlaceholder string
+		Limit          string
+		LimitOffset    string
+		LikeEscape     string
+		// SoftDel is the condition excluding soft deleted rows
+		SoftDel string
+		Ops     []modelFilterOp
+		Fields  []modelFilterField
+		Queries []modelFilterQuery
+	}
+
+	modelFilterOp struct {
+		Name string
+		Op   string
+	}
+
+	modelFilterField struct {
+		Ident  string
+		Param  string
+		GoType string
+		DBName string
+		InList string
+		Like   bool
+	}
+
+	modelFilterQuery struct {
+		Ident     string
+		DBNames   string
+		IdentRefs string
+	}
+
 	queryGroupDef struct {
 		Ident   string
 		Fields  []queryField
 		Queries []queryDef
+		// join is the joined tables of a join query struct
+		join []joinTableDef
+		// softDel is the soft delete column of the queried model, or of the
+		// first table of a join
+		softDel *modelField
+		// version is the field of the version column of a versioned model
+		version *queryField
+		// insertTime and updateTime are the columns set to the current time by
+		// inserts and updates, which is from the database if timeSQL is true
+		insertTime []modelField
+		updateTime []modelField
+		timeSQL    bool
 	}
 
 	queryField struct {
-		Ident  string
-		GoType string
-		DBName string
-		Num    int
+		Ident    string
+		GoType   string
+		DBName   string
+		Num      int
+		Optional bool
+		Alias    string
+		// Agg is the aggregate function over the column of the field, and Expr
+		// is the aggregate expression of its tag
+		Agg  string
+		Expr string
+	}
+
+	joinDef struct {
+		Prefix string
+		Tables []joinTableDef
+		Group  queryGroupDef
+	}
+
+	joinTableDef struct {
+		Prefix string
+		Alias  string
+		// Kind is the join clause of the table, and is empty for the first
+		// table
+		Kind string
+		On   []joinOnField
+		// SoftDel is the soft delete column of the model of the table
+		SoftDel *modelField
+	}
+
+	joinOnField struct {
+		Col modelField
+		Ref modelField
+	}
+
+	joinTemplateData struct {
+		Prefix string
+		Fields []string
 	}
 
 	queryDef struct {
-		Kind  queryKind
-		Name  string
-		Conds []queryCondField
-		Order []queryOrderField
+		Kind     queryKind
+		Name     string
+		Conds    []queryCondField
+		Order    []queryOrderField
+		Sort     []querySortDef
+		Keyset   []queryKeysetField
+		Conflict []queryField
+		Update   []queryField
+		Stream   bool
+		// Returning are the fields of the query struct ReturningIdent into which
+		// the rows affected by the query are scanned
+		Returning      []queryField
+		ReturningIdent string
+		Affected       string
+		Having         []queryCondField
+		Raw            []queryRawToken
+		RawParams      []queryRawParam
+		// MapKey is the field of the in condition of getmapin by which results
+		// are keyed
+		MapKey  queryField
+		Many    bool
+		Missing bool
+		// LockMode is unknown if the query does not lock rows
+		LockMode LockMode
+		LockWait LockWait
+	}
+
+	queryRawToken struct {
+		Kind rawTokenKind
+		// Text is the sql text of a text token, or the name of a param token
+		Text  string
+		Field modelField
+	}
+
+	queryRawParam struct {
+		Name   string
+		GoType string
 	}
 
 	queryCondField struct {
 		Kind  condType
 		Field modelField
+		// Group is the boolean operator joining Conds if the condition is a
+		// group of conditions
+		Group condGroup
+		Conds []queryCondField
 	}
 
 	queryOrderField struct {
@@ -185,10 +410,21 @@ type (
 		Dir   string
 	}
 
+	querySortDef struct {
+		Name  string
+		Order []queryOrderField
+	}
+
+	queryKeysetField struct {
+		Field queryField
+		Desc  bool
+	}
+
 	mainTemplateData struct {
 		Generator string
 		Version   string
 		Package   string
+		Imports   []string
 	}
 
 	modelTemplateData struct {
@@ -198,64 +434,215 @@ type (
 	}
 
 	modelSQLStrings struct {
-		Setup            string
-		DBNames          string
-		Placeholders     string
-		PlaceholderTpl   string
-		PlaceholderCount string
-		Idents           string
-		Indicies         []modelIndex
-		ColNum           string
-	}
-
-	modelIndex struct {
-		Name    string
-		Columns string
+		Positional          bool
+		Setup               []string
+		Table               string
+		DBNames             string
+		Placeholders        string
+		PlaceholderTpl      string
+		PlaceholderCount    string
+		Idents              string
+		ColNum              string
+		OnConflictDoNothing string
+		Returning           string
+		ReturningIdentRefs  string
+		// Clock is true if the model table has a clock
+		Clock      bool
+		InsertTime []queryTimeAssign
 	}
 
 	queryTemplateData struct {
-		PlaceholderPrefix string
-		Prefix            string
-		ModelIdent        string
-		Name              string
-		SQL               querySQLStrings
-		SQLCond           queryCondSQLStrings
-		SQLOrder          queryOrderSQLStrings
+		Prefix     string
+		ModelIdent string
+		Name       string
+		Affected   string
+		SQL        querySQLStrings
+		SQLCond    queryCondSQLStrings
+		SQLOrder   queryOrderSQLStrings
+		SQLUpsert  queryUpsertSQLStrings
+		SQLKeyset  queryKeysetSQLStrings
+		SQLReturn  queryReturningSQLStrings
+		SQLPatch   queryPatchSQLStrings
+		SQLRaw     queryRawSQLStrings
+		SQLMap     queryMapSQLStrings
+		SQLLock    string
+		SQLDel     queryDelSQLStrings
+		SQLUpdate  queryUpdateSQLStrings
 	}
 
 	querySQLStrings struct {
-		DBNames      string
-		NumDBNames   int
-		Idents       string
-		IdentRefs    string
-		Placeholders string
-		ColNum       string
+		Positional       bool
+		Table            string
+		DBNames          string
+		NumDBNames       int
+		Idents           string
+		IdentRefs        string
+		Placeholders     string
+		PlaceholderTpl   string
+		PlaceholderCount string
+		UpdateSet        string
+		ColNum           string
+		GroupBy          string
 	}
 
 	queryCondSQLStrings struct {
 		IdentParams     string
 		DBCond          string
+		HavingCond      string
 		IdentArgs       string
+		ArgGroups       []queryArgGroup
 		ArrIdentArgs    []string
 		ArrIdentArgsLen string
+		ArrPlaceholder  string
 		ParamCount      int
+		Positional      bool
+		// condParams are the param names of the flattened conditions
+		condParams []string
+	}
+
+	queryArgGroup struct {
+		Args string
+		Arr  string
 	}
 
 	queryOrderSQLStrings struct {
 		DBOrder string
+		Limit   string
+		// SortIdent is the type of the sort param if the order is selected at
+		// runtime from Sorts
+		SortIdent string
+		Sorts     []querySortSQLStrings
+		// SortDeclared is true if the sort type is declared by another variant
+		// of the query
+		SortDeclared bool
+	}
+
+	querySortSQLStrings struct {
+		Ident   string
+		DBOrder string
+	}
+
+	queryUpsertSQLStrings struct {
+		DBNames          string
+		Placeholders     string
+		PlaceholderTpl   string
+		PlaceholderCount string
+		Idents           string
+		ColNum           string
+		DBConflict       string
+		// Clock is true if the upsert reads the current time from the clock of
+		// the model table, and Now are the fields set to it
+		Clock bool
+		Now   []queryTimeAssign
+	}
+
+	queryKeysetSQLStrings struct {
+		SQLCond queryCondSQLStrings
+	}
+
+	queryPatchSQLStrings struct {
+		Fields      []queryPatchField
+		NumFields   int
+		HasOptional bool
+		NoopSet     string
+		// Clock is true if the update reads the current time from the clock of
+		// the model table, and Times are the update time columns
+		Clock bool
+		Times []queryPatchTime
+	}
+
+	queryPatchField struct {
+		Ident    string
+		Optional bool
+		// SetFmt is a fmt format string of the assignment which takes the
+		// placeholder number as an operand unless the dialect is positional
+		SetFmt string
+	}
+
+	queryPatchTime struct {
+		// SetFmt is the assignment of the column like that of queryPatchField,
+		// and Value is the bound current time, which is empty if the time is
+		// from the database
+		SetFmt string
+		Value  string
+	}
+
+	queryReturningSQLStrings struct {
+		Ident     string
+		Returning string
+		IdentRefs string
+	}
+
+	queryMapSQLStrings struct {
+		KeyIdent string
+		KeyParam string
+		KeyType  string
+		Many     bool
+		Missing  bool
+	}
+
+	queryDelSQLStrings struct {
+		// Method is the method prefix of a delete query, and Stmt is the
+		// statement preceding its condition
+		Method string
+		Stmt   string
+	}
+
+	queryUpdateSQLStrings struct {
+		// Version is the field of the version column, and is empty if the
+		// update is not versioned
+		Version   string
+		UpdateSet string
+		// DBCond is the condition on the version preceding the condition of
+		// the query
+		DBCond string
+		// Clock is true if the update reads the current time from the clock of
+		// the model table, and Now are the fields set to it
+		Clock     bool
+		Now       []queryTimeAssign
+		identArgs []string
+	}
+
+	queryTimeAssign struct {
+		Ident string
+		Value string
+	}
+
+	// queryVariant is a variant of a query generated from the same query
+	// definition
+	queryVariant struct {
+		Def   queryDef
+		Name  string
+		Table string
+		// DelArgs are the args bound before the condition of a delete query
+		DelArgs      []string
+		Del          queryDelSQLStrings
+		SortDeclared bool
+	}
+
+	queryRawSQLStrings struct {
+		SQL         string
+		IdentParams string
+		IdentArgs   string
 	}
 )
 
 type (
 	Opts struct {
-		Output            string
-		Schema            string
-		Include           string
-		Ignore            string
-		ModelDirective    string
-		QueryDirective    string
+		Output         string
+		Schema         string
+		Include        string
+		Ignore         string
+		ModelDirective string
+		QueryDirective string
+		// JoinDirective is the comment directive of join query structs. Joins
+		// are not generated if empty.
+		JoinDirective     string
 		ModelTag          string
 		PlaceholderPrefix string
+		// Dialect is the sql dialect of generated queries. If nil, it defaults
+		// to [DialectPostgres] with PlaceholderPrefix.
+		Dialect Dialect
 	}
 
 	ExecEnv struct {
@@ -287,6 +674,13 @@ func Execute(log klog.Logger, version string, opts Opts) error {
 func Generate(ctx context.Context, log klog.Logger, outputfs fs.FS, inputfs fs.FS, version string, opts Opts, env ExecEnv) (retErr error) {
 	l := klog.NewLevelLogger(log)
 
+	sqlDialect := opts.Dialect
+	if sqlDialect == nil {
+		sqlDialect = DialectPostgres{
+			PlaceholderPrefix: opts.PlaceholderPrefix,
+		}
+	}
+
 	var schema modelSchema
 	if opts.Schema != "" {
 		if f, err := fs.ReadFile(inputfs, opts.Schema); err != nil {
@@ -297,6 +691,9 @@ func Generate(ctx context.Context, log klog.Logger, outputfs fs.FS, inputfs fs.F
 			if err := json.Unmarshal(f, &schema); err != nil {
 				return kerrors.WithKind(err, ErrInvalidSchema, fmt.Sprintf("Invalid schema file: %s", opts.Schema))
 			}
+			if err := readSchemaSQLFiles(inputfs, path.Dir(opts.Schema), schema); err != nil {
+				return err
+			}
 		}
 	}
 
@@ -324,8 +721,12 @@ func Generate(ctx context.Context, log klog.Logger, outputfs fs.FS, inputfs fs.F
 		return kerrors.WithKind(nil, ErrEnv, "Environment variable GOPACKAGE does not match directory package")
 	}
 
-	directiveObjects := gopackages.FindDirectives(astpkg, []string{opts.ModelDirective, opts.QueryDirective})
-	var modelObjects, queryObjects []dirObjPair
+	sigils := []string{opts.ModelDirective, opts.QueryDirective}
+	if opts.JoinDirective != "" {
+		sigils = append(sigils, opts.JoinDirective)
+	}
+	directiveObjects := gopackages.FindDirectives(astpkg, sigils)
+	var modelObjects, queryObjects, joinObjects []dirObjPair
 	for _, i := range directiveObjects {
 		for _, j := range i.Directives {
 			switch j.Sigil {
@@ -339,6 +740,11 @@ func Generate(ctx context.Context, log klog.Logger, outputfs fs.FS, inputfs fs.F
 					Dir: j,
 					Obj: i,
 				})
+			case opts.JoinDirective:
+				joinObjects = append(joinObjects, dirObjPair{
+					Dir: j,
+					Obj: i,
+				})
 			}
 		}
 	}
@@ -358,6 +764,10 @@ func Generate(ctx context.Context, log klog.Logger, outputfs fs.FS, inputfs fs.F
 	if err != nil {
 		return err
 	}
+	joinDefs, err := parseJoinDefinitions(joinObjects, opts.ModelTag, modelDefMap, fset, schema)
+	if err != nil {
+		return err
+	}
 
 	tplmain, err := template.New("main").Parse(templateMain)
 	if err != nil {
@@ -367,92 +777,169 @@ func Generate(ctx context.Context, log klog.Logger, outputfs fs.FS, inputfs fs.F
 	if err != nil {
 		return kerrors.WithMsg(err, "Failed to parse template templateModel")
 	}
+	tplCondArgs, err := template.New("condargs").Parse(templateCondArgs)
+	if err != nil {
+		return kerrors.WithMsg(err, "Failed to parse template templateCondArgs")
+	}
+	if _, err := tplCondArgs.New("returningrows").Parse(templateReturningRows); err != nil {
+		return kerrors.WithMsg(err, "Failed to parse template templateReturningRows")
+	}
+	if _, err := tplCondArgs.New("sort").Parse(templateSort); err != nil {
+		return kerrors.WithMsg(err, "Failed to parse template templateSort")
+	}
+	if _, err := tplCondArgs.New("affected").Parse(templateAffected); err != nil {
+		return kerrors.WithMsg(err, "Failed to parse template templateAffected")
+	}
 	tplQuery := map[queryKind]*template.Template{}
-	tplQuery[queryKindGetOneEq], err = template.New("getoneeq").Parse(templateGetOneEq)
+	tplQuery[queryKindGetOneEq], err = parseQueryTemplate(tplCondArgs, "getoneeq", templateGetOneEq)
 	if err != nil {
 		return kerrors.WithMsg(err, "Failed to parse template templateGetOneEq")
 	}
-	tplQuery[queryKindGetGroup], err = template.New("getgroup").Parse(templateGetGroup)
+	tplQuery[queryKindGetGroup], err = parseQueryTemplate(tplCondArgs, "getgroup", templateGetGroup)
 	if err != nil {
 		return kerrors.WithMsg(err, "Failed to parse template templateGetGroup")
 	}
-	tplQuery[queryKindGetGroupEq], err = template.New("getgroupeq").Parse(templateGetGroupEq)
+	tplQuery[queryKindGetGroupEq], err = parseQueryTemplate(tplCondArgs, "getgroupeq", templateGetGroupEq)
 	if err != nil {
 		return kerrors.WithMsg(err, "Failed to parse template templateGetGroupEq")
 	}
-	tplQuery[queryKindUpdEq], err = template.New("updeq").Parse(templateUpdEq)
+	tplQuery[queryKindUpdEq], err = parseQueryTemplate(tplCondArgs, "updeq", templateUpdEq)
 	if err != nil {
 		return kerrors.WithMsg(err, "Failed to parse template templateUpdEq")
 	}
-	tplQuery[queryKindDelEq], err = template.New("deleq").Parse(templateDelEq)
+	tplQuery[queryKindDelEq], err = parseQueryTemplate(tplCondArgs, "deleq", templateDelEq)
 	if err != nil {
 		return kerrors.WithMsg(err, "Failed to parse template templateDelEq")
 	}
-
-	file, err := kfs.OpenFile(outputfs, opts.Output, generatedFileFlag, generatedFileMode)
+	tplQuery[queryKindUpsert], err = parseQueryTemplate(tplCondArgs, "upsert", templateUpsert)
 	if err != nil {
-		return kerrors.WithMsg(err, fmt.Sprintf("Failed to write file %s", opts.Output))
+		return kerrors.WithMsg(err, "Failed to parse template templateUpsert")
 	}
-	defer func() {
-		if err := file.Close(); err != nil {
-			retErr = errors.Join(retErr, kerrors.WithMsg(err, fmt.Sprintf("Failed to close open file %s", opts.Output)))
-		}
-	}()
-	fwriter := bufio.NewWriter(file)
-
-	tplData := mainTemplateData{
-		Generator: "go generate forge model",
-		Version:   version,
-		Package:   env.GoPackage,
+	tplQuery[queryKindCount], err = parseQueryTemplate(tplCondArgs, "count", templateCount)
+	if err != nil {
+		return kerrors.WithMsg(err, "Failed to parse template templateCount")
 	}
-	if err := tplmain.Execute(fwriter, tplData); err != nil {
-		return kerrors.WithMsg(err, "Failed to execute main model template")
+	tplQuery[queryKindCountEq], err = parseQueryTemplate(tplCondArgs, "counteq", templateCountEq)
+	if err != nil {
+		return kerrors.WithMsg(err, "Failed to parse template templateCountEq")
+	}
+	tplQuery[queryKindExistsEq], err = parseQueryTemplate(tplCondArgs, "existseq", templateExistsEq)
+	if err != nil {
+		return kerrors.WithMsg(err, "Failed to parse template templateExistsEq")
+	}
+	tplQuery[queryKindGetGroupKeyset], err = parseQueryTemplate(tplCondArgs, "getgroupkeyset", templateGetGroupKeyset)
+	if err != nil {
+		return kerrors.WithMsg(err, "Failed to parse template templateGetGroupKeyset")
+	}
+	// increq only differs from updeq by its update set
+	tplQuery[queryKindIncrEq], err = parseQueryTemplate(tplCondArgs, "increq", templateUpdEq)
+	if err != nil {
+		return kerrors.WithMsg(err, "Failed to parse template templateUpdEq")
+	}
+	tplQuery[queryKindPatchEq], err = parseQueryTemplate(tplCondArgs, "patcheq", templatePatchEq)
+	if err != nil {
+		return kerrors.WithMsg(err, "Failed to parse template templatePatchEq")
+	}
+	tplQuery[queryKindAggregate], err = parseQueryTemplate(tplCondArgs, "aggregate", templateAggregate)
+	if err != nil {
+		return kerrors.WithMsg(err, "Failed to parse template templateAggregate")
+	}
+	tplQuery[queryKindRaw], err = parseQueryTemplate(tplCondArgs, "raw", templateRaw)
+	if err != nil {
+		return kerrors.WithMsg(err, "Failed to parse template templateRaw")
+	}
+	tplQuery[queryKindGetMapIn], err = parseQueryTemplate(tplCondArgs, "getmapin", templateGetMapIn)
+	if err != nil {
+		return kerrors.WithMsg(err, "Failed to parse template templateGetMapIn")
+	}
+	tplStream, err := parseQueryTemplate(tplCondArgs, "stream", templateStream)
+	if err != nil {
+		return kerrors.WithMsg(err, "Failed to parse template templateStream")
+	}
+	tplFilter, err := template.New("filter").Parse(templateFilter)
+	if err != nil {
+		return kerrors.WithMsg(err, "Failed to parse template templateFilter")
+	}
+	tplJoin, err := template.New("join").Parse(templateJoin)
+	if err != nil {
+		return kerrors.WithMsg(err, "Failed to parse template templateJoin")
 	}
 
+	// models are generated before the main template in order to determine
+	// which imports are used
+	var body bytes.Buffer
 	for _, i := range modelDefs {
 		mctx := klog.CtxWithAttrs(ctx, klog.AString("model", i.Ident))
 		l.Debug(mctx, "Detected model", klog.AAny("fields", i.Fields))
 
+		modelSQLStrings, err := i.genModelSQL(sqlDialect)
+		if err != nil {
+			return err
+		}
 		tplData := modelTemplateData{
 			Prefix:     i.Prefix,
 			ModelIdent: i.Ident,
-			SQL:        i.genModelSQL(opts.PlaceholderPrefix),
+			SQL:        modelSQLStrings,
 		}
-		if err := tplmodel.Execute(fwriter, tplData); err != nil {
+		if err := tplmodel.Execute(&body, tplData); err != nil {
 			return kerrors.WithMsg(err, fmt.Sprintf("Failed to execute model template for struct: %s", i.Ident))
 		}
 		for _, j := range queryGroupDefs[i.Prefix] {
 			qctx := klog.CtxWithAttrs(mctx, klog.AString("query", j.Ident))
 			l.Debug(qctx, "Detected query", klog.AAny("fields", j.Fields))
 
-			querySQLStrings := j.genQuerySQL(opts.PlaceholderPrefix)
-			numFields := len(j.Fields)
-			for _, k := range j.Queries {
-				tplData := queryTemplateData{
-					PlaceholderPrefix: opts.PlaceholderPrefix,
-					Prefix:            i.Prefix,
-					ModelIdent:        j.Ident,
-					Name:              k.Name,
-					SQL:               querySQLStrings,
-				}
-				switch k.Kind {
-				case queryKindGetOneEq:
-					tplData.SQLCond = k.genQueryCondSQL(opts.PlaceholderPrefix, 0)
-				case queryKindGetGroup:
-					tplData.SQLOrder = k.genQueryOrderSQL()
-				case queryKindGetGroupEq:
-					tplData.SQLCond = k.genQueryCondSQL(opts.PlaceholderPrefix, 2)
-					tplData.SQLOrder = k.genQueryOrderSQL()
-				case queryKindUpdEq:
-					tplData.SQLCond = k.genQueryCondSQL(opts.PlaceholderPrefix, numFields)
-				case queryKindDelEq:
-					tplData.SQLCond = k.genQueryCondSQL(opts.PlaceholderPrefix, 0)
-				}
-				if err := tplQuery[k.Kind].Execute(fwriter, tplData); err != nil {
-					return kerrors.WithMsg(err, fmt.Sprintf("Failed to execute template for query kind %s on struct %s of model %s", k.Kind, tplData.ModelIdent, tplData.Prefix))
-				}
+			if err := genQueryGroup(&body, sqlDialect, tplQuery, tplStream, i.Prefix, j); err != nil {
+				return err
 			}
 		}
+		if i.opts.Filter {
+			tplData := modelFilterTemplateData{
+				Prefix: i.Prefix,
+				SQL:    i.genModelFilterSQL(sqlDialect, queryGroupDefs[i.Prefix]),
+			}
+			if err := tplFilter.Execute(&body, tplData); err != nil {
+				return kerrors.WithMsg(err, fmt.Sprintf("Failed to execute filter template for struct: %s", i.Ident))
+			}
+		}
+	}
+	for _, i := range joinDefs {
+		jctx := klog.CtxWithAttrs(ctx, klog.AString("join", i.Group.Ident))
+		l.Debug(jctx, "Detected join", klog.AAny("fields", i.Group.Fields))
+
+		tplData := joinTemplateData{
+			Prefix: i.Prefix,
+			Fields: i.genJoinFields(),
+		}
+		if err := tplJoin.Execute(&body, tplData); err != nil {
+			return kerrors.WithMsg(err, fmt.Sprintf("Failed to execute join template for struct: %s", i.Group.Ident))
+		}
+		if err := genQueryGroup(&body, sqlDialect, tplQuery, tplStream, i.Prefix, i.Group); err != nil {
+			return err
+		}
+	}
+
+	file, err := kfs.OpenFile(outputfs, opts.Output, generatedFileFlag, generatedFileMode)
+	if err != nil {
+		return kerrors.WithMsg(err, fmt.Sprintf("Failed to write file %s", opts.Output))
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			retErr = errors.Join(retErr, kerrors.WithMsg(err, fmt.Sprintf("Failed to close open file %s", opts.Output)))
+		}
+	}()
+	fwriter := bufio.NewWriter(file)
+
+	tplData := mainTemplateData{
+		Generator: "go generate forge model",
+		Version:   version,
+		Package:   env.GoPackage,
+		Imports:   findImports(body.Bytes(), []string{"errors", "fmt", "strings", "time"}),
+	}
+	if err := tplmain.Execute(fwriter, tplData); err != nil {
+		return kerrors.WithMsg(err, "Failed to execute main model template")
+	}
+	if _, err := body.WriteTo(fwriter); err != nil {
+		return kerrors.WithMsg(err, fmt.Sprintf("Failed to write to file: %s", opts.Output))
 	}
 
 	if err := fwriter.Flush(); err != nil {
@@ -463,98 +950,668 @@ func Generate(ctx context.Context, log klog.Logger, outputfs fs.FS, inputfs fs.F
 	return nil
 }
 
-func (m *modelDef) genModelSQL(placeholderPrefix string) modelSQLStrings {
-	colNum := len(m.Fields)
-	sqlDefs := make([]string, 0, colNum)
-	sqlDBNames := make([]string, 0, colNum)
-	sqlPlaceholders := make([]string, 0, colNum)
-	sqlPlaceholderTpl := make([]string, 0, colNum)
-	sqlPlaceholderCount := make([]string, 0, colNum)
-	sqlIdents := make([]string, 0, colNum)
+// readSchemaSQLFiles reads the sql files of raw queries relative to the
+// schema file into their sql
+func readSchemaSQLFiles(inputfs fs.FS, dir string, schema modelSchema) error {
+	for _, i := range schema.Models {
+		for _, j := range i.Queries {
+			// queries share the backing array of the schema
+			for n := range j {
+				if err := readQuerySQLFile(inputfs, dir, &j[n]); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
 
-	placeholderStart := 1
-	for n, i := range m.Fields {
-		sqlDefs = append(sqlDefs, fmt.Sprintf("%s %s", i.DBName, i.DBType))
-		sqlDBNames = append(sqlDBNames, i.DBName)
-		sqlPlaceholders = append(sqlPlaceholders, fmt.Sprintf("%s%d", placeholderPrefix, placeholderStart+n))
-		sqlPlaceholderTpl = append(sqlPlaceholderTpl, placeholderPrefix+"%d")
-		sqlPlaceholderCount = append(sqlPlaceholderCount, fmt.Sprintf("n+%d", placeholderStart+n))
-		sqlIdents = append(sqlIdents, fmt.Sprintf("m.%s", i.Ident))
+func readQuerySQLFile(inputfs fs.FS, dir string, q *queryOpts) error {
+	if q.SQLFile == "" {
+		return nil
 	}
-	for _, i := range m.Constraints {
-		fields := make([]string, 0, len(i.Columns))
-		for _, j := range i.Columns {
-			fields = append(fields, j.DBName)
-		}
-		sqlDefs = append(sqlDefs, fmt.Sprintf("%s (%s)", i.Kind, strings.Join(fields, ", ")))
+	if q.SQL != "" {
+		return kerrors.WithKind(nil, ErrInvalidSchema, fmt.Sprintf("Query %s may not take both sql and sqlfile", q.Name))
 	}
-	if m.opts.Setup != "" {
-		sqlDefs = append(sqlDefs, m.opts.Setup)
+	name := path.Join(dir, q.SQLFile)
+	b, err := fs.ReadFile(inputfs, name)
+	if err != nil {
+		return kerrors.WithMsg(err, fmt.Sprintf("Failed reading sql file: %s", name))
 	}
+	q.SQL = string(b)
+	return nil
+}
 
-	sqlIndicies := make([]modelIndex, 0, len(m.Indicies))
-	for _, i := range m.Indicies {
-		k := make([]string, 0, len(i.Columns))
-		for _, j := range i.Columns {
-			if j.Dir == "" {
-				k = append(k, j.Field.DBName)
-			} else {
-				k = append(k, fmt.Sprintf("%s %s", j.Field.DBName, j.Dir))
+// genQueryGroup writes the queries of a query struct
+func genQueryGroup(w *bytes.Buffer, d Dialect, tplQuery map[queryKind]*template.Template, tplStream *template.Template, prefix string, j queryGroupDef) error {
+	var err error
+	querySQLStrings := j.genQuerySQL(d)
+	for _, v := range j.genQueryVariants(d, querySQLStrings.Table) {
+		k := v.Def
+		tplData := queryTemplateData{
+			Prefix:     prefix,
+			ModelIdent: j.Ident,
+			Name:       v.Name,
+			Affected:   k.Affected,
+			SQL:        querySQLStrings,
+			SQLDel:     v.Del,
+		}
+		tplData.SQL.Table = v.Table
+		if err := k.checkQueryCondDialect(d); err != nil {
+			return err
+		}
+		if k.LockMode != LockModeUnknown {
+			lock, ok := d.Lock(k.LockMode, k.LockWait)
+			if !ok {
+				return kerrors.WithKind(nil, ErrInvalidDialect, fmt.Sprintf("Dialect %s does not support the lock of %s %s on struct %s", d.Name(), k.Kind, k.Name, j.Ident))
+			}
+			tplData.SQLLock = lock
+		}
+		if k.Affected == queryAffectedNotFound && k.Kind != queryKindDelEq && !d.AffectedMatched() {
+			return kerrors.WithKind(nil, ErrInvalidDialect, fmt.Sprintf("Dialect %s does not count unchanged rows for affected %s of %s %s on struct %s", d.Name(), k.Affected, k.Kind, k.Name, j.Ident))
+		}
+		switch k.Kind {
+		case queryKindGetOneEq:
+			tplData.SQLCond = k.genQueryCondSQL(d, nil, nil)
+		case queryKindGetGroup:
+			tplData.SQLCond = k.genQueryCondSQL(d, nil, []string{"limit", "offset"})
+			tplData.SQLOrder = k.genQueryOrderSQL(d, j.Ident, true)
+		case queryKindGetGroupEq:
+			tplData.SQLCond = k.genQueryCondSQL(d, nil, []string{"limit", "offset"})
+			tplData.SQLOrder = k.genQueryOrderSQL(d, j.Ident, true)
+		case queryKindGetGroupKeyset:
+			pageArgs := []string{"limit"}
+			tplData.SQLCond = k.genQueryCondSQL(d, nil, pageArgs)
+			tplData.SQLOrder = k.genQueryOrderSQL(d, j.Ident, false)
+			tplData.SQLKeyset = k.genQueryKeysetSQL(d, pageArgs)
+		case queryKindUpdEq:
+			tplData.SQLUpdate = j.genQueryUpdateSQL(d, false)
+			tplData.SQL.UpdateSet = tplData.SQLUpdate.UpdateSet
+			tplData.SQLCond = k.genQueryCondSQL(d, tplData.SQLUpdate.identArgs, nil)
+			if tplData.SQLUpdate.DBCond != "" {
+				tplData.SQLCond.DBCond = tplData.SQLUpdate.DBCond + " AND " + tplData.SQLCond.DBCond
+			}
+			tplData.SQLReturn, err = k.genQueryReturningSQL(d)
+			if err != nil {
+				return err
+			}
+		case queryKindIncrEq:
+			tplData.SQLUpdate = j.genQueryUpdateSQL(d, true)
+			tplData.SQL.UpdateSet = tplData.SQLUpdate.UpdateSet
+			tplData.SQLCond = k.genQueryCondSQL(d, tplData.SQLUpdate.identArgs, nil)
+			tplData.SQLReturn, err = k.genQueryReturningSQL(d)
+			if err != nil {
+				return err
+			}
+		case queryKindPatchEq:
+			tplData.SQLCond = k.genQueryCondSQL(d, nil, nil)
+			tplData.SQLPatch = j.genQueryPatchSQL(d)
+			tplData.SQLReturn, err = k.genQueryReturningSQL(d)
+			if err != nil {
+				return err
+			}
+		case queryKindDelEq:
+			tplData.SQLCond = k.genQueryCondSQL(d, v.DelArgs, nil)
+			tplData.SQLReturn, err = k.genQueryReturningSQL(d)
+			if err != nil {
+				return err
+			}
+		case queryKindCount, queryKindCountEq, queryKindExistsEq:
+			tplData.SQLCond = k.genQueryCondSQL(d, nil, nil)
+		case queryKindAggregate:
+			tplData.SQLCond = k.genQueryCondSQL(d, nil, []string{"limit", "offset"})
+			tplData.SQLOrder = k.genQueryOrderSQL(d, j.Ident, true)
+		case queryKindUpsert:
+			tplData.SQLUpsert = j.genQueryUpsertSQL(d, k)
+		case queryKindRaw:
+			tplData.SQLRaw = k.genQueryRawSQL(d, j.Fields)
+		case queryKindGetMapIn:
+			tplData.SQLCond = k.genQueryCondSQL(d, nil, nil)
+			tplData.SQLOrder = queryOrderSQLStrings{
+				DBOrder: joinQueryOrder(d, k.Order),
+			}
+			tplData.SQLMap = k.genQueryMapSQL(tplData.SQLCond)
+		}
+		tplData.SQLOrder.SortDeclared = v.SortDeclared
+		if err := tplQuery[k.Kind].Execute(w, tplData); err != nil {
+			return kerrors.WithMsg(err, fmt.Sprintf("Failed to execute template for query kind %s on struct %s of model %s", k.Kind, tplData.ModelIdent, tplData.Prefix))
+		}
+		if k.Stream {
+			// streamed queries are not paginated
+			tplData.SQLCond = k.genQueryCondSQL(d, nil, nil)
+			if err := tplStream.Execute(w, tplData); err != nil {
+				return kerrors.WithMsg(err, fmt.Sprintf("Failed to execute stream template for query kind %s on struct %s of model %s", k.Kind, tplData.ModelIdent, tplData.Prefix))
 			}
 		}
-		sqlIndicies = append(sqlIndicies, modelIndex{
-			Name:    i.Name,
-			Columns: strings.Join(k, ", "),
-		})
 	}
+	return nil
+}
 
-	return modelSQLStrings{
-		Setup:            strings.Join(sqlDefs, ", "),
-		DBNames:          strings.Join(sqlDBNames, ", "),
-		Placeholders:     strings.Join(sqlPlaceholders, ", "),
-		PlaceholderTpl:   strings.Join(sqlPlaceholderTpl, ", "),
-		PlaceholderCount: strings.Join(sqlPlaceholderCount, ", "),
-		Idents:           strings.Join(sqlIdents, ", "),
-		Indicies:         sqlIndicies,
-		ColNum:           strconv.Itoa(colNum),
+// genQueryVariants returns the variants of the queries of a query struct.
+// Queries of a soft deleted model exclude soft deleted rows, and are followed
+// by a variant which includes them. Delete queries of a soft deleted model
+// instead stamp the soft delete column, and are followed by a variant which
+// deletes rows.
+func (q *queryGroupDef) genQueryVariants(d Dialect, table string) []queryVariant {
+	hardDel := queryDelSQLStrings{
+		Method: "Del",
+		Stmt:   "DELETE FROM " + table,
+	}
+	hasSoftDel := q.softDel != nil
+	for _, i := range q.join {
+		if i.SoftDel != nil {
+			hasSoftDel = true
+		}
+	}
+	variants := make([]queryVariant, 0, len(q.Queries))
+	for _, k := range q.Queries {
+		v := queryVariant{
+			Def:   k,
+			Name:  k.Name,
+			Table: table,
+			Del:   hardDel,
+		}
+		if !hasSoftDel {
+			variants = append(variants, v)
+			continue
+		}
+		switch k.Kind {
+		case queryKindGetOneEq, queryKindGetGroup, queryKindGetGroupEq, queryKindGetGroupKeyset, queryKindGetMapIn, queryKindCount, queryKindCountEq, queryKindExistsEq, queryKindAggregate:
+			v.Def.Conds = q.appendSoftDelCond(k.Conds)
+			incl := queryVariant{
+				Def:          k,
+				Name:         k.Name + "IncludingDeleted",
+				Table:        table,
+				SortDeclared: true,
+			}
+			if len(q.join) != 0 {
+				incl.Table = genJoinTableSQL(d, q.join, false)
+			}
+			variants = append(variants, v, incl)
+		case queryKindDelEq:
+			v.Def.Conds = q.appendSoftDelCond(k.Conds)
+			v.DelArgs = []string{softDelStamp(*q.softDel)}
+			v.Del = queryDelSQLStrings{
+				Method: "Del",
+				Stmt:   "UPDATE " + table + " SET " + d.UpdateSet([]string{d.Ident(q.softDel.DBName)}, []string{placeholder(d, 1)}),
+			}
+			hard := queryVariant{
+				Def:   k,
+				Name:  k.Name,
+				Table: table,
+				Del: queryDelSQLStrings{
+					Method: "HardDel",
+					Stmt:   hardDel.Stmt,
+				},
+			}
+			variants = append(variants, v, hard)
+		default:
+			variants = append(variants, v)
+		}
+	}
+	return variants
+}
+
+// appendSoftDelCond returns the conditions of a query with an additional
+// condition excluding soft deleted rows of the queried model
+func (q *queryGroupDef) appendSoftDelCond(conds []queryCondField) []queryCondField {
+	if q.softDel == nil {
+		return conds
+	}
+	return append(slices.Clip(conds), queryCondField{
+		Kind:  condIsNull,
+		Field: *q.softDel,
+	})
+}
+
+// findImports returns the packages which are referenced by generated code
+func findImports(code []byte, pkgs []string) []string {
+	imports := make([]string, 0, len(pkgs))
+	for _, i := range pkgs {
+		if regexp.MustCompile(`\b` + regexp.QuoteMeta(i) + `\.`).Match(code) {
+			imports = append(imports, i)
+		}
+	}
+	return imports
+}
+
+func parseQueryTemplate(tplCondArgs *template.Template, name string, text string) (*template.Template, error) {
+	t, err := tplCondArgs.Clone()
+	if err != nil {
+		return nil, err
+	}
+	return t.New(name).Parse(text)
+}
+
+func (m *modelDef) genModelSQL(d Dialect) (modelSQLStrings, error) {
+	returningSet := map[string]struct{}{}
+	sqlReturning := make([]string, 0, len(m.Returning))
+	sqlReturningIdentRefs := make([]string, 0, len(m.Returning))
+	for _, i := range m.Returning {
+		returningSet[i.DBName] = struct{}{}
+		sqlReturning = append(sqlReturning, d.Ident(i.DBName))
+		sqlReturningIdentRefs = append(sqlReturningIdentRefs, fmt.Sprintf("&m.%s", i.Ident))
+	}
+	returning := ""
+	if len(sqlReturning) != 0 {
+		var ok bool
+		returning, ok = d.Returning(sqlReturning)
+		if !ok {
+			return modelSQLStrings{}, kerrors.WithKind(nil, ErrInvalidDialect, fmt.Sprintf("Dialect %s does not support returning on struct %s", d.Name(), m.Ident))
+		}
+	}
+
+	// returning fields are generated by the database and are not inserted
+	colNum := len(m.Fields) - len(m.Returning)
+	sqlDefs := make([]string, 0, len(m.Fields))
+	sqlDBNames := make([]string, 0, colNum)
+	sqlPlaceholders := make([]string, 0, colNum)
+	sqlPlaceholderTpl := make([]string, 0, colNum)
+	sqlPlaceholderCount := make([]string, 0, colNum)
+	sqlIdents := make([]string, 0, colNum)
+	insertTime := map[string]struct{}{}
+	for _, i := range m.InsertTime {
+		insertTime[i.DBName] = struct{}{}
+	}
+	var sqlInsertTime []queryTimeAssign
+
+	placeholderStart := 1
+	for _, i := range m.Fields {
+		sqlDefs = append(sqlDefs, fmt.Sprintf("%s %s", d.Ident(i.DBName), i.DBType))
+		if _, ok := returningSet[i.DBName]; ok {
+			continue
+		}
+		sqlDBNames = append(sqlDBNames, d.Ident(i.DBName))
+		if _, ok := insertTime[i.DBName]; ok {
+			if m.TimeSQL {
+				// columns set by the database are not bound
+				sqlPlaceholders = append(sqlPlaceholders, sqlCurrentTime)
+				sqlPlaceholderTpl = append(sqlPlaceholderTpl, sqlCurrentTime)
+				colNum--
+				continue
+			}
+			sqlInsertTime = append(sqlInsertTime, queryTimeAssign{
+				Ident: i.Ident,
+				Value: timeValue(i.GoType),
+			})
+		}
+		n := len(sqlIdents)
+		sqlPlaceholders = append(sqlPlaceholders, placeholder(d, placeholderStart+n))
+		sqlPlaceholderTpl = append(sqlPlaceholderTpl, d.Placeholder())
+		sqlPlaceholderCount = append(sqlPlaceholderCount, fmt.Sprintf("n+%d", placeholderStart+n))
+		sqlIdents = append(sqlIdents, fmt.Sprintf("m.%s", i.Ident))
+	}
+	for _, i := range m.Constraints {
+		fields := make([]string, 0, len(i.Columns))
+		for _, j := range i.Columns {
+			fields = append(fields, d.Ident(j.DBName))
+		}
+		sqlDefs = append(sqlDefs, fmt.Sprintf("%s (%s)", i.Kind, strings.Join(fields, ", ")))
+	}
+	if m.opts.Setup != "" {
+		sqlDefs = append(sqlDefs, m.opts.Setup)
+	}
+
+	sqlIndicies := make([]SQLIndex, 0, len(m.Indicies))
+	for _, i := range m.Indicies {
+		k := make([]string, 0, len(i.Columns))
+		for _, j := range i.Columns {
+			if j.Dir == "" {
+				k = append(k, d.Ident(j.Field.DBName))
+			} else {
+				k = append(k, fmt.Sprintf("%s %s", d.Ident(j.Field.DBName), j.Dir))
+			}
+		}
+		sqlIndicies = append(sqlIndicies, SQLIndex{
+			Name:    fmt.Sprintf("%s_%s_index", sqlTableName, i.Name),
+			Columns: strings.Join(k, ", "),
+		})
 	}
+
+	return modelSQLStrings{
+		Positional:          d.Positional(),
+		Setup:               d.Setup(sqlTableName, sqlDefs, sqlIndicies),
+		Table:               d.Ident(sqlTableName),
+		DBNames:             strings.Join(sqlDBNames, ", "),
+		Placeholders:        strings.Join(sqlPlaceholders, ", "),
+		PlaceholderTpl:      strings.Join(sqlPlaceholderTpl, ", "),
+		PlaceholderCount:    strings.Join(sqlPlaceholderCount, ", "),
+		Idents:              strings.Join(sqlIdents, ", "),
+		ColNum:              strconv.Itoa(colNum),
+		OnConflictDoNothing: d.OnConflictDoNothing(sqlDBNames),
+		Returning:           returning,
+		ReturningIdentRefs:  strings.Join(sqlReturningIdentRefs, ", "),
+		Clock:               m.hasClock(),
+		InsertTime:          sqlInsertTime,
+	}, nil
 }
 
-func (q *queryGroupDef) genQuerySQL(placeholderPrefix string) querySQLStrings {
+func (q *queryGroupDef) genQuerySQL(d Dialect) querySQLStrings {
 	colNum := len(q.Fields)
 	sqlDBNames := make([]string, 0, colNum)
 	sqlIdents := make([]string, 0, colNum)
 	sqlIdentRefs := make([]string, 0, colNum)
 	sqlPlaceholders := make([]string, 0, colNum)
+	sqlPlaceholderTpl := make([]string, 0, colNum)
+	sqlPlaceholderCount := make([]string, 0, colNum)
+	var sqlGroupBy []string
 
 	placeholderStart := 1
 	for n, i := range q.Fields {
-		sqlDBNames = append(sqlDBNames, i.DBName)
+		sqlDBNames = append(sqlDBNames, aggIdent(d, i.Agg, i.Alias, i.DBName))
+		if i.Agg == "" {
+			sqlGroupBy = append(sqlGroupBy, fieldIdent(d, i.Alias, i.DBName))
+		}
 		sqlIdents = append(sqlIdents, fmt.Sprintf("m.%s", i.Ident))
 		sqlIdentRefs = append(sqlIdentRefs, fmt.Sprintf("&m.%s", i.Ident))
-		sqlPlaceholders = append(sqlPlaceholders, fmt.Sprintf("%s%d", placeholderPrefix, placeholderStart+n))
+		sqlPlaceholders = append(sqlPlaceholders, placeholder(d, placeholderStart+n))
+		sqlPlaceholderTpl = append(sqlPlaceholderTpl, d.Placeholder())
+		sqlPlaceholderCount = append(sqlPlaceholderCount, fmt.Sprintf("n+%d", placeholderStart+n))
+	}
+
+	table := d.Ident(sqlTableName)
+	if len(q.join) != 0 {
+		table = genJoinTableSQL(d, q.join, true)
+	}
+	// query structs without aggregate fields are not grouped
+	if !q.hasAgg() {
+		sqlGroupBy = nil
 	}
 
 	return querySQLStrings{
-		DBNames:      strings.Join(sqlDBNames, ", "),
-		NumDBNames:   len(sqlDBNames),
-		Idents:       strings.Join(sqlIdents, ", "),
-		IdentRefs:    strings.Join(sqlIdentRefs, ", "),
-		Placeholders: strings.Join(sqlPlaceholders, ", "),
-		ColNum:       fmt.Sprintf("%d", colNum),
-	}
-}
-
-func (q *queryDef) genQueryCondSQL(placeholderPrefix string, offset int) queryCondSQLStrings {
-	sqlIdentParams := make([]string, 0, len(q.Conds))
-	sqlDBCond := make([]string, 0, len(q.Conds))
-	sqlIdentArgs := make([]string, 0, len(q.Conds))
-	sqlArrIdentArgs := make([]string, 0, len(q.Conds))
-	sqlArrIdentArgsLen := make([]string, 0, len(q.Conds))
-	paramCount := offset
-	for _, i := range q.Conds {
+		Positional:       d.Positional(),
+		Table:            table,
+		DBNames:          strings.Join(sqlDBNames, ", "),
+		NumDBNames:       len(sqlDBNames),
+		Idents:           strings.Join(sqlIdents, ", "),
+		IdentRefs:        strings.Join(sqlIdentRefs, ", "),
+		Placeholders:     strings.Join(sqlPlaceholders, ", "),
+		PlaceholderTpl:   strings.Join(sqlPlaceholderTpl, ", "),
+		PlaceholderCount: strings.Join(sqlPlaceholderCount, ", "),
+		UpdateSet:        d.UpdateSet(sqlDBNames, sqlPlaceholders),
+		ColNum:           fmt.Sprintf("%d", colNum),
+		GroupBy:          strings.Join(sqlGroupBy, ", "),
+	}
+}
+
+// genQueryUpdateSQL generates the update of updeq, or of increq if incr is
+// true. The version column of a versioned model is incremented rather than
+// set, and the update is conditional on the version of the query struct.
+// Update time columns are set to the current time, including those which are
+// not fields of the query struct.
+func (q *queryGroupDef) genQueryUpdateSQL(d Dialect, incr bool) queryUpdateSQLStrings {
+	updateTime := map[string]struct{}{}
+	for _, i := range q.updateTime {
+		updateTime[i.DBName] = struct{}{}
+	}
+	sqlDBNames := make([]string, 0, len(q.Fields)+len(q.updateTime))
+	sqlVals := make([]string, 0, len(q.Fields)+len(q.updateTime))
+	sqlIdentArgs := make([]string, 0, len(q.Fields)+len(q.updateTime)+1)
+	clock := false
+	var sqlNow []queryTimeAssign
+	for _, i := range q.Fields {
+		dbName := d.Ident(i.DBName)
+		sqlDBNames = append(sqlDBNames, dbName)
+		if q.version != nil && i.DBName == q.version.DBName {
+			sqlVals = append(sqlVals, dbName+" + 1")
+			continue
+		}
+		if _, ok := updateTime[i.DBName]; ok {
+			delete(updateTime, i.DBName)
+			if q.timeSQL {
+				sqlVals = append(sqlVals, sqlCurrentTime)
+				continue
+			}
+			clock = true
+			sqlNow = append(sqlNow, queryTimeAssign{
+				Ident: i.Ident,
+				Value: timeValue(i.GoType),
+			})
+			sqlIdentArgs = append(sqlIdentArgs, fmt.Sprintf("m.%s", i.Ident))
+			sqlVals = append(sqlVals, placeholder(d, len(sqlIdentArgs)))
+			continue
+		}
+		sqlIdentArgs = append(sqlIdentArgs, fmt.Sprintf("m.%s", i.Ident))
+		if incr {
+			sqlVals = append(sqlVals, fmt.Sprintf("%s + %s", dbName, placeholder(d, len(sqlIdentArgs))))
+		} else {
+			sqlVals = append(sqlVals, placeholder(d, len(sqlIdentArgs)))
+		}
+	}
+	// update time columns which are not fields of the query struct are set
+	// without assigning the query struct
+	for _, i := range q.updateTime {
+		if _, ok := updateTime[i.DBName]; !ok {
+			continue
+		}
+		sqlDBNames = append(sqlDBNames, d.Ident(i.DBName))
+		if q.timeSQL {
+			sqlVals = append(sqlVals, sqlCurrentTime)
+			continue
+		}
+		clock = true
+		sqlIdentArgs = append(sqlIdentArgs, timeValue(i.GoType))
+		sqlVals = append(sqlVals, placeholder(d, len(sqlIdentArgs)))
+	}
+	if q.version == nil {
+		return queryUpdateSQLStrings{
+			UpdateSet: d.UpdateSet(sqlDBNames, sqlVals),
+			Clock:     clock,
+			Now:       sqlNow,
+			identArgs: sqlIdentArgs,
+		}
+	}
+	// the version is bound after the updated columns
+	sqlIdentArgs = append(sqlIdentArgs, fmt.Sprintf("m.%s", q.version.Ident))
+	return queryUpdateSQLStrings{
+		Version:   q.version.Ident,
+		UpdateSet: d.UpdateSet(sqlDBNames, sqlVals),
+		DBCond:    fmt.Sprintf("%s = %s", d.Ident(q.version.DBName), placeholder(d, len(sqlIdentArgs))),
+		Clock:     clock,
+		Now:       sqlNow,
+		identArgs: sqlIdentArgs,
+	}
+}
+
+// hasAgg returns true if the query struct has aggregate fields
+func (q *queryGroupDef) hasAgg() bool {
+	for _, i := range q.Fields {
+		if i.Agg != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// genJoinTableSQL generates the joined tables of a join query struct. Soft
+// deleted rows of joined tables are excluded by their join condition if
+// excludeDeleted is true.
+func genJoinTableSQL(d Dialect, tables []joinTableDef, excludeDeleted bool) string {
+	var b strings.Builder
+	for n, i := range tables {
+		if n != 0 {
+			b.WriteString(" ")
+			b.WriteString(i.Kind)
+			b.WriteString(" ")
+		}
+		b.WriteString(d.Ident(fmt.Sprintf(`"+t.%s+"`, joinTableNameIdent(i.Prefix))))
+		b.WriteString(" ")
+		b.WriteString(d.Ident(i.Alias))
+		if len(i.On) != 0 {
+			on := make([]string, 0, len(i.On))
+			for _, j := range i.On {
+				on = append(on, fmt.Sprintf("%s = %s", fieldIdent(d, j.Col.Alias, j.Col.DBName), fieldIdent(d, j.Ref.Alias, j.Ref.DBName)))
+			}
+			if excludeDeleted && i.SoftDel != nil {
+				on = append(on, fmt.Sprintf("%s IS NULL", fieldIdent(d, i.SoftDel.Alias, i.SoftDel.DBName)))
+			}
+			b.WriteString(" ON ")
+			b.WriteString(strings.Join(on, " AND "))
+		}
+	}
+	return b.String()
+}
+
+// joinTableNameIdent returns the field of the join table struct holding the
+// table name of a model
+func joinTableNameIdent(prefix string) string {
+	return strings.ToUpper(prefix[:1]) + prefix[1:] + "TableName"
+}
+
+// genJoinFields generates the fields of the join table struct
+func (j *joinDef) genJoinFields() []string {
+	maxLen := 0
+	for _, i := range j.Tables {
+		maxLen = max(maxLen, len(joinTableNameIdent(i.Prefix)))
+	}
+	fields := make([]string, 0, len(j.Tables))
+	for _, i := range j.Tables {
+		fields = append(fields, fmt.Sprintf("%-*s string", maxLen, joinTableNameIdent(i.Prefix)))
+	}
+	return fields
+}
+
+// fieldIdent returns the quoted column of a field, which is qualified by the
+// alias of its table in a join
+func fieldIdent(d Dialect, alias string, dbName string) string {
+	if alias == "" {
+		return d.Ident(dbName)
+	}
+	return d.Ident(alias) + "." + d.Ident(dbName)
+}
+
+// aggIdent returns the aggregate expression of a field, or the quoted column
+// if it is not an aggregate
+func aggIdent(d Dialect, agg string, alias string, dbName string) string {
+	if agg == "" {
+		return fieldIdent(d, alias, dbName)
+	}
+	if dbName == "*" {
+		return agg + "(*)"
+	}
+	return agg + "(" + fieldIdent(d, alias, dbName) + ")"
+}
+
+// genModelFilterSQL generates the filter builder of a model which selects
+// into the query structs of the model
+func (m *modelDef) genModelFilterSQL(d Dialect, queryGroups []queryGroupDef) modelFilterSQLStrings {
+	fields := make([]modelFilterField, 0, len(m.Fields))
+	for _, i := range m.Fields {
+		// params are prefixed to avoid colliding with go keywords and the
+		// identifiers of the generated methods
+		param := "v" + i.Ident
+		dbName := d.Ident(i.DBName)
+		fields = append(fields, modelFilterField{
+			Ident:  i.Ident,
+			Param:  param,
+			GoType: i.GoType,
+			DBName: dbName,
+			InList: d.InList(dbName, `"+strings.Join(placeholders, ", ")+"`),
+			Like:   i.GoType == "string",
+		})
+	}
+	queries := make([]modelFilterQuery, 0, len(queryGroups))
+	for _, i := range queryGroups {
+		if i.hasAgg() {
+			continue
+		}
+		sqlStrings := i.genQuerySQL(d)
+		queries = append(queries, modelFilterQuery{
+			Ident:     i.Ident,
+			DBNames:   sqlStrings.DBNames,
+			IdentRefs: sqlStrings.IdentRefs,
+		})
+	}
+	softDel := ""
+	if m.SoftDel != nil {
+		softDel = d.Ident(m.SoftDel.DBName) + " IS NULL"
+	}
+	// the table name is quoted around the expression of sqlTableName
+	quotePrefix, quoteSuffix, _ := strings.Cut(d.Ident(sqlTableName), sqlTableName)
+	tableExpr := make([]string, 0, 3)
+	if quotePrefix != "" {
+		tableExpr = append(tableExpr, `"`+quotePrefix+`"`)
+	}
+	tableExpr = append(tableExpr, "t.TableName")
+	if quoteSuffix != "" {
+		tableExpr = append(tableExpr, `"`+quoteSuffix+`"`)
+	}
+	return modelFilterSQLStrings{
+		Positional:     d.Positional(),
+		TableExpr:      strings.Join(tableExpr, " + "),
+		Placeholder:    d.Placeholder(),
+		ArrPlaceholder: d.InListElem(),
+		Limit:          " " + d.Limit(d.Placeholder(), ""),
+		LimitOffset:    " " + d.Limit(d.Placeholder(), d.Placeholder()),
+		LikeEscape:     sqldb.LikeEscape,
+		SoftDel:        softDel,
+		Ops: []modelFilterOp{
+			{Name: "Eq", Op: "="},
+			{Name: "Neq", Op: "<>"},
+			{Name: "Lt", Op: "<"},
+			{Name: "Leq", Op: "<="},
+			{Name: "Gt", Op: ">"},
+			{Name: "Geq", Op: ">="},
+		},
+		Fields:  fields,
+		Queries: queries,
+	}
+}
+
+// genQueryCondSQL generates the condition of a query. prefixArgs are the args
+// bound to the placeholders preceding the condition. pageArgs are the
+// pagination args of a paginated query, e.g. limit and offset.
+func (q *queryDef) genQueryCondSQL(d Dialect, prefixArgs []string, pageArgs []string) queryCondSQLStrings {
+	positional := d.Positional()
+	sqlPageArgs := pageArgs
+	if !positional && len(pageArgs) != 0 {
+		// numbered dialects bind pagination args to the first placeholders
+		prefixArgs = append(append([]string{}, pageArgs...), prefixArgs...)
+		sqlPageArgs = nil
+	}
+
+	// conditions are bound in the order in which they appear in the query, and
+	// having conditions follow the where conditions
+	conds := flattenQueryConds(nil, q.Conds)
+	conds = flattenQueryConds(conds, q.Having)
+	sqlIdentParams := make([]string, 0, len(conds))
+	sqlDBCond := make([]string, 0, len(conds))
+	sqlIdentArgs := make([]string, 0, len(prefixArgs)+len(conds)+len(sqlPageArgs))
+	sqlIdentArgs = append(sqlIdentArgs, prefixArgs...)
+	sqlArrIdentArgs := make([]string, 0, len(conds))
+	sqlArrIdentArgsLen := make([]string, 0, len(conds))
+	argGroups := make([]queryArgGroup, 0, 2+len(conds))
+	// positional dialects bind args in the order in which they appear in the
+	// query, so args are grouped in order between IN lists
+	groupArgs := make([]string, 0, len(prefixArgs)+len(conds)+len(sqlPageArgs))
+	groupArgs = append(groupArgs, prefixArgs...)
+	paramCount := len(prefixArgs)
+	// params are named by their fields, and repeated names are numbered
+	paramNames := map[string]struct{}{}
+	for _, i := range pageArgs {
+		paramNames[i] = struct{}{}
+	}
+	// params of fields of joined models which share a name are qualified by
+	// their model
+	identModels := map[string]string{}
+	qualified := map[string]struct{}{}
+	for _, i := range conds {
+		if i.Field.Model == "" {
+			continue
+		}
+		if m, ok := identModels[i.Field.Ident]; !ok {
+			identModels[i.Field.Ident] = i.Field.Model
+		} else if m != i.Field.Model {
+			qualified[i.Field.Ident] = struct{}{}
+		}
+	}
+	condParams := make([]string, 0, len(conds))
+	for _, i := range conds {
 		paramName := strings.ToLower(i.Field.Ident)
-		dbName := i.Field.DBName
+		if _, ok := qualified[i.Field.Ident]; ok {
+			paramName = i.Field.Model + i.Field.Ident
+		}
+		dbName := aggIdent(d, i.Field.Agg, i.Field.Alias, i.Field.DBName)
 		paramType := i.Field.GoType
 		condText := "="
 		switch i.Kind {
@@ -574,275 +1631,1651 @@ func (q *queryDef) genQueryCondSQL(placeholderPrefix string, offset int) queryCo
 		case condLike:
 			paramName = paramName + "Prefix"
 			condText = "LIKE"
+		case condPrefix, condIPrefix:
+			paramName = paramName + "Prefix"
+		case condSuffix, condISuffix:
+			paramName = paramName + "Suffix"
+		case condContains, condIContains:
+			paramName = paramName + "Substr"
+		case condEqAny:
+			paramName = paramName + "s"
+			paramType = "[]" + paramType
+		case condJSONHasKey:
+			paramName = paramName + "Key"
+			paramType = "string"
+		}
+		switch i.Kind {
+		case condIsNull, condNotNull:
+		case condBetween:
+			paramName = uniqueParamName(paramNames, paramName, []string{"Start", "End"})
+		default:
+			paramName = uniqueParamName(paramNames, paramName, []string{""})
 		}
+		condParams = append(condParams, paramName)
 
-		sqlIdentParams = append(sqlIdentParams, fmt.Sprintf("%s %s", paramName, paramType))
-		if i.Kind == condIn {
-			sqlDBCond = append(sqlDBCond, fmt.Sprintf(`%s IN (VALUES "+placeholders%s+")`, dbName, paramName))
+		switch i.Kind {
+		case condIsNull:
+			sqlDBCond = append(sqlDBCond, fmt.Sprintf("%s IS NULL", dbName))
+		case condNotNull:
+			sqlDBCond = append(sqlDBCond, fmt.Sprintf("%s IS NOT NULL", dbName))
+		case condBetween:
+			startName := paramName + "Start"
+			endName := paramName + "End"
+			sqlIdentParams = append(sqlIdentParams, fmt.Sprintf("%s, %s %s", startName, endName, paramType))
+			sqlDBCond = append(sqlDBCond, fmt.Sprintf("%s BETWEEN %s AND %s", dbName, placeholder(d, paramCount+1), placeholder(d, paramCount+2)))
+			paramCount += 2
+			sqlIdentArgs = append(sqlIdentArgs, startName, endName)
+			groupArgs = append(groupArgs, startName, endName)
+		case condPrefix, condSuffix, condContains, condIPrefix, condISuffix, condIContains:
+			sqlIdentParams = append(sqlIdentParams, fmt.Sprintf("%s %s", paramName, paramType))
+			paramCount++
+			pattern := placeholder(d, paramCount)
+			var predicate string
+			switch i.Kind {
+			case condIPrefix, condISuffix, condIContains:
+				predicate = d.ILike(dbName, pattern)
+			default:
+				predicate = fmt.Sprintf("%s LIKE %s", dbName, pattern)
+			}
+			sqlDBCond = append(sqlDBCond, fmt.Sprintf("%s ESCAPE '%s'", predicate, sqldb.LikeEscape))
+			// the input is escaped at runtime such that it is matched literally
+			arg := fmt.Sprintf("sqldb.EscapeLike(%s)", paramName)
+			switch i.Kind {
+			case condPrefix, condIPrefix:
+				arg = arg + `+"%"`
+			case condSuffix, condISuffix:
+				arg = `"%"+` + arg
+			default:
+				arg = `"%"+` + arg + `+"%"`
+			}
+			sqlIdentArgs = append(sqlIdentArgs, arg)
+			groupArgs = append(groupArgs, arg)
+		case condArrContains, condArrOverlap, condEqAny, condJSONContains, condJSONHasKey:
+			sqlIdentParams = append(sqlIdentParams, fmt.Sprintf("%s %s", paramName, paramType))
+			paramCount++
+			// support of the dialect is checked by checkQueryCondDialect
+			predicate, _ := d.ContainerCond(i.Kind.containerOp(), dbName, placeholder(d, paramCount))
+			sqlDBCond = append(sqlDBCond, predicate)
+			sqlIdentArgs = append(sqlIdentArgs, paramName)
+			groupArgs = append(groupArgs, paramName)
+		case condIn:
+			sqlIdentParams = append(sqlIdentParams, fmt.Sprintf("%s %s", paramName, paramType))
+			sqlDBCond = append(sqlDBCond, d.InList(dbName, fmt.Sprintf(`"+placeholders%s+"`, paramName)))
 			sqlArrIdentArgs = append(sqlArrIdentArgs, paramName)
 			sqlArrIdentArgsLen = append(sqlArrIdentArgsLen, fmt.Sprintf("len(%s)", paramName))
-		} else {
+			if positional {
+				argGroups = appendArgGroup(argGroups, groupArgs)
+				groupArgs = groupArgs[:0]
+				argGroups = append(argGroups, queryArgGroup{
+					Arr: paramName,
+				})
+			}
+		default:
+			sqlIdentParams = append(sqlIdentParams, fmt.Sprintf("%s %s", paramName, paramType))
 			paramCount++
-			sqlDBCond = append(sqlDBCond, fmt.Sprintf("%s %s %s%d", dbName, condText, placeholderPrefix, paramCount))
+			sqlDBCond = append(sqlDBCond, fmt.Sprintf("%s %s %s", dbName, condText, placeholder(d, paramCount)))
 			sqlIdentArgs = append(sqlIdentArgs, paramName)
+			groupArgs = append(groupArgs, paramName)
+		}
+	}
+	sqlIdentArgs = append(sqlIdentArgs, sqlPageArgs...)
+	groupArgs = append(groupArgs, sqlPageArgs...)
+	paramCount += len(sqlPageArgs)
+	argGroups = appendArgGroup(argGroups, groupArgs)
+	if !positional {
+		for _, i := range sqlArrIdentArgs {
+			argGroups = append(argGroups, queryArgGroup{
+				Arr: i,
+			})
 		}
 	}
+	dbCond, sqlDBCond := joinQueryConds(q.Conds, condGroupAll, sqlDBCond)
+	havingCond, _ := joinQueryConds(q.Having, condGroupAll, sqlDBCond)
 	return queryCondSQLStrings{
 		IdentParams:     strings.Join(sqlIdentParams, ", "),
-		DBCond:          strings.Join(sqlDBCond, " AND "),
+		DBCond:          dbCond,
+		HavingCond:      havingCond,
 		IdentArgs:       strings.Join(sqlIdentArgs, ", "),
+		ArgGroups:       argGroups,
 		ArrIdentArgs:    sqlArrIdentArgs,
 		ArrIdentArgsLen: strings.Join(sqlArrIdentArgsLen, "+"),
+		ArrPlaceholder:  d.InListElem(),
 		ParamCount:      paramCount,
+		Positional:      positional,
+		condParams:      condParams,
 	}
 }
 
-func (q *queryDef) genQueryOrderSQL() queryOrderSQLStrings {
-	colOrder := make([]string, 0, len(q.Order))
-	for _, i := range q.Order {
-		if i.Dir == "" {
-			colOrder = append(colOrder, i.Field.DBName)
-		} else {
-			colOrder = append(colOrder, fmt.Sprintf("%s %s", i.Field.DBName, i.Dir))
-		}
+// uniqueParamName returns name, or name numbered from 2 if name with any of
+// suffixes is already in names, and adds the resulting names to names
+func uniqueParamName(names map[string]struct{}, name string, suffixes []string) string {
+	base := name
+	for n := 2; slices.ContainsFunc(suffixes, func(suffix string) bool {
+		_, ok := names[name+suffix]
+		return ok
+	}); n++ {
+		name = fmt.Sprintf("%s%d", base, n)
 	}
-	return queryOrderSQLStrings{
-		DBOrder: strings.Join(colOrder, ", "),
+	for _, i := range suffixes {
+		names[name+i] = struct{}{}
 	}
+	return name
 }
 
-func parseModelDefinitions(modelObjects []dirObjPair, modelTag string, fset *token.FileSet, schema modelSchema) ([]modelDef, error) {
-	modelDefs := make([]modelDef, 0, len(modelObjects))
+// checkQueryCondDialect returns an error if the dialect does not support a
+// condition of the query
+func (q *queryDef) checkQueryCondDialect(d Dialect) error {
+	for _, i := range flattenQueryConds(nil, q.Conds) {
+		op := i.Kind.containerOp()
+		if op == ContainerOpUnknown {
+			continue
+		}
+		if _, ok := d.ContainerCond(op, "", ""); !ok {
+			return kerrors.WithKind(nil, ErrInvalidDialect, fmt.Sprintf("Dialect %s does not support the condition on field %s of %s %s", d.Name(), i.Field.DBName, q.Kind, q.Name))
+		}
+	}
+	return nil
+}
 
-	for _, i := range modelObjects {
-		prefix := i.Dir.Directive
-		if prefix == "" {
-			return nil, kerrors.WithKind(nil, ErrInvalidFile, "Model directive without prefix")
+// flattenQueryConds appends the conditions of all groups in the order in which
+// they appear in the query
+func flattenQueryConds(flat []queryCondField, conds []queryCondField) []queryCondField {
+	for _, i := range conds {
+		if i.Group != condGroupNone {
+			flat = flattenQueryConds(flat, i.Conds)
+		} else {
+			flat = append(flat, i)
 		}
-		opts := schema.Models[prefix]
-		if i.Obj.Kind != gopackages.ObjKindDeclType {
-			return nil, kerrors.WithKind(nil, ErrInvalidFile, "Model directive used on non-type declaration")
+	}
+	return flat
+}
+
+// joinQueryConds joins the predicates of flattened conditions by their groups,
+// and returns the remaining predicates
+func joinQueryConds(conds []queryCondField, group condGroup, predicates []string) (string, []string) {
+	k := make([]string, 0, len(conds))
+	for _, i := range conds {
+		if i.Group == condGroupNone {
+			k = append(k, predicates[0])
+			predicates = predicates[1:]
+			continue
 		}
-		typeSpec, ok := i.Obj.Obj.(*ast.TypeSpec)
-		if !ok {
-			return nil, kerrors.WithMsg(nil, "Unexpected directive object type")
+		var p string
+		p, predicates = joinQueryConds(i.Conds, i.Group, predicates)
+		if len(i.Conds) > 1 {
+			p = "(" + p + ")"
 		}
-		structName := typeSpec.Name.Name
-		structType, ok := typeSpec.Type.(*ast.StructType)
-		if !ok {
-			return nil, kerrors.WithKind(nil, ErrInvalidFile, "Model directive used on non-struct type declaration")
+		k = append(k, p)
+	}
+	return strings.Join(k, group.String()), predicates
+}
+
+func appendArgGroup(groups []queryArgGroup, args []string) []queryArgGroup {
+	if len(args) == 0 {
+		return groups
+	}
+	return append(groups, queryArgGroup{
+		Args: strings.Join(args, ", "),
+	})
+}
+
+// genQueryOrderSQL generates the order of a query. structIdent is the query
+// struct of the query.
+func (q *queryDef) genQueryOrderSQL(d Dialect, structIdent string, offset bool) queryOrderSQLStrings {
+	sqlOffset := ""
+	if offset {
+		sqlOffset = placeholder(d, 2)
+	}
+	if len(q.Sort) == 0 {
+		return queryOrderSQLStrings{
+			DBOrder: joinQueryOrder(d, q.Order),
+			Limit:   d.Limit(placeholder(d, 1), sqlOffset),
 		}
-		if structType.Incomplete {
-			return nil, kerrors.WithMsg(nil, "Unexpected incomplete struct definition")
+	}
+	// the order is selected at runtime from the prebuilt orders of the sorts
+	sortIdent := structIdent + q.Name + "Sort"
+	sorts := make([]querySortSQLStrings, 0, len(q.Sort))
+	for _, i := range q.Sort {
+		sorts = append(sorts, querySortSQLStrings{
+			Ident:   sortIdent + i.Name,
+			DBOrder: joinQueryOrder(d, i.Order),
+		})
+	}
+	return queryOrderSQLStrings{
+		DBOrder:   `"+orderBy+"`,
+		Limit:     d.Limit(placeholder(d, 1), sqlOffset),
+		SortIdent: sortIdent,
+		Sorts:     sorts,
+	}
+}
+
+func joinQueryOrder(d Dialect, order []queryOrderField) string {
+	colOrder := make([]string, 0, len(order))
+	for _, i := range order {
+		if i.Dir == "" {
+			colOrder = append(colOrder, aggIdent(d, i.Field.Agg, i.Field.Alias, i.Field.DBName))
+		} else {
+			colOrder = append(colOrder, fmt.Sprintf("%s %s", aggIdent(d, i.Field.Agg, i.Field.Alias, i.Field.DBName), i.Dir))
 		}
-		astFields, err := findFields(modelTag, structType, fset)
-		if err != nil {
-			return nil, err
+	}
+	return strings.Join(colOrder, ", ")
+}
+
+// genQueryKeysetSQL generates the condition of a query which selects the rows
+// following the cursor row in the keyset order
+func (q *queryDef) genQueryKeysetSQL(d Dialect, pageArgs []string) queryKeysetSQLStrings {
+	positional := d.Positional()
+	placeholderStart := 1
+	if !positional {
+		placeholderStart += len(pageArgs)
+	}
+
+	mixed := false
+	for _, i := range q.Keyset {
+		if i.Desc != q.Keyset[0].Desc {
+			mixed = true
+			break
 		}
-		if len(astFields) == 0 {
-			return nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("No model fields found on struct: %s", structName))
+	}
+
+	var sqlPred string
+	var sqlArgs []string
+	if !mixed {
+		// a row value comparison is able to use a multicolumn index when all
+		// columns are ordered in the same direction
+		condText := ">"
+		if q.Keyset[0].Desc {
+			condText = "<"
 		}
-		modelFields, fieldMap, err := parseModelFields(astFields)
-		if err != nil {
-			return nil, kerrors.WithMsg(err, fmt.Sprintf("Invalid model fields for struct %s", structName))
+		dbNames := make([]string, 0, len(q.Keyset))
+		placeholders := make([]string, 0, len(q.Keyset))
+		for n, i := range q.Keyset {
+			dbNames = append(dbNames, d.Ident(i.Field.DBName))
+			placeholders = append(placeholders, placeholder(d, placeholderStart+n))
+			sqlArgs = append(sqlArgs, fmt.Sprintf("after.%s", i.Field.Ident))
 		}
-		constraints := make([]modelConstraint, 0, len(opts.Model.Constraints))
-		for _, i := range opts.Model.Constraints {
-			if i.Kind == "" {
-				return nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Missing constraint kind for struct %s", structName))
-			}
-			if len(i.Columns) == 0 {
-				return nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("No columns for constraint of struct %s", structName))
+		if len(q.Keyset) == 1 {
+			sqlPred = fmt.Sprintf("%s %s %s", dbNames[0], condText, placeholders[0])
+		} else {
+			sqlPred = fmt.Sprintf("(%s) %s (%s)", strings.Join(dbNames, ", "), condText, strings.Join(placeholders, ", "))
+		}
+	} else {
+		// mixed directions are expanded to (a > $1 OR (a = $1 AND b < $2)) where
+		// numbered placeholders may be repeated, but positional args must be
+		// bound for each occurrence
+		terms := make([]string, 0, len(q.Keyset))
+		for n, i := range q.Keyset {
+			condText := ">"
+			if i.Desc {
+				condText = "<"
 			}
-			fields := make([]modelField, 0, len(i.Columns))
-			for _, j := range i.Columns {
-				f, ok := fieldMap[j]
-				if !ok {
-					return nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Unknown field %s for constraint of struct %s", j, structName))
+			k := make([]string, 0, n+1)
+			for m, j := range q.Keyset[:n] {
+				k = append(k, fmt.Sprintf("%s = %s", d.Ident(j.Field.DBName), placeholder(d, placeholderStart+m)))
+				if positional {
+					sqlArgs = append(sqlArgs, fmt.Sprintf("after.%s", j.Field.Ident))
 				}
-				fields = append(fields, f)
 			}
-			constraints = append(constraints, modelConstraint{
-				Kind:    i.Kind,
-				Columns: fields,
-			})
+			k = append(k, fmt.Sprintf("%s %s %s", d.Ident(i.Field.DBName), condText, placeholder(d, placeholderStart+n)))
+			// numbered placeholders of preceding columns are reused, so each
+			// column is bound exactly once
+			sqlArgs = append(sqlArgs, fmt.Sprintf("after.%s", i.Field.Ident))
+			if len(k) == 1 {
+				terms = append(terms, k[0])
+			} else {
+				terms = append(terms, "("+strings.Join(k, " AND ")+")")
+			}
 		}
-		indicies := make([]modelIndexDef, 0, len(opts.Model.Indicies))
-		for _, i := range opts.Model.Indicies {
-			if i.Name == "" {
-				return nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("No name for index of struct %s", structName))
+		sqlPred = "(" + strings.Join(terms, " OR ") + ")"
+	}
+
+	sqlCond := q.genQueryCondSQL(d, sqlArgs, pageArgs)
+	if sqlCond.DBCond == "" {
+		sqlCond.DBCond = sqlPred
+	} else {
+		sqlCond.DBCond = sqlPred + " AND " + sqlCond.DBCond
+	}
+	return queryKeysetSQLStrings{
+		SQLCond: sqlCond,
+	}
+}
+
+// genQueryPatchSQL generates the update of patcheq. Update time columns are
+// always set to the current time, and the fields of the query struct for them
+// are ignored.
+func (q *queryGroupDef) genQueryPatchSQL(d Dialect) queryPatchSQLStrings {
+	updateTime := map[string]struct{}{}
+	for _, i := range q.updateTime {
+		updateTime[i.DBName] = struct{}{}
+	}
+	fields := make([]queryPatchField, 0, len(q.Fields))
+	hasOptional := false
+	for _, i := range q.Fields {
+		if _, ok := updateTime[i.DBName]; ok {
+			continue
+		}
+		if i.Optional {
+			hasOptional = true
+		}
+		fields = append(fields, queryPatchField{
+			Ident:    i.Ident,
+			Optional: i.Optional,
+			SetFmt:   d.UpdateSet([]string{d.Ident(i.DBName)}, []string{d.Placeholder()}),
+		})
+	}
+	clock := false
+	times := make([]queryPatchTime, 0, len(q.updateTime))
+	for _, i := range q.updateTime {
+		dbName := d.Ident(i.DBName)
+		if q.timeSQL {
+			times = append(times, queryPatchTime{
+				SetFmt: d.UpdateSet([]string{dbName}, []string{sqlCurrentTime}),
+			})
+			continue
+		}
+		clock = true
+		times = append(times, queryPatchTime{
+			SetFmt: d.UpdateSet([]string{dbName}, []string{d.Placeholder()}),
+			Value:  timeValue(i.GoType),
+		})
+	}
+	noop := d.Ident(q.Fields[0].DBName)
+	return queryPatchSQLStrings{
+		Fields:    fields,
+		NumFields: len(fields) + len(times),
+		// the set is never empty with an update time column
+		HasOptional: hasOptional && len(times) == 0,
+		NoopSet:     d.UpdateSet([]string{noop}, []string{noop}),
+		Clock:       clock,
+		Times:       times,
+	}
+}
+
+func (q *queryDef) genQueryReturningSQL(d Dialect) (queryReturningSQLStrings, error) {
+	if q.ReturningIdent == "" {
+		return queryReturningSQLStrings{}, nil
+	}
+	sqlDBNames := make([]string, 0, len(q.Returning))
+	sqlIdentRefs := make([]string, 0, len(q.Returning))
+	for _, i := range q.Returning {
+		sqlDBNames = append(sqlDBNames, d.Ident(i.DBName))
+		sqlIdentRefs = append(sqlIdentRefs, fmt.Sprintf("&r.%s", i.Ident))
+	}
+	returning, ok := d.Returning(sqlDBNames)
+	if !ok {
+		return queryReturningSQLStrings{}, kerrors.WithKind(nil, ErrInvalidDialect, fmt.Sprintf("Dialect %s does not support returning on %s %s", d.Name(), q.Kind, q.Name))
+	}
+	return queryReturningSQLStrings{
+		Ident:     q.ReturningIdent,
+		Returning: returning,
+		IdentRefs: strings.Join(sqlIdentRefs, ", "),
+	}, nil
+}
+
+// genQueryMapSQL generates the map of getmapin from its condition
+func (q *queryDef) genQueryMapSQL(sqlCond queryCondSQLStrings) queryMapSQLStrings {
+	// the keys are the input of the top level in condition
+	keyParam := ""
+	n := 0
+	for _, i := range q.Conds {
+		if i.Group != condGroupNone {
+			n += len(flattenQueryConds(nil, i.Conds))
+			continue
+		}
+		if i.Kind == condIn {
+			keyParam = sqlCond.condParams[n]
+			break
+		}
+		n++
+	}
+	return queryMapSQLStrings{
+		KeyIdent: q.MapKey.Ident,
+		KeyParam: keyParam,
+		KeyType:  q.MapKey.GoType,
+		Many:     q.Many,
+		Missing:  q.Missing,
+	}
+}
+
+// genQueryRawSQL generates raw sql with its substitutions, and rewrites its
+// named params into placeholders of the dialect
+func (q *queryDef) genQueryRawSQL(d Dialect, fields []queryField) queryRawSQLStrings {
+	positional := d.Positional()
+	sqlIdentParams := make([]string, 0, len(q.RawParams))
+	sqlIdentArgs := make([]string, 0, len(q.RawParams))
+	paramNums := map[string]int{}
+	for n, i := range q.RawParams {
+		sqlIdentParams = append(sqlIdentParams, fmt.Sprintf("%s %s", rawParamIdent(i.Name), i.GoType))
+		paramNums[i.Name] = n + 1
+		if !positional {
+			sqlIdentArgs = append(sqlIdentArgs, rawParamIdent(i.Name))
+		}
+	}
+	var b strings.Builder
+	for _, i := range q.Raw {
+		switch i.Kind {
+		case rawTokenTable:
+			b.WriteString(d.Ident(sqlTableName))
+		case rawTokenColumns:
+			cols := make([]string, 0, len(fields))
+			for _, j := range fields {
+				cols = append(cols, d.Ident(j.DBName))
+			}
+			b.WriteString(strings.Join(cols, ", "))
+		case rawTokenCol:
+			b.WriteString(d.Ident(i.Field.DBName))
+		case rawTokenParam:
+			b.WriteString(placeholder(d, paramNums[i.Text]))
+			// positional dialects bind a param for every occurrence
+			if positional {
+				sqlIdentArgs = append(sqlIdentArgs, rawParamIdent(i.Text))
+			}
+		default:
+			// raw sql is embedded in an interpreted go string literal
+			quoted := strconv.Quote(i.Text)
+			b.WriteString(quoted[1 : len(quoted)-1])
+		}
+	}
+	return queryRawSQLStrings{
+		SQL:         b.String(),
+		IdentParams: strings.Join(sqlIdentParams, ", "),
+		IdentArgs:   strings.Join(sqlIdentArgs, ", "),
+	}
+}
+
+// rawParamIdent returns the go identifier of a named param, which is prefixed
+// to avoid colliding with go keywords and the identifiers of the generated
+// method
+func rawParamIdent(name string) string {
+	return "v" + strings.ToUpper(name[:1]) + name[1:]
+}
+
+// genQueryUpsertSQL generates the upsert of upsert query k. Insert and update
+// time columns are inserted with the current time, including those which are
+// not fields of the query struct. Conflicting rows set their update time
+// columns, and keep their insert time columns, unless the upsert updates no
+// columns.
+func (q *queryGroupDef) genQueryUpsertSQL(d Dialect, k queryDef) queryUpsertSQLStrings {
+	insertTime := map[string]struct{}{}
+	for _, i := range q.insertTime {
+		insertTime[i.DBName] = struct{}{}
+	}
+	updateTime := map[string]struct{}{}
+	for _, i := range q.updateTime {
+		updateTime[i.DBName] = struct{}{}
+	}
+	inserted := map[string]struct{}{}
+	// time columns which are not fields of the query struct are inserted after
+	// its fields
+	var timeCols []modelField
+	for _, i := range q.Fields {
+		inserted[i.DBName] = struct{}{}
+	}
+	for _, i := range slices.Concat(q.insertTime, q.updateTime) {
+		if _, ok := inserted[i.DBName]; ok {
+			continue
+		}
+		inserted[i.DBName] = struct{}{}
+		timeCols = append(timeCols, i)
+	}
+
+	colNum := len(q.Fields) + len(timeCols)
+	sqlDBNames := make([]string, 0, colNum)
+	sqlPlaceholders := make([]string, 0, colNum)
+	sqlPlaceholderTpl := make([]string, 0, colNum)
+	sqlPlaceholderCount := make([]string, 0, colNum)
+	sqlIdents := make([]string, 0, colNum)
+	clock := false
+	var sqlNow []queryTimeAssign
+	addCol := func(dbName string, isTime bool, ident string, value string) {
+		sqlDBNames = append(sqlDBNames, d.Ident(dbName))
+		if isTime {
+			if q.timeSQL {
+				// columns set by the database are not bound
+				sqlPlaceholders = append(sqlPlaceholders, sqlCurrentTime)
+				sqlPlaceholderTpl = append(sqlPlaceholderTpl, sqlCurrentTime)
+				colNum--
+				return
+			}
+			clock = true
+		}
+		n := len(sqlIdents)
+		sqlPlaceholders = append(sqlPlaceholders, placeholder(d, 1+n))
+		sqlPlaceholderTpl = append(sqlPlaceholderTpl, d.Placeholder())
+		sqlPlaceholderCount = append(sqlPlaceholderCount, fmt.Sprintf("n+%d", 1+n))
+		if ident == "" {
+			sqlIdents = append(sqlIdents, value)
+		} else {
+			sqlIdents = append(sqlIdents, fmt.Sprintf("m.%s", ident))
+		}
+	}
+	for _, i := range q.Fields {
+		_, isInsertTime := insertTime[i.DBName]
+		_, isUpdateTime := updateTime[i.DBName]
+		isTime := isInsertTime || isUpdateTime
+		if isTime && !q.timeSQL {
+			sqlNow = append(sqlNow, queryTimeAssign{
+				Ident: i.Ident,
+				Value: timeValue(i.GoType),
+			})
+		}
+		addCol(i.DBName, isTime, i.Ident, "")
+	}
+	for _, i := range timeCols {
+		addCol(i.DBName, true, "", timeValue(i.GoType))
+	}
+
+	conflict := make([]string, 0, len(k.Conflict))
+	for _, i := range k.Conflict {
+		conflict = append(conflict, d.Ident(i.DBName))
+	}
+	update := make([]string, 0, len(k.Update)+len(q.updateTime))
+	if len(k.Update) != 0 {
+		updated := map[string]struct{}{}
+		for _, i := range k.Update {
+			updated[i.DBName] = struct{}{}
+			if _, ok := insertTime[i.DBName]; ok {
+				if _, ok := updateTime[i.DBName]; !ok {
+					// insert time columns keep the time the row was inserted
+					continue
+				}
+			}
+			update = append(update, d.Ident(i.DBName))
+		}
+		for _, i := range q.updateTime {
+			if _, ok := updated[i.DBName]; !ok {
+				update = append(update, d.Ident(i.DBName))
+			}
+		}
+	}
+	return queryUpsertSQLStrings{
+		DBNames:          strings.Join(sqlDBNames, ", "),
+		Placeholders:     strings.Join(sqlPlaceholders, ", "),
+		PlaceholderTpl:   strings.Join(sqlPlaceholderTpl, ", "),
+		PlaceholderCount: strings.Join(sqlPlaceholderCount, ", "),
+		Idents:           strings.Join(sqlIdents, ", "),
+		ColNum:           strconv.Itoa(colNum),
+		DBConflict:       d.Upsert(conflict, update),
+		Clock:            clock,
+		Now:              sqlNow,
+	}
+}
+
+func parseModelDefinitions(modelObjects []dirObjPair, modelTag string, fset *token.FileSet, schema modelSchema) ([]modelDef, error) {
+	modelDefs := make([]modelDef, 0, len(modelObjects))
+
+	for _, i := range modelObjects {
+		prefix := i.Dir.Directive
+		if prefix == "" {
+			return nil, kerrors.WithKind(nil, ErrInvalidFile, "Model directive without prefix")
+		}
+		opts := schema.Models[prefix]
+		if i.Obj.Kind != gopackages.ObjKindDeclType {
+			return nil, kerrors.WithKind(nil, ErrInvalidFile, "Model directive used on non-type declaration")
+		}
+		typeSpec, ok := i.Obj.Obj.(*ast.TypeSpec)
+		if !ok {
+			return nil, kerrors.WithMsg(nil, "Unexpected directive object type")
+		}
+		structName := typeSpec.Name.Name
+		structType, ok := typeSpec.Type.(*ast.StructType)
+		if !ok {
+			return nil, kerrors.WithKind(nil, ErrInvalidFile, "Model directive used on non-struct type declaration")
+		}
+		if structType.Incomplete {
+			return nil, kerrors.WithMsg(nil, "Unexpected incomplete struct definition")
+		}
+		astFields, err := findFields(modelTag, structType, fset)
+		if err != nil {
+			return nil, err
+		}
+		if len(astFields) == 0 {
+			return nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("No model fields found on struct: %s", structName))
+		}
+		modelFields, fieldMap, err := parseModelFields(astFields)
+		if err != nil {
+			return nil, kerrors.WithMsg(err, fmt.Sprintf("Invalid model fields for struct %s", structName))
+		}
+		constraints := make([]modelConstraint, 0, len(opts.Model.Constraints))
+		for _, i := range opts.Model.Constraints {
+			if i.Kind == "" {
+				return nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Missing constraint kind for struct %s", structName))
+			}
+			if len(i.Columns) == 0 {
+				return nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("No columns for constraint of struct %s", structName))
+			}
+			fields := make([]modelField, 0, len(i.Columns))
+			for _, j := range i.Columns {
+				f, ok := fieldMap[j]
+				if !ok {
+					return nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Unknown field %s for constraint of struct %s", j, structName))
+				}
+				fields = append(fields, f)
+			}
+			constraints = append(constraints, modelConstraint{
+				Kind:    i.Kind,
+				Columns: fields,
+			})
+		}
+		indicies := make([]modelIndexDef, 0, len(opts.Model.Indicies))
+		for _, i := range opts.Model.Indicies {
+			if i.Name == "" {
+				return nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("No name for index of struct %s", structName))
+			}
+			if len(i.Columns) == 0 {
+				return nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("No columns for index of struct %s", structName))
+			}
+			columns := make([]modelIndexColumn, 0, len(i.Columns))
+			for _, j := range i.Columns {
+				f, ok := fieldMap[j.Col]
+				if !ok {
+					return nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Unknown field %s for index of struct %s", j, structName))
+				}
+				columns = append(columns, modelIndexColumn{
+					Field: f,
+					Dir:   j.Dir,
+				})
+			}
+			indicies = append(indicies, modelIndexDef{
+				Name:    i.Name,
+				Columns: columns,
+			})
+		}
+		returning := make([]modelField, 0, len(opts.Model.Returning))
+		for _, i := range opts.Model.Returning {
+			f, ok := fieldMap[i]
+			if !ok {
+				return nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Unknown returning field %s of struct %s", i, structName))
+			}
+			returning = append(returning, f)
+		}
+		if len(returning) == len(modelFields) {
+			return nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Returning fields include all fields of struct %s", structName))
+		}
+		var softDel *modelField
+		if opts.Model.SoftDelete != "" {
+			f, ok := fieldMap[opts.Model.SoftDelete]
+			if !ok {
+				return nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Unknown soft delete field %s of struct %s", opts.Model.SoftDelete, structName))
+			}
+			if softDelStamp(f) == "" {
+				return nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Soft delete field %s of struct %s must be *time.Time or *int64", opts.Model.SoftDelete, structName))
+			}
+			softDel = &f
+		}
+		var version *modelField
+		if opts.Model.Version != "" {
+			f, ok := fieldMap[opts.Model.Version]
+			if !ok {
+				return nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Unknown version field %s of struct %s", opts.Model.Version, structName))
+			}
+			if f.GoType != "int" && f.GoType != "int64" {
+				return nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Version field %s of struct %s must be int or int64", opts.Model.Version, structName))
+			}
+			version = &f
+		}
+		timeSQL := false
+		switch opts.Model.AutoTime.Source {
+		case "", "clock":
+		case "sql":
+			timeSQL = true
+		default:
+			return nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Invalid auto time source %s of struct %s", opts.Model.AutoTime.Source, structName))
+		}
+		// auto time columns may not be managed by other model options
+		managedCols := map[string]struct{}{}
+		for _, i := range returning {
+			managedCols[i.DBName] = struct{}{}
+		}
+		if softDel != nil {
+			managedCols[softDel.DBName] = struct{}{}
+		}
+		if version != nil {
+			managedCols[version.DBName] = struct{}{}
+		}
+		insertTime, err := parseAutoTimeFields(opts.Model.AutoTime.Insert, fieldMap, managedCols, timeSQL)
+		if err != nil {
+			return nil, kerrors.WithMsg(err, fmt.Sprintf("Invalid auto insert time of struct %s", structName))
+		}
+		updateTime, err := parseAutoTimeFields(opts.Model.AutoTime.Update, fieldMap, managedCols, timeSQL)
+		if err != nil {
+			return nil, kerrors.WithMsg(err, fmt.Sprintf("Invalid auto update time of struct %s", structName))
+		}
+		modelDefs = append(modelDefs, modelDef{
+			Prefix:      prefix,
+			Ident:       structName,
+			Fields:      modelFields,
+			Constraints: constraints,
+			Indicies:    indicies,
+			Returning:   returning,
+			SoftDel:     softDel,
+			Version:     version,
+			InsertTime:  insertTime,
+			UpdateTime:  updateTime,
+			TimeSQL:     timeSQL,
+			opts:        opts.Model,
+			fieldMap:    fieldMap,
+		})
+	}
+
+	return modelDefs, nil
+}
+
+// softDelStamp returns the expression of the value stamped on the soft delete
+// column of a soft deleted row, or the empty string if the type of the column
+// is not supported
+func softDelStamp(f modelField) string {
+	switch f.GoType {
+	case "*time.Time":
+		return "t.now()"
+	case "*int64":
+		return "t.now().Unix()"
+	default:
+		return ""
+	}
+}
+
+// parseAutoTimeFields parses the columns set to the current time by inserts or
+// updates. Columns set by the database must be time.Time, and columns set from
+// the clock of the model table may also be int64 unix seconds.
+func parseAutoTimeFields(cols []string, fieldMap map[string]modelField, managedCols map[string]struct{}, timeSQL bool) ([]modelField, error) {
+	fields := make([]modelField, 0, len(cols))
+	seen := map[string]struct{}{}
+	for _, i := range cols {
+		f, ok := fieldMap[i]
+		if !ok {
+			return nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Unknown auto time field %s", i))
+		}
+		if _, ok := seen[i]; ok {
+			return nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Duplicate auto time field %s", i))
+		}
+		seen[i] = struct{}{}
+		if _, ok := managedCols[i]; ok {
+			return nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Auto time field %s may not be returning, soft delete, or version", i))
+		}
+		if f.GoType != "time.Time" && (timeSQL || f.GoType != "int64") {
+			return nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Invalid type %s of auto time field %s", f.GoType, i))
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// timeValue returns the expression of the current time as the go type of an
+// auto time field
+func timeValue(goType string) string {
+	if goType == "int64" {
+		return "now.Unix()"
+	}
+	return "now"
+}
+
+// hasClock returns true if the model table requires a clock
+func (m *modelDef) hasClock() bool {
+	if m.SoftDel != nil {
+		return true
+	}
+	return !m.TimeSQL && (len(m.InsertTime) != 0 || len(m.UpdateTime) != 0)
+}
+
+func parseModelFields(astfields []astField) ([]modelField, map[string]modelField, error) {
+	fields := make([]modelField, 0, len(astfields))
+	seenFields := map[string]modelField{}
+	for n, i := range astfields {
+		dbName, dbType, ok := strings.Cut(i.Tags, ",")
+		if !ok || dbName == "" || dbType == "" {
+			return nil, nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Model field tag must be dbname,dbtype on field %s", i.Ident))
+		}
+		if dup, ok := seenFields[dbName]; ok {
+			return nil, nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Duplicate field %s on %s and %s", dbName, i.Ident, dup.Ident))
+		}
+		f := modelField{
+			Ident:  i.Ident,
+			GoType: i.GoType,
+			DBName: dbName,
+			DBType: dbType,
+			Num:    n + 1,
+		}
+		seenFields[dbName] = f
+		fields = append(fields, f)
+	}
+	return fields, seenFields, nil
+}
+
+func parseQueryDefinitions(queryObjects []dirObjPair, modelTag string, modelDefs map[string]modelDef, fset *token.FileSet, schema modelSchema) (map[string][]queryGroupDef, error) {
+	queryGroupDefs := map[string][]queryGroupDef{}
+
+	for _, i := range queryObjects {
+		prefix := i.Dir.Directive
+		if prefix == "" {
+			return nil, kerrors.WithKind(nil, ErrInvalidFile, "Query directive without prefix")
+		}
+		mdef, ok := modelDefs[prefix]
+		if !ok {
+			return nil, kerrors.WithKind(nil, ErrInvalidFile, fmt.Sprintf("Query directive prefix %s without model definition", prefix))
+		}
+		if i.Obj.Kind != gopackages.ObjKindDeclType {
+			return nil, kerrors.WithKind(nil, ErrInvalidFile, "Query directive used on non-type declaration")
+		}
+		typeSpec, ok := i.Obj.Obj.(*ast.TypeSpec)
+		if !ok {
+			return nil, kerrors.WithMsg(nil, "Unexpected directive object type")
+		}
+		structName := typeSpec.Name.Name
+		structType, ok := typeSpec.Type.(*ast.StructType)
+		if !ok {
+			return nil, kerrors.WithKind(nil, ErrInvalidFile, "Query directive used on non-struct type declaration")
+		}
+		if structType.Incomplete {
+			return nil, kerrors.WithMsg(nil, "Unexpected incomplete struct definition")
+		}
+		astFields, err := findFields(modelTag, structType, fset)
+		if err != nil {
+			return nil, err
+		}
+		if len(astFields) == 0 {
+			return nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("No query fields found on struct: %s", structName))
+		}
+		fields, err := parseQueryFields(astFields, mdef.fieldMap)
+		if err != nil {
+			return nil, kerrors.WithMsg(err, fmt.Sprintf("Invalid query fields for struct %s", structName))
+		}
+		queryFieldMap := map[string]queryField{}
+		hasOptional := false
+		for _, j := range fields {
+			queryFieldMap[j.DBName] = j
+			if j.Optional {
+				hasOptional = true
+			}
+		}
+		var version *queryField
+		if mdef.Version != nil {
+			if f, ok := queryFieldMap[mdef.Version.DBName]; ok {
+				version = &f
+			}
+		}
+		opts := schema.Models[prefix].Queries[structName]
+		queries := make([]queryDef, 0, len(opts))
+		for _, j := range opts {
+			def, err := parseQueryDef(j, structName, fields, queryFieldMap, mdef.fieldMap, hasOptional)
+			if err != nil {
+				return nil, err
+			}
+			if def.Kind == queryKindUpdEq && mdef.Version != nil {
+				// updates of a versioned model check and increment the version
+				if version == nil {
+					return nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Query struct %s of %s %s must have version field %s", structName, def.Kind, def.Name, mdef.Version.DBName))
+				}
+				if def.ReturningIdent != "" || def.Affected != "" {
+					return nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Versioned %s %s on struct %s may not have returning or affected", def.Kind, def.Name, structName))
+				}
+			}
+			if def.Kind == queryKindIncrEq {
+				if err := checkQueryIncrFields(def, structName, fields, mdef.UpdateTime); err != nil {
+					return nil, err
+				}
+			}
+			if (def.Kind == queryKindIncrEq || def.Kind == queryKindPatchEq || (def.Kind == queryKindUpsert && len(def.Update) != 0)) && mdef.Version != nil {
+				// increq, patcheq, and upsert with update columns would update the
+				// row without checking the version
+				return nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Query kind %s %s on struct %s may not update versioned model %s", def.Kind, def.Name, structName, prefix))
+			}
+			queries = append(queries, def)
+		}
+		queryGroupDefs[prefix] = append(queryGroupDefs[prefix], queryGroupDef{
+			Ident:      structName,
+			Fields:     fields,
+			Queries:    queries,
+			softDel:    mdef.SoftDel,
+			version:    version,
+			insertTime: mdef.InsertTime,
+			updateTime: mdef.UpdateTime,
+			timeSQL:    mdef.TimeSQL,
+		})
+	}
+
+	for _, groups := range queryGroupDefs {
+		groupMap := map[string]queryGroupDef{}
+		for _, i := range groups {
+			groupMap[i.Ident] = i
+		}
+		returningTargets := map[string]struct{}{}
+		for _, i := range groups {
+			for n, j := range i.Queries {
+				if j.ReturningIdent == "" {
+					continue
+				}
+				target, ok := groupMap[j.ReturningIdent]
+				if !ok {
+					return nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Unknown returning query struct %s for %s %s on struct %s", j.ReturningIdent, j.Kind, j.Name, i.Ident))
+				}
+				for _, k := range target.Fields {
+					if k.Agg != "" {
+						return nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Returning query struct %s for %s %s on struct %s may not have aggregate fields", j.ReturningIdent, j.Kind, j.Name, i.Ident))
+					}
+				}
+				i.Queries[n].Returning = target.Fields
+				returningTargets[target.Ident] = struct{}{}
+			}
+		}
+		for _, i := range groups {
+			if len(i.Queries) != 0 {
+				continue
+			}
+			// query structs used only as returning targets do not require queries
+			if _, ok := returningTargets[i.Ident]; !ok {
+				return nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Query struct %s missing queries", i.Ident))
+			}
+		}
+	}
+
+	return queryGroupDefs, nil
+}
+
+func parseJoinDefinitions(joinObjects []dirObjPair, modelTag string, modelDefs map[string]modelDef, fset *token.FileSet, schema modelSchema) ([]joinDef, error) {
+	var joinDefs []joinDef
+	joinSet := map[string]struct{}{}
+
+	for _, i := range joinObjects {
+		prefix := i.Dir.Directive
+		if prefix == "" {
+			return nil, kerrors.WithKind(nil, ErrInvalidFile, "Join directive without prefix")
+		}
+		if _, ok := modelDefs[prefix]; ok {
+			return nil, kerrors.WithKind(nil, ErrInvalidFile, fmt.Sprintf("Join directive prefix %s is also a model prefix", prefix))
+		}
+		if _, ok := joinSet[prefix]; ok {
+			return nil, kerrors.WithKind(nil, ErrInvalidFile, fmt.Sprintf("Duplicate join directive prefix %s", prefix))
+		}
+		joinSet[prefix] = struct{}{}
+		if i.Obj.Kind != gopackages.ObjKindDeclType {
+			return nil, kerrors.WithKind(nil, ErrInvalidFile, "Join directive used on non-type declaration")
+		}
+		typeSpec, ok := i.Obj.Obj.(*ast.TypeSpec)
+		if !ok {
+			return nil, kerrors.WithMsg(nil, "Unexpected directive object type")
+		}
+		structName := typeSpec.Name.Name
+		structType, ok := typeSpec.Type.(*ast.StructType)
+		if !ok {
+			return nil, kerrors.WithKind(nil, ErrInvalidFile, "Join directive used on non-struct type declaration")
+		}
+		if structType.Incomplete {
+			return nil, kerrors.WithMsg(nil, "Unexpected incomplete struct definition")
+		}
+		opts, ok := schema.Joins[prefix]
+		if !ok {
+			return nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Join %s missing schema for struct %s", prefix, structName))
+		}
+		tables, fieldMap, err := parseJoinTables(opts, modelDefs)
+		if err != nil {
+			return nil, kerrors.WithMsg(err, fmt.Sprintf("Invalid join %s for struct %s", prefix, structName))
+		}
+		astFields, err := findFields(modelTag, structType, fset)
+		if err != nil {
+			return nil, err
+		}
+		if len(astFields) == 0 {
+			return nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("No query fields found on struct: %s", structName))
+		}
+		// pointer fields of a join denote columns which may be null, e.g. of a
+		// left join, rather than optional fields
+		fields, err := parseQueryFields(astFields, fieldMap)
+		if err != nil {
+			return nil, kerrors.WithMsg(err, fmt.Sprintf("Invalid query fields for struct %s", structName))
+		}
+		if len(opts.Queries) == 0 {
+			return nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Join struct %s missing queries", structName))
+		}
+		queries := make([]queryDef, 0, len(opts.Queries))
+		for _, j := range opts.Queries {
+			kind, err := parseQueryKind(j.Kind)
+			if err != nil {
+				return nil, kerrors.WithMsg(err, fmt.Sprintf("Invalid query kind for %s on struct %s", j.Name, structName))
+			}
+			switch kind {
+			case queryKindGetOneEq, queryKindGetGroup, queryKindGetGroupEq, queryKindCount, queryKindCountEq, queryKindExistsEq, queryKindAggregate:
+			default:
+				return nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Query kind %s is not supported by join on %s of struct %s", j.Kind, j.Name, structName))
+			}
+			// query kinds of a join do not reference fields of the query struct
+			def, err := parseQueryDef(j, structName, fields, nil, fieldMap, false)
+			if err != nil {
+				return nil, err
+			}
+			queries = append(queries, def)
+		}
+		joinDefs = append(joinDefs, joinDef{
+			Prefix: prefix,
+			Tables: tables,
+			Group: queryGroupDef{
+				Ident:   structName,
+				Fields:  fields,
+				Queries: queries,
+				join:    tables,
+				softDel: tables[0].SoftDel,
+			},
+		})
+	}
+
+	return joinDefs, nil
+}
+
+// parseJoinTables parses the joined tables of a join. The returned field map
+// has the fields of all joined models by prefix.column.
+func parseJoinTables(opts joinConfig, modelDefs map[string]modelDef) ([]joinTableDef, map[string]modelField, error) {
+	if len(opts.Join) == 0 {
+		return nil, nil, kerrors.WithKind(nil, ErrInvalidModel, "Join missing joined models")
+	}
+	tables := make([]joinTableDef, 0, 1+len(opts.Join))
+	fieldMap := map[string]modelField{}
+	addTable := func(prefix string, kind string) error {
+		mdef, ok := modelDefs[prefix]
+		if !ok {
+			return kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Unknown join model %s", prefix))
+		}
+		for _, i := range tables {
+			if i.Prefix == prefix {
+				return kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Duplicate join model %s", prefix))
+			}
+		}
+		alias := fmt.Sprintf("t%d", len(tables)+1)
+		for _, i := range mdef.Fields {
+			i.Alias = alias
+			i.Model = prefix
+			fieldMap[prefix+"."+i.DBName] = i
+		}
+		var softDel *modelField
+		if mdef.SoftDel != nil {
+			f := *mdef.SoftDel
+			f.Alias = alias
+			softDel = &f
+		}
+		tables = append(tables, joinTableDef{
+			Prefix:  prefix,
+			Alias:   alias,
+			Kind:    kind,
+			SoftDel: softDel,
+		})
+		return nil
+	}
+	if err := addTable(opts.From, ""); err != nil {
+		return nil, nil, err
+	}
+	for _, i := range opts.Join {
+		var kind string
+		switch i.Kind {
+		case "", "inner":
+			kind = "INNER JOIN"
+		case "left":
+			kind = "LEFT JOIN"
+		default:
+			return nil, nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Invalid join kind %s for model %s", i.Kind, i.Model))
+		}
+		if err := addTable(i.Model, kind); err != nil {
+			return nil, nil, err
+		}
+		if len(i.On) == 0 {
+			return nil, nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Join missing on fields for model %s", i.Model))
+		}
+		on := make([]joinOnField, 0, len(i.On))
+		for _, j := range i.On {
+			// col is a field of the joined model, and ref is a field of a model
+			// joined before it
+			colPrefix, _, _ := strings.Cut(j.Col, ".")
+			col, ok := fieldMap[j.Col]
+			if !ok || colPrefix != i.Model {
+				return nil, nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Invalid join on field %s for model %s", j.Col, i.Model))
+			}
+			refPrefix, _, _ := strings.Cut(j.Ref, ".")
+			ref, ok := fieldMap[j.Ref]
+			if !ok || refPrefix == i.Model {
+				return nil, nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Invalid join on ref field %s for model %s", j.Ref, i.Model))
+			}
+			on = append(on, joinOnField{
+				Col: col,
+				Ref: ref,
+			})
+		}
+		tables[len(tables)-1].On = on
+	}
+	return tables, fieldMap, nil
+}
+
+// checkQueryIncrFields returns an error if a field incremented by increq is
+// not numeric or is a column of its conditions. Update time columns are set
+// rather than incremented.
+func checkQueryIncrFields(def queryDef, structName string, fields []queryField, updateTime []modelField) error {
+	condCols := map[string]struct{}{}
+	for _, i := range flattenQueryConds(nil, def.Conds) {
+		condCols[i.Field.DBName] = struct{}{}
+	}
+	for _, i := range fields {
+		if slices.ContainsFunc(updateTime, func(f modelField) bool { return f.DBName == i.DBName }) {
+			continue
+		}
+		if !isNumericGoType(i.GoType) {
+			return kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Increment field %s with type %s of %s %s on struct %s is not numeric", i.DBName, i.GoType, def.Kind, def.Name, structName))
+		}
+		if _, ok := condCols[i.DBName]; ok {
+			return kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Increment field %s of %s %s on struct %s may not be a condition", i.DBName, def.Kind, def.Name, structName))
+		}
+	}
+	return nil
+}
+
+// parseQueryDef parses a query of a query struct. fieldMap is the fields on
+// which the query may have conditions and orders.
+func parseQueryDef(j queryOpts, structName string, fields []queryField, queryFieldMap map[string]queryField, fieldMap map[string]modelField, hasOptional bool) (queryDef, error) {
+	kind, err := parseQueryKind(j.Kind)
+	if err != nil {
+		return queryDef{}, kerrors.WithMsg(err, fmt.Sprintf("Invalid query kind for %s on struct %s", j.Name, structName))
+	}
+	if j.Name == "" {
+		return queryDef{}, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Query name missing for kind %s on struct %s", j.Kind, structName))
+	}
+	def := queryDef{
+		Kind: kind,
+		Name: j.Name,
+	}
+	if hasOptional && kind != queryKindPatchEq {
+		return queryDef{}, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Query kind %s does not take optional fields on %s of struct %s", j.Kind, j.Name, structName))
+	}
+	hasAgg := false
+	for _, i := range fields {
+		if i.Agg != "" {
+			hasAgg = true
+			break
+		}
+	}
+	if kind == queryKindAggregate {
+		if !hasAgg {
+			return queryDef{}, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Query missing aggregate fields for %s %s on struct %s", j.Kind, j.Name, structName))
+		}
+	} else if hasAgg {
+		return queryDef{}, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Query kind %s does not take aggregate fields on %s of struct %s", j.Kind, j.Name, structName))
+	}
+	switch kind {
+	case queryKindGetGroup, queryKindGetGroupEq, queryKindGetGroupKeyset:
+		def.Stream = j.Stream
+	default:
+		if j.Stream {
+			return queryDef{}, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Query kind %s does not take stream on %s of struct %s", j.Kind, j.Name, structName))
+		}
+	}
+	switch kind {
+	case queryKindGetOneEq, queryKindGetGroupEq, queryKindUpdEq, queryKindIncrEq, queryKindPatchEq, queryKindDelEq, queryKindCountEq, queryKindExistsEq, queryKindGetMapIn:
+		{
+			if len(j.Conditions) == 0 {
+				return queryDef{}, kerrors.WithKind(err, ErrInvalidModel, fmt.Sprintf("Query missing condition fields for %s %s on struct %s", j.Kind, j.Name, structName))
+			}
+			k, err := parseQueryConds(j.Conditions, fieldMap)
+			if err != nil {
+				return queryDef{}, kerrors.WithMsg(err, fmt.Sprintf("Invalid conditions for %s %s on struct %s", j.Kind, j.Name, structName))
+			}
+			def.Conds = k
+		}
+	case queryKindGetGroupKeyset, queryKindAggregate:
+		{
+			k, err := parseQueryConds(j.Conditions, fieldMap)
+			if err != nil {
+				return queryDef{}, kerrors.WithMsg(err, fmt.Sprintf("Invalid conditions for %s %s on struct %s", j.Kind, j.Name, structName))
+			}
+			def.Conds = k
+		}
+	default:
+		if len(j.Conditions) != 0 {
+			return queryDef{}, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Query kind %s does not take conditions on %s of struct %s", j.Kind, j.Name, structName))
+		}
+	}
+	switch kind {
+	case queryKindGetGroup, queryKindGetGroupEq:
+		{
+			k, err := parseQueryOrder(j.Order, fieldMap)
+			if err != nil {
+				return queryDef{}, kerrors.WithMsg(err, fmt.Sprintf("Invalid order for %s %s on struct %s", j.Kind, j.Name, structName))
+			}
+			def.Order = k
+		}
+		if len(j.Sort) != 0 {
+			if len(j.Order) != 0 {
+				return queryDef{}, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Query may not take both order and sort on %s %s of struct %s", j.Kind, j.Name, structName))
+			}
+			sorts := make([]querySortDef, 0, len(j.Sort))
+			sortSet := map[string]struct{}{}
+			for _, c := range j.Sort {
+				if c.Name == "" {
+					return queryDef{}, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Sort name missing for %s %s on struct %s", j.Kind, j.Name, structName))
+				}
+				if _, ok := sortSet[c.Name]; ok {
+					return queryDef{}, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Duplicate sort %s for %s %s on struct %s", c.Name, j.Kind, j.Name, structName))
+				}
+				sortSet[c.Name] = struct{}{}
+				if len(c.Order) == 0 {
+					return queryDef{}, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Sort %s missing order fields for %s %s on struct %s", c.Name, j.Kind, j.Name, structName))
+				}
+				k, err := parseQueryOrder(c.Order, fieldMap)
+				if err != nil {
+					return queryDef{}, kerrors.WithMsg(err, fmt.Sprintf("Invalid sort %s for %s %s on struct %s", c.Name, j.Kind, j.Name, structName))
+				}
+				sorts = append(sorts, querySortDef{
+					Name:  c.Name,
+					Order: k,
+				})
 			}
-			if len(i.Columns) == 0 {
-				return nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("No columns for index of struct %s", structName))
+			def.Sort = sorts
+		}
+	case queryKindGetGroupKeyset:
+		{
+			if len(j.Order) == 0 {
+				return queryDef{}, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Query missing order fields for %s %s on struct %s", j.Kind, j.Name, structName))
 			}
-			columns := make([]modelIndexColumn, 0, len(i.Columns))
-			for _, j := range i.Columns {
-				f, ok := fieldMap[j.Col]
+			k := make([]queryOrderField, 0, len(j.Order))
+			keyset := make([]queryKeysetField, 0, len(j.Order))
+			for _, c := range j.Order {
+				field, ok := fieldMap[c.Col]
 				if !ok {
-					return nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Unknown field %s for index of struct %s", j, structName))
+					return queryDef{}, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Unknown order field %s for %s %s on struct %s", c.Col, j.Kind, j.Name, structName))
 				}
-				columns = append(columns, modelIndexColumn{
-					Field: f,
-					Dir:   j.Dir,
+				qfield, ok := queryFieldMap[c.Col]
+				if !ok {
+					return queryDef{}, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Keyset order field %s for %s %s is not a field of struct %s", c.Col, j.Kind, j.Name, structName))
+				}
+				var desc bool
+				switch strings.ToUpper(c.Dir) {
+				case "", "ASC":
+					desc = false
+				case "DESC":
+					desc = true
+				default:
+					return queryDef{}, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Invalid keyset order dir %s for field %s on %s %s of struct %s", c.Dir, c.Col, j.Kind, j.Name, structName))
+				}
+				k = append(k, queryOrderField{
+					Field: field,
+					Dir:   c.Dir,
+				})
+				keyset = append(keyset, queryKeysetField{
+					Field: qfield,
+					Desc:  desc,
 				})
 			}
-			indicies = append(indicies, modelIndexDef{
-				Name:    i.Name,
-				Columns: columns,
-			})
+			def.Order = k
+			def.Keyset = keyset
 		}
-		modelDefs = append(modelDefs, modelDef{
-			Prefix:      prefix,
-			Ident:       structName,
-			Fields:      modelFields,
-			Constraints: constraints,
-			Indicies:    indicies,
-			opts:        opts.Model,
-			fieldMap:    fieldMap,
-		})
-	}
-
-	return modelDefs, nil
-}
-
-func parseModelFields(astfields []astField) ([]modelField, map[string]modelField, error) {
-	fields := make([]modelField, 0, len(astfields))
-	seenFields := map[string]modelField{}
-	for n, i := range astfields {
-		dbName, dbType, ok := strings.Cut(i.Tags, ",")
-		if !ok || dbName == "" || dbType == "" {
-			return nil, nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Model field tag must be dbname,dbtype on field %s", i.Ident))
+	case queryKindAggregate:
+		{
+			// having and order may also refer to aggregate fields by their
+			// aggregate expressions
+			aggMap := aggFieldMap(fieldMap, fields)
+			k, err := parseQueryOrder(j.Order, aggMap)
+			if err != nil {
+				return queryDef{}, kerrors.WithMsg(err, fmt.Sprintf("Invalid order for %s %s on struct %s", j.Kind, j.Name, structName))
+			}
+			def.Order = k
+			having, err := parseQueryConds(j.Having, aggMap)
+			if err != nil {
+				return queryDef{}, kerrors.WithMsg(err, fmt.Sprintf("Invalid having conditions for %s %s on struct %s", j.Kind, j.Name, structName))
+			}
+			def.Having = having
 		}
-		if dup, ok := seenFields[dbName]; ok {
-			return nil, nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Duplicate field %s on %s and %s", dbName, i.Ident, dup.Ident))
+	case queryKindGetMapIn:
+		{
+			k, err := parseQueryOrder(j.Order, fieldMap)
+			if err != nil {
+				return queryDef{}, kerrors.WithMsg(err, fmt.Sprintf("Invalid order for %s %s on struct %s", j.Kind, j.Name, structName))
+			}
+			def.Order = k
 		}
-		f := modelField{
-			Ident:  i.Ident,
-			GoType: i.GoType,
-			DBName: dbName,
-			DBType: dbType,
-			Num:    n + 1,
+	default:
+		if len(j.Order) != 0 {
+			return queryDef{}, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Query kind %s does not take order on %s of struct %s", j.Kind, j.Name, structName))
 		}
-		seenFields[dbName] = f
-		fields = append(fields, f)
 	}
-	return fields, seenFields, nil
-}
-
-func parseQueryDefinitions(queryObjects []dirObjPair, modelTag string, modelDefs map[string]modelDef, fset *token.FileSet, schema modelSchema) (map[string][]queryGroupDef, error) {
-	queryGroupDefs := map[string][]queryGroupDef{}
-
-	for _, i := range queryObjects {
-		prefix := i.Dir.Directive
-		if prefix == "" {
-			return nil, kerrors.WithKind(nil, ErrInvalidFile, "Query directive without prefix")
+	if kind != queryKindAggregate && len(j.Having) != 0 {
+		return queryDef{}, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Query kind %s does not take having on %s of struct %s", j.Kind, j.Name, structName))
+	}
+	if j.Lock != nil {
+		switch kind {
+		case queryKindGetOneEq, queryKindGetGroup, queryKindGetGroupEq:
+		default:
+			return queryDef{}, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Query kind %s does not take lock on %s of struct %s", j.Kind, j.Name, structName))
 		}
-		mdef, ok := modelDefs[prefix]
-		if !ok {
-			return nil, kerrors.WithKind(nil, ErrInvalidFile, fmt.Sprintf("Query directive prefix %s without model definition", prefix))
+		switch j.Lock.Mode {
+		case "update":
+			def.LockMode = LockModeUpdate
+		case "share":
+			def.LockMode = LockModeShare
+		default:
+			return queryDef{}, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Invalid lock mode %s on %s %s of struct %s", j.Lock.Mode, j.Kind, j.Name, structName))
 		}
-		if i.Obj.Kind != gopackages.ObjKindDeclType {
-			return nil, kerrors.WithKind(nil, ErrInvalidFile, "Query directive used on non-type declaration")
+		switch j.Lock.Wait {
+		case "":
+			def.LockWait = LockWaitDefault
+		case "nowait":
+			def.LockWait = LockWaitNoWait
+		case "skiplocked":
+			def.LockWait = LockWaitSkipLocked
+		default:
+			return queryDef{}, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Invalid lock wait %s on %s %s of struct %s", j.Lock.Wait, j.Kind, j.Name, structName))
 		}
-		typeSpec, ok := i.Obj.Obj.(*ast.TypeSpec)
-		if !ok {
-			return nil, kerrors.WithMsg(nil, "Unexpected directive object type")
+	}
+	if kind == queryKindGetMapIn {
+		var keyCond *queryCondField
+		for n, c := range def.Conds {
+			if c.Group != condGroupNone || c.Kind != condIn {
+				continue
+			}
+			if keyCond != nil {
+				return queryDef{}, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Query may only have one in condition for %s %s on struct %s", j.Kind, j.Name, structName))
+			}
+			keyCond = &def.Conds[n]
 		}
-		structName := typeSpec.Name.Name
-		structType, ok := typeSpec.Type.(*ast.StructType)
+		if keyCond == nil {
+			return queryDef{}, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Query missing in condition for %s %s on struct %s", j.Kind, j.Name, structName))
+		}
+		key, ok := queryFieldMap[keyCond.Field.DBName]
 		if !ok {
-			return nil, kerrors.WithKind(nil, ErrInvalidFile, "Query directive used on non-struct type declaration")
+			return queryDef{}, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Map key field %s for %s %s is not a field of struct %s", keyCond.Field.DBName, j.Kind, j.Name, structName))
 		}
-		if structType.Incomplete {
-			return nil, kerrors.WithMsg(nil, "Unexpected incomplete struct definition")
+		if strings.HasPrefix(key.GoType, "[]") || strings.HasPrefix(key.GoType, "map[") {
+			return queryDef{}, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Map key field %s for %s %s of struct %s is not comparable", keyCond.Field.DBName, j.Kind, j.Name, structName))
 		}
-		astFields, err := findFields(modelTag, structType, fset)
-		if err != nil {
-			return nil, err
+		def.MapKey = key
+		def.Many = j.Many
+		def.Missing = j.Missing
+	} else if j.Many || j.Missing {
+		return queryDef{}, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Query kind %s does not take many or missing on %s of struct %s", j.Kind, j.Name, structName))
+	}
+	if kind == queryKindRaw {
+		if j.SQL == "" {
+			return queryDef{}, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Query missing sql for %s %s on struct %s", j.Kind, j.Name, structName))
 		}
-		if len(astFields) == 0 {
-			return nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("No query fields found on struct: %s", structName))
+		paramCols := map[string]string{}
+		for _, c := range j.Params {
+			if !isRawParamName(c.Name) {
+				return queryDef{}, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Invalid param name %s for %s %s on struct %s", c.Name, j.Kind, j.Name, structName))
+			}
+			if _, ok := paramCols[c.Name]; ok {
+				return queryDef{}, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Duplicate param %s for %s %s on struct %s", c.Name, j.Kind, j.Name, structName))
+			}
+			if _, ok := fieldMap[c.Col]; !ok {
+				return queryDef{}, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Unknown param field %s of param %s for %s %s on struct %s", c.Col, c.Name, j.Kind, j.Name, structName))
+			}
+			paramCols[c.Name] = c.Col
 		}
-		fields, err := parseQueryFields(astFields, mdef.fieldMap)
+		tokens, params, err := parseRawSQL(j.SQL, fieldMap, paramCols)
 		if err != nil {
-			return nil, kerrors.WithMsg(err, fmt.Sprintf("Invalid query fields for struct %s", structName))
-		}
-		opts := schema.Models[prefix].Queries[structName]
-		if len(opts) == 0 {
-			return nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Query struct %s missing queries", structName))
+			return queryDef{}, kerrors.WithMsg(err, fmt.Sprintf("Invalid sql for %s %s on struct %s", j.Kind, j.Name, structName))
 		}
-		queries := make([]queryDef, 0, len(opts))
-		for _, j := range opts {
-			kind, err := parseQueryKind(j.Kind)
-			if err != nil {
-				return nil, kerrors.WithMsg(err, fmt.Sprintf("Invalid query kind for %s on struct %s", j.Name, structName))
+		for _, c := range j.Params {
+			if !slices.ContainsFunc(params, func(p queryRawParam) bool { return p.Name == c.Name }) {
+				return queryDef{}, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Unused param %s for %s %s on struct %s", c.Name, j.Kind, j.Name, structName))
 			}
-			if j.Name == "" {
-				return nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Query name missing for kind %s on struct %s", j.Kind, structName))
+		}
+		def.Raw = tokens
+		def.RawParams = params
+	} else if j.SQL != "" || j.Params != nil {
+		return queryDef{}, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Query kind %s does not take sql or params on %s of struct %s", j.Kind, j.Name, structName))
+	}
+	switch kind {
+	case queryKindGetGroup, queryKindGetGroupEq:
+	default:
+		if len(j.Sort) != 0 {
+			return queryDef{}, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Query kind %s does not take sort on %s of struct %s", j.Kind, j.Name, structName))
+		}
+	}
+	switch kind {
+	case queryKindUpsert:
+		{
+			if len(j.Conflict) == 0 {
+				return queryDef{}, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Query missing conflict fields for %s %s on struct %s", j.Kind, j.Name, structName))
 			}
-			def := queryDef{
-				Kind: kind,
-				Name: j.Name,
+			conflict := make([]queryField, 0, len(j.Conflict))
+			conflictSet := map[string]struct{}{}
+			for _, c := range j.Conflict {
+				field, ok := queryFieldMap[c]
+				if !ok {
+					return queryDef{}, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Unknown conflict field %s for %s %s on struct %s", c, j.Kind, j.Name, structName))
+				}
+				conflict = append(conflict, field)
+				conflictSet[c] = struct{}{}
 			}
-			switch kind {
-			case queryKindGetOneEq, queryKindGetGroupEq, queryKindUpdEq, queryKindDelEq:
-				{
-					if len(j.Conditions) == 0 {
-						return nil, kerrors.WithKind(err, ErrInvalidModel, fmt.Sprintf("Query missing condition fields for %s %s on struct %s", j.Kind, j.Name, structName))
+			var update []queryField
+			if j.Update == nil {
+				// update all fields which are not conflict targets by default
+				for _, c := range fields {
+					if _, ok := conflictSet[c.DBName]; !ok {
+						update = append(update, c)
 					}
-					k := make([]queryCondField, 0, len(j.Conditions))
-					for _, c := range j.Conditions {
-						field, ok := mdef.fieldMap[c.Col]
-						if !ok {
-							return nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Unknown condition field %s for %s %s on struct %s", c.Col, j.Kind, j.Name, structName))
-						}
-						cond, err := parseCond(c.Cond)
-						if err != nil {
-							return nil, kerrors.WithMsg(err, fmt.Sprintf("Invalid condition for field %s on query %s %s of struct %s", c.Col, j.Kind, j.Name, structName))
-						}
-						k = append(k, queryCondField{
-							Kind:  cond,
-							Field: field,
-						})
-					}
-					def.Conds = k
 				}
-			default:
-				if len(j.Conditions) != 0 {
-					return nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Query kind %s does not take conditions on %s of struct %s", j.Kind, j.Name, structName))
+			} else {
+				update = make([]queryField, 0, len(j.Update))
+				for _, c := range j.Update {
+					field, ok := queryFieldMap[c]
+					if !ok {
+						return queryDef{}, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Unknown update field %s for %s %s on struct %s", c, j.Kind, j.Name, structName))
+					}
+					update = append(update, field)
 				}
 			}
-			switch kind {
-			case queryKindGetGroup, queryKindGetGroupEq:
-				{
-					k := make([]queryOrderField, 0, len(j.Order))
-					for _, c := range j.Order {
-						field, ok := mdef.fieldMap[c.Col]
-						if !ok {
-							return nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Unknown order field %s for %s %s on struct %s", c.Col, j.Kind, j.Name, structName))
-						}
-						k = append(k, queryOrderField{
-							Field: field,
-							Dir:   c.Dir,
-						})
-					}
-					def.Order = k
+			def.Conflict = conflict
+			def.Update = update
+		}
+	default:
+		if j.Conflict != nil || j.Update != nil {
+			return queryDef{}, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Query kind %s does not take conflict or update on %s of struct %s", j.Kind, j.Name, structName))
+		}
+	}
+	switch kind {
+	case queryKindUpdEq, queryKindIncrEq, queryKindPatchEq, queryKindDelEq:
+		// returning query structs are resolved once all query structs are
+		// parsed
+		def.ReturningIdent = j.Returning
+		switch j.Affected {
+		case "", queryAffectedCount, queryAffectedNotFound:
+		default:
+			return queryDef{}, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Invalid affected %s on %s %s of struct %s", j.Affected, j.Kind, j.Name, structName))
+		}
+		if j.Affected != "" && j.Returning != "" {
+			return queryDef{}, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Query may not take both returning and affected on %s %s of struct %s", j.Kind, j.Name, structName))
+		}
+		def.Affected = j.Affected
+	default:
+		if j.Returning != "" {
+			return queryDef{}, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Query kind %s does not take returning on %s of struct %s", j.Kind, j.Name, structName))
+		}
+		if j.Affected != "" {
+			return queryDef{}, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Query kind %s does not take affected on %s of struct %s", j.Kind, j.Name, structName))
+		}
+	}
+	return def, nil
+}
+
+type (
+	rawTokenKind int
+)
+
+const (
+	rawTokenText rawTokenKind = iota
+	rawTokenTable
+	rawTokenColumns
+	rawTokenCol
+	rawTokenParam
+)
+
+func isRawParamName(name string) bool {
+	if name == "" || !isRawParamStart(name[0]) {
+		return false
+	}
+	for i := 1; i < len(name); i++ {
+		if !isRawParamChar(name[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isRawParamStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isRawParamChar(c byte) bool {
+	return isRawParamStart(c) || (c >= '0' && c <= '9')
+}
+
+// parseRawSQL parses the substitutions and named params of raw sql. Quoted
+// strings and identifiers are copied verbatim, line comments are removed, and
+// whitespace is collapsed such that the sql fits on a single line. A param
+// takes the type of the field of the same name unless specified by
+// paramCols. Params are returned in the order in which they first appear.
+func parseRawSQL(text string, fieldMap map[string]modelField, paramCols map[string]string) ([]queryRawToken, []queryRawParam, error) {
+	var tokens []queryRawToken
+	var params []queryRawParam
+	var lit strings.Builder
+	flush := func() {
+		if lit.Len() != 0 {
+			tokens = append(tokens, queryRawToken{
+				Kind: rawTokenText,
+				Text: lit.String(),
+			})
+			lit.Reset()
+		}
+	}
+	space := false
+	for i := 0; i < len(text); {
+		c := text[i]
+		if c == ' ' || c == '\t' || c == '\n' || c == '\r' || strings.HasPrefix(text[i:], "--") {
+			if c == '-' {
+				end := strings.IndexByte(text[i:], '\n')
+				if end < 0 {
+					end = len(text) - i
 				}
+				i += end
+			} else {
+				i++
+			}
+			space = true
+			continue
+		}
+		if space {
+			if lit.Len() != 0 || len(tokens) != 0 {
+				lit.WriteByte(' ')
+			}
+			space = false
+		}
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			end := strings.IndexByte(text[i+1:], c)
+			if end < 0 {
+				return nil, nil, kerrors.WithKind(nil, ErrInvalidModel, "Unterminated quote in sql")
+			}
+			lit.WriteString(text[i : i+end+2])
+			i += end + 2
+		case strings.HasPrefix(text[i:], "::"):
+			// postgres type casts are not params
+			lit.WriteString("::")
+			i += 2
+		case c == ':' && i+1 < len(text) && isRawParamStart(text[i+1]):
+			end := i + 2
+			for end < len(text) && isRawParamChar(text[end]) {
+				end++
+			}
+			name := text[i+1 : end]
+			col := name
+			if k, ok := paramCols[name]; ok {
+				col = k
+			}
+			field, ok := fieldMap[col]
+			if !ok {
+				return nil, nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Param %s is not a field of the model", name))
+			}
+			flush()
+			tokens = append(tokens, queryRawToken{
+				Kind: rawTokenParam,
+				Text: name,
+			})
+			if !slices.ContainsFunc(params, func(p queryRawParam) bool { return p.Name == name }) {
+				params = append(params, queryRawParam{
+					Name:   name,
+					GoType: field.GoType,
+				})
+			}
+			i = end
+		case strings.HasPrefix(text[i:], "{{"):
+			end := strings.Index(text[i+2:], "}}")
+			if end < 0 {
+				return nil, nil, kerrors.WithKind(nil, ErrInvalidModel, "Unterminated substitution in sql")
+			}
+			name := strings.TrimSpace(text[i+2 : i+2+end])
+			flush()
+			switch name {
+			case "table":
+				tokens = append(tokens, queryRawToken{
+					Kind: rawTokenTable,
+				})
+			case "columns":
+				tokens = append(tokens, queryRawToken{
+					Kind: rawTokenColumns,
+				})
 			default:
-				if len(j.Order) != 0 {
-					return nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Query kind %s does not take order on %s of struct %s", j.Kind, j.Name, structName))
+				field, ok := fieldMap[name]
+				if !ok {
+					return nil, nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Substitution %s is not a field of the model", name))
 				}
+				tokens = append(tokens, queryRawToken{
+					Kind:  rawTokenCol,
+					Field: field,
+				})
 			}
-			queries = append(queries, def)
+			i += end + 4
+		default:
+			lit.WriteByte(c)
+			i++
 		}
-		queryGroupDefs[prefix] = append(queryGroupDefs[prefix], queryGroupDef{
-			Ident:   structName,
-			Fields:  fields,
-			Queries: queries,
-		})
 	}
+	flush()
+	// the statement terminator is added by the query template
+	if n := len(tokens) - 1; n >= 0 && tokens[n].Kind == rawTokenText {
+		tokens[n].Text = strings.TrimRight(tokens[n].Text, "; ")
+	}
+	return tokens, params, nil
+}
 
-	return queryGroupDefs, nil
+// aggFieldMap returns the model fields along with the aggregate fields of a
+// query struct by their aggregate expressions
+func aggFieldMap(fieldMap map[string]modelField, fields []queryField) map[string]modelField {
+	m := make(map[string]modelField, len(fieldMap)+len(fields))
+	for k, v := range fieldMap {
+		m[k] = v
+	}
+	for _, i := range fields {
+		if i.Agg == "" {
+			continue
+		}
+		m[i.Expr] = modelField{
+			Ident:  i.Ident,
+			GoType: strings.TrimPrefix(i.GoType, "*"),
+			DBName: i.DBName,
+			Alias:  i.Alias,
+			Agg:    i.Agg,
+		}
+	}
+	return m
+}
+
+func parseQueryOrder(order []queryOrderOpt, fieldMap map[string]modelField) ([]queryOrderField, error) {
+	k := make([]queryOrderField, 0, len(order))
+	for _, i := range order {
+		field, ok := fieldMap[i.Col]
+		if !ok {
+			return nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Unknown order field %s", i.Col))
+		}
+		k = append(k, queryOrderField{
+			Field: field,
+			Dir:   i.Dir,
+		})
+	}
+	return k, nil
 }
 
 func parseQueryFields(astfields []astField, fieldMap map[string]modelField) ([]queryField, error) {
@@ -852,14 +3285,32 @@ func parseQueryFields(astfields []astField, fieldMap map[string]modelField) ([]q
 		if dbName == "" {
 			return nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Query field opt must be dbname for field %s", i.Ident))
 		}
-		if mfield, ok := fieldMap[dbName]; !ok || i.GoType != mfield.GoType {
+		if agg, col, ok := parseAggExpr(dbName); ok {
+			f, err := parseAggField(i, agg, col, fieldMap)
+			if err != nil {
+				return nil, err
+			}
+			f.Num = n + 1
+			f.Expr = dbName
+			fields = append(fields, f)
+			continue
+		}
+		mfield, ok := fieldMap[dbName]
+		if !ok {
+			return nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Field %s with type %s does not exist on model", dbName, i.GoType))
+		}
+		// a pointer to the model field type denotes an optional field
+		optional := i.GoType == "*"+mfield.GoType
+		if i.GoType != mfield.GoType && !optional {
 			return nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Field %s with type %s does not exist on model", dbName, i.GoType))
 		}
 		f := queryField{
-			Ident:  i.Ident,
-			GoType: i.GoType,
-			DBName: dbName,
-			Num:    n + 1,
+			Ident:    i.Ident,
+			GoType:   i.GoType,
+			DBName:   mfield.DBName,
+			Num:      n + 1,
+			Optional: optional,
+			Alias:    mfield.Alias,
 		}
 		fields = append(fields, f)
 	}
@@ -877,8 +3328,98 @@ const (
 	queryKindGetGroupEq
 	queryKindUpdEq
 	queryKindDelEq
+	queryKindUpsert
+	queryKindCount
+	queryKindCountEq
+	queryKindExistsEq
+	queryKindGetGroupKeyset
+	queryKindPatchEq
+	queryKindIncrEq
+	queryKindAggregate
+	queryKindRaw
+	queryKindGetMapIn
 )
 
+// parseAggExpr parses an aggregate expression of the form fn(col)
+func parseAggExpr(expr string) (string, string, bool) {
+	fn, rest, ok := strings.Cut(expr, "(")
+	if !ok {
+		return "", "", false
+	}
+	col, ok := strings.CutSuffix(rest, ")")
+	if !ok {
+		return "", "", false
+	}
+	return fn, col, true
+}
+
+func isNumericGoType(goType string) bool {
+	switch goType {
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "float32", "float64":
+		return true
+	default:
+		return false
+	}
+}
+
+// parseAggField parses a query field bound to an aggregate over a model
+// column
+func parseAggField(i astField, agg string, col string, fieldMap map[string]modelField) (queryField, error) {
+	switch agg {
+	case "count", "sum", "min", "max", "avg":
+	default:
+		return queryField{}, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Unknown aggregate %s for field %s", agg, i.Ident))
+	}
+	f := queryField{
+		Ident:  i.Ident,
+		GoType: i.GoType,
+		Agg:    strings.ToUpper(agg),
+	}
+	var colType string
+	if col == "*" {
+		if agg != "count" {
+			return queryField{}, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Aggregate %s may not be over all columns for field %s", agg, i.Ident))
+		}
+		f.DBName = col
+	} else {
+		mfield, ok := fieldMap[col]
+		if !ok {
+			return queryField{}, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Aggregate field %s does not exist on model for field %s", col, i.Ident))
+		}
+		f.DBName = mfield.DBName
+		f.Alias = mfield.Alias
+		colType = mfield.GoType
+	}
+	// aggregates other than count are null if all column values of a group are
+	// null, and may be scanned into a pointer
+	resType := strings.TrimPrefix(i.GoType, "*")
+	switch agg {
+	case "count":
+		if i.GoType != "int" && i.GoType != "int64" {
+			return queryField{}, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Aggregate count must have type int or int64 for field %s", i.Ident))
+		}
+	case "sum":
+		if !isNumericGoType(colType) {
+			return queryField{}, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Aggregate sum over non-numeric field %s for field %s", col, i.Ident))
+		}
+		if resType != colType && resType != "int64" && resType != "float64" {
+			return queryField{}, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Aggregate sum of %s must have type %s, int64, or float64 for field %s", col, colType, i.Ident))
+		}
+	case "min", "max":
+		if resType != colType {
+			return queryField{}, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Aggregate %s of %s must have type %s for field %s", agg, col, colType, i.Ident))
+		}
+	case "avg":
+		if !isNumericGoType(colType) {
+			return queryField{}, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Aggregate avg over non-numeric field %s for field %s", col, i.Ident))
+		}
+		if resType != "float64" {
+			return queryField{}, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Aggregate avg of %s must have type float64 for field %s", col, i.Ident))
+		}
+	}
+	return f, nil
+}
+
 func parseQueryKind(kind string) (queryKind, error) {
 	switch kind {
 	case "getoneeq":
@@ -891,6 +3432,26 @@ func parseQueryKind(kind string) (queryKind, error) {
 		return queryKindUpdEq, nil
 	case "deleq":
 		return queryKindDelEq, nil
+	case "upsert":
+		return queryKindUpsert, nil
+	case "count":
+		return queryKindCount, nil
+	case "counteq":
+		return queryKindCountEq, nil
+	case "existseq":
+		return queryKindExistsEq, nil
+	case "getgroupkeyset":
+		return queryKindGetGroupKeyset, nil
+	case "patcheq":
+		return queryKindPatchEq, nil
+	case "increq":
+		return queryKindIncrEq, nil
+	case "aggregate":
+		return queryKindAggregate, nil
+	case "raw":
+		return queryKindRaw, nil
+	case "getmapin":
+		return queryKindGetMapIn, nil
 	default:
 		return queryKindUnknown, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Illegal query kind %s", kind))
 	}
@@ -908,6 +3469,26 @@ func (q queryKind) String() string {
 		return "updeq"
 	case queryKindDelEq:
 		return "deleq"
+	case queryKindUpsert:
+		return "upsert"
+	case queryKindCount:
+		return "count"
+	case queryKindCountEq:
+		return "counteq"
+	case queryKindExistsEq:
+		return "existseq"
+	case queryKindGetGroupKeyset:
+		return "getgroupkeyset"
+	case queryKindPatchEq:
+		return "patcheq"
+	case queryKindIncrEq:
+		return "increq"
+	case queryKindAggregate:
+		return "aggregate"
+	case queryKindRaw:
+		return "raw"
+	case queryKindGetMapIn:
+		return "getmapin"
 	default:
 		return "unknown"
 	}
@@ -927,8 +3508,127 @@ const (
 	condGeq
 	condIn
 	condLike
+	condIsNull
+	condNotNull
+	condBetween
+	condPrefix
+	condSuffix
+	condContains
+	condIPrefix
+	condISuffix
+	condIContains
+	condArrContains
+	condArrOverlap
+	condEqAny
+	condJSONContains
+	condJSONHasKey
+)
+
+func (c condType) containerOp() ContainerOp {
+	switch c {
+	case condArrContains:
+		return ContainerOpArrContains
+	case condArrOverlap:
+		return ContainerOpArrOverlap
+	case condEqAny:
+		return ContainerOpEqAny
+	case condJSONContains:
+		return ContainerOpJSONContains
+	case condJSONHasKey:
+		return ContainerOpJSONHasKey
+	default:
+		return ContainerOpUnknown
+	}
+}
+
+type (
+	condGroup int
+)
+
+const (
+	condGroupNone condGroup = iota
+	condGroupAll
+	condGroupAny
 )
 
+func (g condGroup) String() string {
+	switch g {
+	case condGroupAll:
+		return " AND "
+	case condGroupAny:
+		return " OR "
+	default:
+		return ""
+	}
+}
+
+// parseQueryConds parses conditions and nested groups of conditions
+func parseQueryConds(conds []queryCondOpt, fieldMap map[string]modelField) ([]queryCondField, error) {
+	k := make([]queryCondField, 0, len(conds))
+	for _, c := range conds {
+		if c.Any != nil || c.All != nil {
+			if c.Col != "" || c.Cond != "" || (c.Any != nil && c.All != nil) {
+				return nil, kerrors.WithKind(nil, ErrInvalidModel, "Condition group must only have one of any or all")
+			}
+			group := condGroupAll
+			groupConds := c.All
+			if c.Any != nil {
+				group = condGroupAny
+				groupConds = c.Any
+			}
+			if len(groupConds) == 0 {
+				return nil, kerrors.WithKind(nil, ErrInvalidModel, "Condition group missing conditions")
+			}
+			children, err := parseQueryConds(groupConds, fieldMap)
+			if err != nil {
+				return nil, err
+			}
+			k = append(k, queryCondField{
+				Group: group,
+				Conds: children,
+			})
+			continue
+		}
+		field, ok := fieldMap[c.Col]
+		if !ok {
+			return nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Unknown condition field %s", c.Col))
+		}
+		cond, err := parseCond(c.Cond)
+		if err != nil {
+			return nil, kerrors.WithMsg(err, fmt.Sprintf("Invalid condition for field %s", c.Col))
+		}
+		switch cond {
+		case condPrefix, condSuffix, condContains, condIPrefix, condISuffix, condIContains:
+			if field.GoType != "string" {
+				return nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Like condition on non-string field %s", c.Col))
+			}
+		case condArrContains, condArrOverlap:
+			if !strings.HasPrefix(field.GoType, "[]") {
+				return nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Array condition on non-slice field %s", c.Col))
+			}
+		case condEqAny:
+			// eqany takes a slice of the go field type of a scalar column
+			if strings.HasPrefix(field.GoType, "[]") {
+				return nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Any condition on slice field %s", c.Col))
+			}
+		case condJSONContains, condJSONHasKey:
+			if !isJSONField(field) {
+				return nil, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("JSON condition on non-json field %s", c.Col))
+			}
+		}
+		k = append(k, queryCondField{
+			Kind:  cond,
+			Field: field,
+		})
+	}
+	return k, nil
+}
+
+// isJSONField returns true if the sql type of a field is json or jsonb
+func isJSONField(f modelField) bool {
+	return strings.HasPrefix(strings.ToUpper(f.DBType), "JSON")
+}
+
 func parseCond(cond string) (condType, error) {
 	switch cond {
 	case "", "eq":
@@ -947,6 +3647,34 @@ func parseCond(cond string) (condType, error) {
 		return condIn, nil
 	case "like":
 		return condLike, nil
+	case "isnull":
+		return condIsNull, nil
+	case "notnull":
+		return condNotNull, nil
+	case "between":
+		return condBetween, nil
+	case "prefix":
+		return condPrefix, nil
+	case "suffix":
+		return condSuffix, nil
+	case "contains":
+		return condContains, nil
+	case "iprefix":
+		return condIPrefix, nil
+	case "isuffix":
+		return condISuffix, nil
+	case "icontains":
+		return condIContains, nil
+	case "arrcontains":
+		return condArrContains, nil
+	case "arroverlap":
+		return condArrOverlap, nil
+	case "eqany":
+		return condEqAny, nil
+	case "jsoncontains":
+		return condJSONContains, nil
+	case "jsonhaskey":
+		return condJSONHasKey, nil
 	default:
 		return condUnknown, kerrors.WithKind(nil, ErrInvalidModel, fmt.Sprintf("Illegal cond type %s", cond))
 	}
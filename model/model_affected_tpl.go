@@ -0,0 +1,27 @@
+package model
+
+const templateAffected = `
+{{- define "affectedcount" }}
+	if err != nil {
+		return 0, err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+{{- end }}
+{{- define "affectednotfound" }}
+	if err != nil {
+		return err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return sqldb.ErrNotFound
+	}
+	return nil
+{{- end }}
+`
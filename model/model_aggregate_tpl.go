@@ -0,0 +1,28 @@
+package model
+
+const templateAggregate = `
+func (t *{{.Prefix}}ModelTable) Get{{.ModelIdent}}{{.Name}}(ctx context.Context, d sqldb.Executor{{template "condparams" .}}, limit, offset int) (_ []{{.ModelIdent}}, retErr error) {
+	{{- template "condargs" . }}
+	res := make([]{{.ModelIdent}}, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT {{.SQL.DBNames}} FROM {{.SQL.Table}}{{with .SQLCond.DBCond}} WHERE {{.}}{{end}}{{with .SQL.GroupBy}} GROUP BY {{.}}{{end}}{{with .SQLCond.HavingCond}} HAVING {{.}}{{end}}{{with .SQLOrder.DBOrder}} ORDER BY {{.}}{{end}} {{.SQLOrder.Limit}};"{{template "condexecargs" .}})
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m {{.ModelIdent}}
+		if err := rows.Scan({{.SQL.IdentRefs}}); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+`
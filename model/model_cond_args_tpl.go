@@ -0,0 +1,32 @@
+package model
+
+const templateCondArgs = `
+{{- define "condargs" }}
+	{{- if .SQLCond.ArrIdentArgs }}
+	paramCount := {{.SQLCond.ParamCount}}
+	args := make([]interface{}, 0, paramCount{{with .SQLCond.ArrIdentArgsLen}}+{{.}}{{end}})
+	{{- range .SQLCond.ArgGroups }}
+	{{- if .Arr }}
+	var placeholders{{.Arr}} string
+	{
+		placeholders := make([]string, 0, len({{.Arr}}))
+		for _, i := range {{.Arr}} {
+			{{- if $.SQLCond.Positional }}
+			placeholders = append(placeholders, "{{$.SQLCond.ArrPlaceholder}}")
+			{{- else }}
+			paramCount++
+			placeholders = append(placeholders, fmt.Sprintf("{{$.SQLCond.ArrPlaceholder}}", paramCount))
+			{{- end }}
+			args = append(args, i)
+		}
+		placeholders{{.Arr}} = strings.Join(placeholders, ", ")
+	}
+	{{- else }}
+	args = append(args, {{.Args}})
+	{{- end }}
+	{{- end }}
+	{{- end }}
+{{- end }}
+{{- define "condparams" }}{{with .SQLCond.IdentParams}}, {{.}}{{end}}{{end}}
+{{- define "condexecargs" }}{{if .SQLCond.ArrIdentArgs}}, args...{{else}}{{with .SQLCond.IdentArgs}}, {{.}}{{end}}{{end}}{{end}}
+`
@@ -0,0 +1,12 @@
+package model
+
+const templateCountEq = `
+func (t *{{.Prefix}}ModelTable) Count{{.ModelIdent}}{{.Name}}(ctx context.Context, d sqldb.Executor{{template "condparams" .}}) (int, error) {
+	{{- template "condargs" . }}
+	var count int
+	if err := d.QueryRowContext(ctx, "SELECT COUNT(*) FROM {{.SQL.Table}} WHERE {{.SQLCond.DBCond}};"{{template "condexecargs" .}}).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+`
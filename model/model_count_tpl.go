@@ -0,0 +1,11 @@
+package model
+
+const templateCount = `
+func (t *{{.Prefix}}ModelTable) Count{{.ModelIdent}}{{.Name}}(ctx context.Context, d sqldb.Executor) (int, error) {
+	var count int
+	if err := d.QueryRowContext(ctx, "SELECT COUNT(*) FROM {{.SQL.Table}}{{with .SQLCond.DBCond}} WHERE {{.}}{{end}};").Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+`
@@ -1,27 +1,28 @@
 package model
 
 const templateDelEq = `
-func (t *{{.Prefix}}ModelTable) Del{{.Name}}(ctx context.Context, d sqldb.Executor, {{.SQLCond.IdentParams}}) error {
-	{{- if .SQLCond.ArrIdentArgs }}
-	paramCount := {{.SQLCond.ParamCount}}
-	args := make([]interface{}, 0, paramCount{{with .SQLCond.ArrIdentArgsLen}}+{{.}}{{end}})
-	{{- if .SQLCond.IdentArgs }}
-	args = append(args, {{.SQLCond.IdentArgs}})
-	{{- end }}
-	{{- end }}
-	{{- range .SQLCond.ArrIdentArgs }}
-	var placeholders{{.}} string
-	{
-		placeholders := make([]string, 0, len({{.}}))
-		for _, i := range {{.}} {
-			paramCount++
-			placeholders = append(placeholders, fmt.Sprintf("($%d)", paramCount))
-			args = append(args, i)
-		}
-		placeholders{{.}} = strings.Join(placeholders, ", ")
-	}
-	{{- end }}
-	_, err := d.ExecContext(ctx, "DELETE FROM "+t.TableName+" WHERE {{.SQLCond.DBCond}};", {{if .SQLCond.ArrIdentArgs}}args...{{else}}{{.SQLCond.IdentArgs}}{{end}})
+{{- if .SQLReturn.Ident }}
+func (t *{{.Prefix}}ModelTable) {{.SQLDel.Method}}{{.Name}}(ctx context.Context, d sqldb.Executor{{template "condparams" .}}) (_ []{{.SQLReturn.Ident}}, retErr error) {
+	{{- template "condargs" . }}
+	rows, err := d.QueryContext(ctx, "{{.SQLDel.Stmt}} WHERE {{.SQLCond.DBCond}}{{.SQLReturn.Returning}};"{{template "condexecargs" .}})
+	{{- template "returningrows" . }}
+}
+{{- else if eq .Affected "count" }}
+func (t *{{.Prefix}}ModelTable) {{.SQLDel.Method}}{{.Name}}(ctx context.Context, d sqldb.Executor{{template "condparams" .}}) (int64, error) {
+	{{- template "condargs" . }}
+	res, err := d.ExecContext(ctx, "{{.SQLDel.Stmt}} WHERE {{.SQLCond.DBCond}};"{{template "condexecargs" .}})
+	{{- template "affectedcount" . }}
+}
+{{- else }}
+func (t *{{.Prefix}}ModelTable) {{.SQLDel.Method}}{{.Name}}(ctx context.Context, d sqldb.Executor{{template "condparams" .}}) error {
+	{{- template "condargs" . }}
+	{{- if eq .Affected "notfound" }}
+	res, err := d.ExecContext(ctx, "{{.SQLDel.Stmt}} WHERE {{.SQLCond.DBCond}};"{{template "condexecargs" .}})
+	{{- template "affectednotfound" . }}
+	{{- else }}
+	_, err := d.ExecContext(ctx, "{{.SQLDel.Stmt}} WHERE {{.SQLCond.DBCond}};"{{template "condexecargs" .}})
 	return err
+	{{- end }}
 }
+{{- end }}
 `
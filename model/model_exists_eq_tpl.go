@@ -0,0 +1,12 @@
+package model
+
+const templateExistsEq = `
+func (t *{{.Prefix}}ModelTable) Exists{{.ModelIdent}}{{.Name}}(ctx context.Context, d sqldb.Executor{{template "condparams" .}}) (bool, error) {
+	{{- template "condargs" . }}
+	var exists bool
+	if err := d.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM {{.SQL.Table}} WHERE {{.SQLCond.DBCond}});"{{template "condexecargs" .}}).Scan(&exists); err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+`
@@ -0,0 +1,181 @@
+package model
+
+const templateFilter = `
+type (
+	// {{.Prefix}}ModelFilter builds a query of {{.Prefix}}ModelTable with
+	// conditions joined by AND
+	{{.Prefix}}ModelFilter struct {
+		t      *{{.Prefix}}ModelTable
+		conds  []string
+		args   []interface{}
+		order  []string
+		limit  int
+		offset int
+		{{- if .SQL.SoftDel }}
+
+		includingDeleted bool
+		{{- end }}
+	}
+)
+
+func (t *{{.Prefix}}ModelTable) Filter() *{{.Prefix}}ModelFilter {
+	return &{{.Prefix}}ModelFilter{
+		t: t,
+	}
+}
+{{- range $field := .SQL.Fields }}
+{{- range $.SQL.Ops }}
+
+func (f *{{$.Prefix}}ModelFilter) Where{{$field.Ident}}{{.Name}}({{$field.Param}} {{$field.GoType}}) *{{$.Prefix}}ModelFilter {
+	f.args = append(f.args, {{$field.Param}})
+	{{- if $.SQL.Positional }}
+	f.conds = append(f.conds, "{{$field.DBName}} {{.Op}} {{$.SQL.Placeholder}}")
+	{{- else }}
+	f.conds = append(f.conds, fmt.Sprintf("{{$field.DBName}} {{.Op}} {{$.SQL.Placeholder}}", len(f.args)))
+	{{- end }}
+	return f
+}
+{{- end }}
+
+func (f *{{$.Prefix}}ModelFilter) Where{{.Ident}}In({{.Param}}s []{{.GoType}}) *{{$.Prefix}}ModelFilter {
+	if len({{.Param}}s) == 0 {
+		// an empty IN list is invalid sql, and matches no rows
+		f.conds = append(f.conds, "1 = 0")
+		return f
+	}
+	placeholders := make([]string, 0, len({{.Param}}s))
+	for _, i := range {{.Param}}s {
+		f.args = append(f.args, i)
+		{{- if $.SQL.Positional }}
+		placeholders = append(placeholders, "{{$.SQL.ArrPlaceholder}}")
+		{{- else }}
+		placeholders = append(placeholders, fmt.Sprintf("{{$.SQL.ArrPlaceholder}}", len(f.args)))
+		{{- end }}
+	}
+	f.conds = append(f.conds, "{{.InList}}")
+	return f
+}
+{{- if .Like }}
+
+// Where{{.Ident}}Like matches a raw LIKE pattern with an ESCAPE of
+// sqldb.LikeEscape, and text of the pattern which is matched literally may be
+// escaped by sqldb.EscapeLike
+func (f *{{$.Prefix}}ModelFilter) Where{{.Ident}}Like({{.Param}}Pattern string) *{{$.Prefix}}ModelFilter {
+	f.args = append(f.args, {{.Param}}Pattern)
+	{{- if $.SQL.Positional }}
+	f.conds = append(f.conds, "{{.DBName}} LIKE {{$.SQL.Placeholder}} ESCAPE '{{$.SQL.LikeEscape}}'")
+	{{- else }}
+	f.conds = append(f.conds, fmt.Sprintf("{{.DBName}} LIKE {{$.SQL.Placeholder}} ESCAPE '{{$.SQL.LikeEscape}}'", len(f.args)))
+	{{- end }}
+	return f
+}
+{{- end }}
+
+func (f *{{$.Prefix}}ModelFilter) Where{{.Ident}}IsNull() *{{$.Prefix}}ModelFilter {
+	f.conds = append(f.conds, "{{.DBName}} IS NULL")
+	return f
+}
+
+func (f *{{$.Prefix}}ModelFilter) Where{{.Ident}}NotNull() *{{$.Prefix}}ModelFilter {
+	f.conds = append(f.conds, "{{.DBName}} IS NOT NULL")
+	return f
+}
+
+func (f *{{$.Prefix}}ModelFilter) Order{{.Ident}}(desc bool) *{{$.Prefix}}ModelFilter {
+	if desc {
+		f.order = append(f.order, "{{.DBName}} DESC")
+	} else {
+		f.order = append(f.order, "{{.DBName}}")
+	}
+	return f
+}
+{{- end }}
+{{- if .SQL.SoftDel }}
+
+// IncludingDeleted includes soft deleted rows in the query
+func (f *{{.Prefix}}ModelFilter) IncludingDeleted() *{{.Prefix}}ModelFilter {
+	f.includingDeleted = true
+	return f
+}
+{{- end }}
+
+// Limit limits the number of rows of the query, and is not applied if zero or
+// negative
+func (f *{{.Prefix}}ModelFilter) Limit(limit int) *{{.Prefix}}ModelFilter {
+	if limit < 0 {
+		limit = 0
+	}
+	f.limit = limit
+	return f
+}
+
+// Offset skips rows of the query, and is only applied with a limit
+func (f *{{.Prefix}}ModelFilter) Offset(offset int) *{{.Prefix}}ModelFilter {
+	f.offset = offset
+	return f
+}
+
+func (f *{{.Prefix}}ModelFilter) query(cols string) (string, []interface{}) {
+	t := f.t
+	query := "SELECT " + cols + " FROM " + {{.SQL.TableExpr}}
+	conds := f.conds
+	{{- with .SQL.SoftDel }}
+	if !f.includingDeleted {
+		conds = append([]string{"{{.}}"}, conds...)
+	}
+	{{- end }}
+	if len(conds) != 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	if len(f.order) != 0 {
+		query += " ORDER BY " + strings.Join(f.order, ", ")
+	}
+	args := make([]interface{}, 0, len(f.args)+2)
+	args = append(args, f.args...)
+	if f.limit > 0 {
+		args = append(args, f.limit)
+		if f.offset > 0 {
+			args = append(args, f.offset)
+			{{- if .SQL.Positional }}
+			query += "{{.SQL.LimitOffset}}"
+			{{- else }}
+			query += fmt.Sprintf("{{.SQL.LimitOffset}}", len(args)-1, len(args))
+			{{- end }}
+		} else {
+			{{- if .SQL.Positional }}
+			query += "{{.SQL.Limit}}"
+			{{- else }}
+			query += fmt.Sprintf("{{.SQL.Limit}}", len(args))
+			{{- end }}
+		}
+	}
+	return query + ";", args
+}
+{{- range .SQL.Queries }}
+
+func (f *{{$.Prefix}}ModelFilter) Get{{.Ident}}(ctx context.Context, d sqldb.Executor) (_ []{{.Ident}}, retErr error) {
+	query, args := f.query("{{.DBNames}}")
+	res := make([]{{.Ident}}, 0, f.limit)
+	rows, err := d.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m {{.Ident}}
+		if err := rows.Scan({{.IdentRefs}}); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+{{- end }}
+`
@@ -0,0 +1,53 @@
+package model
+
+const templateGetGroupKeyset = `
+func (t *{{.Prefix}}ModelTable) Get{{.ModelIdent}}{{.Name}}(ctx context.Context, d sqldb.Executor{{template "condparams" .}}, limit int) (_ []{{.ModelIdent}}, retErr error) {
+	{{- template "condargs" . }}
+	res := make([]{{.ModelIdent}}, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT {{.SQL.DBNames}} FROM {{.SQL.Table}}{{with .SQLCond.DBCond}} WHERE {{.}}{{end}} ORDER BY {{.SQLOrder.DBOrder}} {{.SQLOrder.Limit}};"{{template "condexecargs" .}})
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m {{.ModelIdent}}
+		if err := rows.Scan({{.SQL.IdentRefs}}); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *{{.Prefix}}ModelTable) Get{{.ModelIdent}}{{.Name}}After(ctx context.Context, d sqldb.Executor{{template "condparams" .}}, after *{{.ModelIdent}}, limit int) (_ []{{.ModelIdent}}, retErr error) {
+	{{- template "condargs" .SQLKeyset }}
+	res := make([]{{.ModelIdent}}, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT {{.SQL.DBNames}} FROM {{.SQL.Table}} WHERE {{.SQLKeyset.SQLCond.DBCond}} ORDER BY {{.SQLOrder.DBOrder}} {{.SQLOrder.Limit}};"{{template "condexecargs" .SQLKeyset}})
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m {{.ModelIdent}}
+		if err := rows.Scan({{.SQL.IdentRefs}}); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+`
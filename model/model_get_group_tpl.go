@@ -1,9 +1,11 @@
 package model
 
 const templateGetGroup = `
-func (t *{{.Prefix}}ModelTable) Get{{.ModelIdent}}{{.Name}}(ctx context.Context, d sqldb.Executor, limit, offset int) (_ []{{.ModelIdent}}, retErr error) {
+{{- template "sorttype" . }}
+func (t *{{.Prefix}}ModelTable) Get{{.ModelIdent}}{{.Name}}(ctx context.Context, d sqldb.Executor{{template "sortparam" .}}, limit, offset int) (_ []{{.ModelIdent}}, retErr error) {
+	{{- template "sortorder" . }}
 	res := make([]{{.ModelIdent}}, 0, limit)
-	rows, err := d.QueryContext(ctx, "SELECT {{.SQL.DBNames}} FROM "+t.TableName+"{{with .SQLOrder.DBOrder}} ORDER BY {{.}}{{end}} LIMIT {{.PlaceholderPrefix}}1 OFFSET {{.PlaceholderPrefix}}2;", limit, offset)
+	rows, err := d.QueryContext(ctx, "SELECT {{.SQL.DBNames}} FROM {{.SQL.Table}}{{with .SQLCond.DBCond}} WHERE {{.}}{{end}}{{with .SQLOrder.DBOrder}} ORDER BY {{.}}{{end}} {{.SQLOrder.Limit}}{{.SQLLock}};", limit, offset)
 	if err != nil {
 		return nil, err
 	}
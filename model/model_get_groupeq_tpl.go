@@ -1,26 +1,12 @@
 package model
 
 const templateGetGroupEq = `
-func (t *{{.Prefix}}ModelTable) Get{{.ModelIdent}}{{.Name}}(ctx context.Context, d sqldb.Executor, {{.SQLCond.IdentParams}}, limit, offset int) (_ []{{.ModelIdent}}, retErr error) {
-	{{- if .SQLCond.ArrIdentArgs }}
-	paramCount := {{.SQLCond.ParamCount}}
-	args := make([]interface{}, 0, paramCount{{with .SQLCond.ArrIdentArgsLen}}+{{.}}{{end}})
-	args = append(args, limit, offset{{if .SQLCond.IdentArgs}}, {{.SQLCond.IdentArgs}}{{end}})
-	{{- end }}
-	{{- range .SQLCond.ArrIdentArgs }}
-	var placeholders{{.}} string
-	{
-		placeholders := make([]string, 0, len({{.}}))
-		for _, i := range {{.}} {
-			paramCount++
-			placeholders = append(placeholders, fmt.Sprintf("($%d)", paramCount))
-			args = append(args, i)
-		}
-		placeholders{{.}} = strings.Join(placeholders, ", ")
-	}
-	{{- end }}
+{{- template "sorttype" . }}
+func (t *{{.Prefix}}ModelTable) Get{{.ModelIdent}}{{.Name}}(ctx context.Context, d sqldb.Executor{{template "condparams" .}}{{template "sortparam" .}}, limit, offset int) (_ []{{.ModelIdent}}, retErr error) {
+	{{- template "condargs" . }}
+	{{- template "sortorder" . }}
 	res := make([]{{.ModelIdent}}, 0, limit)
-	rows, err := d.QueryContext(ctx, "SELECT {{.SQL.DBNames}} FROM "+t.TableName+" WHERE {{.SQLCond.DBCond}}{{with .SQLOrder.DBOrder}} ORDER BY {{.}}{{end}} LIMIT {{.PlaceholderPrefix}}1 OFFSET {{.PlaceholderPrefix}}2;", {{if .SQLCond.ArrIdentArgs}}args...{{else}}limit, offset, {{.SQLCond.IdentArgs}}{{end}})
+	rows, err := d.QueryContext(ctx, "SELECT {{.SQL.DBNames}} FROM {{.SQL.Table}} WHERE {{.SQLCond.DBCond}}{{with .SQLOrder.DBOrder}} ORDER BY {{.}}{{end}} {{.SQLOrder.Limit}}{{.SQLLock}};"{{template "condexecargs" .}})
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,45 @@
+package model
+
+const templateGetMapIn = `
+func (t *{{.Prefix}}ModelTable) Get{{.ModelIdent}}{{.Name}}(ctx context.Context, d sqldb.Executor{{template "condparams" .}}) (_ map[{{.SQLMap.KeyType}}]{{if .SQLMap.Many}}[]{{end}}{{.ModelIdent}}, {{if .SQLMap.Missing}}_ []{{.SQLMap.KeyType}}, {{end}}retErr error) {
+	res := map[{{.SQLMap.KeyType}}]{{if .SQLMap.Many}}[]{{end}}{{.ModelIdent}}{}
+	if len({{.SQLMap.KeyParam}}) == 0 {
+		return res, {{if .SQLMap.Missing}}nil, {{end}}nil
+	}
+	{{- template "condargs" . }}
+	rows, err := d.QueryContext(ctx, "SELECT {{.SQL.DBNames}} FROM {{.SQL.Table}} WHERE {{.SQLCond.DBCond}}{{with .SQLOrder.DBOrder}} ORDER BY {{.}}{{end}};"{{template "condexecargs" .}})
+	if err != nil {
+		return nil, {{if .SQLMap.Missing}}nil, {{end}}err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m {{.ModelIdent}}
+		if err := rows.Scan({{.SQL.IdentRefs}}); err != nil {
+			return nil, {{if .SQLMap.Missing}}nil, {{end}}err
+		}
+		{{- if .SQLMap.Many }}
+		res[m.{{.SQLMap.KeyIdent}}] = append(res[m.{{.SQLMap.KeyIdent}}], m)
+		{{- else }}
+		res[m.{{.SQLMap.KeyIdent}}] = m
+		{{- end }}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, {{if .SQLMap.Missing}}nil, {{end}}err
+	}
+	{{- if .SQLMap.Missing }}
+	var missing []{{.SQLMap.KeyType}}
+	for _, i := range {{.SQLMap.KeyParam}} {
+		if _, ok := res[i]; !ok {
+			missing = append(missing, i)
+		}
+	}
+	return res, missing, nil
+	{{- else }}
+	return res, nil
+	{{- end }}
+}
+`
@@ -0,0 +1,12 @@
+package model
+
+const templateJoin = `
+type (
+	// {{.Prefix}}ModelTable queries the joined tables of {{.Prefix}}
+	{{.Prefix}}ModelTable struct {
+		{{- range .Fields }}
+		{{.}}
+		{{- end }}
+	}
+)
+`
@@ -0,0 +1,103 @@
+package model
+
+const templatePatchEq = `
+{{- define "patchset" }}
+	{{- if .SQLPatch.Clock }}
+	now := t.now()
+	{{- end }}
+	{{- if .SQLCond.Positional }}
+	set := make([]string, 0, {{.SQLPatch.NumFields}})
+	setArgs := make([]interface{}, 0, {{.SQLPatch.NumFields}})
+	{{- range .SQLPatch.Fields }}
+	{{- if .Optional }}
+	if m.{{.Ident}} != nil {
+		set = append(set, "{{.SetFmt}}")
+		setArgs = append(setArgs, *m.{{.Ident}})
+	}
+	{{- else }}
+	set = append(set, "{{.SetFmt}}")
+	setArgs = append(setArgs, m.{{.Ident}})
+	{{- end }}
+	{{- end }}
+	{{- range .SQLPatch.Times }}
+	set = append(set, "{{.SetFmt}}")
+	{{- with .Value }}
+	setArgs = append(setArgs, {{.}})
+	{{- end }}
+	{{- end }}
+	{{- template "condargs" . }}
+	{{- if .SQLCond.ArrIdentArgs }}
+	args = append(setArgs, args...)
+	{{- else }}
+	args := append(setArgs{{with .SQLCond.IdentArgs}}, {{.}}{{end}})
+	{{- end }}
+	{{- else }}
+	{{- if .SQLCond.ArrIdentArgs }}
+	{{- template "condargs" . }}
+	{{- else }}
+	paramCount := {{.SQLCond.ParamCount}}
+	args := make([]interface{}, 0, paramCount+{{.SQLPatch.NumFields}})
+	{{- with .SQLCond.IdentArgs }}
+	args = append(args, {{.}})
+	{{- end }}
+	{{- end }}
+	set := make([]string, 0, {{.SQLPatch.NumFields}})
+	{{- range .SQLPatch.Fields }}
+	{{- if .Optional }}
+	if m.{{.Ident}} != nil {
+		paramCount++
+		set = append(set, fmt.Sprintf("{{.SetFmt}}", paramCount))
+		args = append(args, *m.{{.Ident}})
+	}
+	{{- else }}
+	paramCount++
+	set = append(set, fmt.Sprintf("{{.SetFmt}}", paramCount))
+	args = append(args, m.{{.Ident}})
+	{{- end }}
+	{{- end }}
+	{{- range .SQLPatch.Times }}
+	{{- if .Value }}
+	paramCount++
+	set = append(set, fmt.Sprintf("{{.SetFmt}}", paramCount))
+	args = append(args, {{.Value}})
+	{{- else }}
+	set = append(set, "{{.SetFmt}}")
+	{{- end }}
+	{{- end }}
+	{{- end }}
+	{{- if .SQLPatch.HasOptional }}
+	if len(set) == 0 {
+		// assign a column to itself when no fields are set such that the
+		// update still matches rows
+		set = append(set, "{{.SQLPatch.NoopSet}}")
+	}
+	{{- end }}
+{{- end }}
+{{- if .SQLReturn.Ident }}
+func (t *{{.Prefix}}ModelTable) Patch{{.ModelIdent}}{{.Name}}(ctx context.Context, d sqldb.Executor, m *{{.ModelIdent}}{{template "condparams" .}}) (_ []{{.SQLReturn.Ident}}, retErr error) {
+	{{- template "patchset" . }}
+	rows, err := d.QueryContext(ctx, "UPDATE {{.SQL.Table}} SET "+strings.Join(set, ", ")+" WHERE {{.SQLCond.DBCond}}{{.SQLReturn.Returning}};", args...)
+	{{- template "returningrows" . }}
+}
+{{- else if eq .Affected "count" }}
+func (t *{{.Prefix}}ModelTable) Patch{{.ModelIdent}}{{.Name}}(ctx context.Context, d sqldb.Executor, m *{{.ModelIdent}}{{template "condparams" .}}) (int64, error) {
+	{{- template "patchset" . }}
+	res, err := d.ExecContext(ctx, "UPDATE {{.SQL.Table}} SET "+strings.Join(set, ", ")+" WHERE {{.SQLCond.DBCond}};", args...)
+	{{- template "affectedcount" . }}
+}
+{{- else }}
+func (t *{{.Prefix}}ModelTable) Patch{{.ModelIdent}}{{.Name}}(ctx context.Context, d sqldb.Executor, m *{{.ModelIdent}}{{template "condparams" .}}) error {
+	{{- template "patchset" . }}
+	{{- if eq .Affected "notfound" }}
+	res, err := d.ExecContext(ctx, "UPDATE {{.SQL.Table}} SET "+strings.Join(set, ", ")+" WHERE {{.SQLCond.DBCond}};", args...)
+	{{- template "affectednotfound" . }}
+	{{- else }}
+	_, err := d.ExecContext(ctx, "UPDATE {{.SQL.Table}} SET "+strings.Join(set, ", ")+" WHERE {{.SQLCond.DBCond}};", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+	{{- end }}
+}
+{{- end }}
+`
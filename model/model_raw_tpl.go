@@ -0,0 +1,27 @@
+package model
+
+const templateRaw = `
+func (t *{{.Prefix}}ModelTable) Get{{.ModelIdent}}{{.Name}}(ctx context.Context, d sqldb.Executor{{with .SQLRaw.IdentParams}}, {{.}}{{end}}) (_ []{{.ModelIdent}}, retErr error) {
+	res := []{{.ModelIdent}}{}
+	rows, err := d.QueryContext(ctx, "{{.SQLRaw.SQL}};"{{with .SQLRaw.IdentArgs}}, {{.}}{{end}})
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m {{.ModelIdent}}
+		if err := rows.Scan({{.SQL.IdentRefs}}); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+`
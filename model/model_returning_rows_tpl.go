@@ -0,0 +1,26 @@
+package model
+
+const templateReturningRows = `
+{{- define "returningrows" }}
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	var res []{{.SQLReturn.Ident}}
+	for rows.Next() {
+		var r {{.SQLReturn.Ident}}
+		if err := rows.Scan({{.SQLReturn.IdentRefs}}); err != nil {
+			return nil, err
+		}
+		res = append(res, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+{{- end }}
+`
@@ -0,0 +1,35 @@
+package model
+
+const templateSort = `
+{{- define "sorttype" }}
+{{- if not .SQLOrder.SortDeclared }}
+{{- with .SQLOrder.SortIdent }}
+type (
+	// {{.}} is a sort of Get{{$.ModelIdent}}{{$.Name}}
+	{{.}} int
+)
+
+const (
+	{{- range $n, $s := $.SQLOrder.Sorts }}
+	{{.Ident}}{{if eq $n 0}} {{$.SQLOrder.SortIdent}} = iota{{end}}
+	{{- end }}
+)
+{{ end }}
+{{- end }}
+{{- end }}
+{{- define "sortparam" }}{{with .SQLOrder.SortIdent}}, sort {{.}}{{end}}{{end}}
+{{- define "sortorder" }}
+	{{- with .SQLOrder.Sorts }}
+	// unknown sorts use the first sort
+	orderBy := "{{(index . 0).DBOrder}}"
+	{{- if gt (len .) 1 }}
+	switch sort {
+	{{- range (slice . 1) }}
+	case {{.Ident}}:
+		orderBy = "{{.DBOrder}}"
+	{{- end }}
+	}
+	{{- end }}
+	{{- end }}
+{{- end }}
+`
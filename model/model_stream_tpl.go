@@ -0,0 +1,30 @@
+package model
+
+const templateStream = `
+func (t *{{.Prefix}}ModelTable) Stream{{.ModelIdent}}{{.Name}}(ctx context.Context, d sqldb.Executor{{template "condparams" .}}{{template "sortparam" .}}, fn func(m {{.ModelIdent}}) error) (retErr error) {
+	{{- template "condargs" . }}
+	{{- template "sortorder" . }}
+	rows, err := d.QueryContext(ctx, "SELECT {{.SQL.DBNames}} FROM {{.SQL.Table}}{{with .SQLCond.DBCond}} WHERE {{.}}{{end}}{{with .SQLOrder.DBOrder}} ORDER BY {{.}}{{end}}{{.SQLLock}};"{{template "condexecargs" .}})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m {{.ModelIdent}}
+		if err := rows.Scan({{.SQL.IdentRefs}}); err != nil {
+			return err
+		}
+		if err := fn(m); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return nil
+}
+`
@@ -4,16 +4,25 @@ const templateModel = `
 type (
 	{{.Prefix}}ModelTable struct {
 		TableName string
+		{{- if .SQL.Clock }}
+		// Clock returns the current time, and is time.Now if nil
+		Clock func() time.Time
+		{{- end }}
 	}
 )
+{{- if .SQL.Clock }}
 
-func (t *{{.Prefix}}ModelTable) Setup(ctx context.Context, d sqldb.Executor) error {
-	_, err := d.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+t.TableName+" ({{.SQL.Setup}});")
-	if err != nil {
-		return err
+func (t *{{.Prefix}}ModelTable) now() time.Time {
+	if t.Clock != nil {
+		return t.Clock()
 	}
-	{{- range .SQL.Indicies }}
-	_, err = d.ExecContext(ctx, "CREATE INDEX IF NOT EXISTS "+t.TableName+"_{{.Name}}_index ON "+t.TableName+" ({{.Columns}});")
+	return time.Now()
+}
+{{- end }}
+
+func (t *{{.Prefix}}ModelTable) Setup(ctx context.Context, d sqldb.Executor) error {
+	{{- range $n, $stmt := .SQL.Setup }}
+	_, err {{if eq $n 0}}:{{end}}= d.ExecContext(ctx, "{{$stmt}};")
 	if err != nil {
 		return err
 	}
@@ -22,26 +31,54 @@ func (t *{{.Prefix}}ModelTable) Setup(ctx context.Context, d sqldb.Executor) err
 }
 
 func (t *{{.Prefix}}ModelTable) Insert(ctx context.Context, d sqldb.Executor, m *{{.ModelIdent}}) error {
-	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" ({{.SQL.DBNames}}) VALUES ({{.SQL.Placeholders}});", {{.SQL.Idents}})
+	{{- with .SQL.InsertTime }}
+	now := t.now()
+	{{- range . }}
+	m.{{.Ident}} = {{.Value}}
+	{{- end }}
+	{{- end }}
+	{{- if .SQL.Returning }}
+	if err := d.QueryRowContext(ctx, "INSERT INTO {{.SQL.Table}} ({{.SQL.DBNames}}) VALUES ({{.SQL.Placeholders}}){{.SQL.Returning}};", {{.SQL.Idents}}).Scan({{.SQL.ReturningIdentRefs}}); err != nil {
+		return err
+	}
+	{{- else }}
+	_, err := d.ExecContext(ctx, "INSERT INTO {{.SQL.Table}} ({{.SQL.DBNames}}) VALUES ({{.SQL.Placeholders}});", {{.SQL.Idents}})
 	if err != nil {
 		return err
 	}
+	{{- end }}
 	return nil
 }
 
 func (t *{{.Prefix}}ModelTable) InsertBulk(ctx context.Context, d sqldb.Executor, models []*{{.ModelIdent}}, allowConflict bool) error {
 	conflictSQL := ""
 	if allowConflict {
-		conflictSQL = " ON CONFLICT DO NOTHING"
+		conflictSQL = "{{.SQL.OnConflictDoNothing}}"
 	}
 	placeholders := make([]string, 0, len(models))
 	args := make([]interface{}, 0, len(models)*{{.SQL.ColNum}})
+	{{- if .SQL.InsertTime }}
+	now := t.now()
+	{{- end }}
+	{{- if .SQL.Positional }}
+	for _, m := range models {
+		{{- range .SQL.InsertTime }}
+		m.{{.Ident}} = {{.Value}}
+		{{- end }}
+		placeholders = append(placeholders, "({{.SQL.PlaceholderTpl}})")
+		args = append(args, {{.SQL.Idents}})
+	}
+	{{- else }}
 	for c, m := range models {
+		{{- range .SQL.InsertTime }}
+		m.{{.Ident}} = {{.Value}}
+		{{- end }}
 		n := c * {{.SQL.ColNum}}
 		placeholders = append(placeholders, fmt.Sprintf("({{.SQL.PlaceholderTpl}})", {{.SQL.PlaceholderCount}}))
 		args = append(args, {{.SQL.Idents}})
 	}
-	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" ({{.SQL.DBNames}}) VALUES "+strings.Join(placeholders, ", ")+conflictSQL+";", args...)
+	{{- end }}
+	_, err := d.ExecContext(ctx, "INSERT INTO {{.SQL.Table}} ({{.SQL.DBNames}}) VALUES "+strings.Join(placeholders, ", ")+conflictSQL+";", args...)
 	if err != nil {
 		return err
 	}
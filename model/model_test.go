@@ -20,10 +20,11 @@ func TestGenerate(t *testing.T) {
 	var filemode fs.FileMode = 0o644
 
 	for _, tc := range []struct {
-		Name   string
-		Fsys   fs.FS
-		Output map[string]string
-		Err    error
+		Name    string
+		Dialect Dialect
+		Fsys    fs.FS
+		Output  map[string]string
+		Err     error
 	}{
 		{
 			Name: "parses directives from files",
@@ -478,22 +479,89 @@ func (t *smModelTable) GetSMManyCond(ctx context.Context, d sqldb.Executor, user
 			},
 		},
 		{
-			Name: "errors on wrong package",
-			Fsys: fstest.MapFS{
-				"stuff.go": &fstest.MapFile{
-					Data: []byte(`package different
-`),
-					Mode:    filemode,
-					ModTime: now,
-				},
-			},
-			Err: ErrEnv,
-		},
-		{
-			Name: "errors on invalid schema file",
+			Name:    "generates sqlite dialect",
+			Dialect: DialectSQLite{},
 			Fsys: fstest.MapFS{
 				"model.json": &fstest.MapFile{
-					Data:    []byte(`"bogus"`),
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "model": {
+        "constraints": [
+          {
+            "kind": "UNIQUE",
+            "columns": ["username", "first_name"]
+          }
+        ],
+        "indicies": [
+          {
+            "name": "names",
+            "columns": [{"col": "first_name"}, {"col": "username", "dir": "DESC"}]
+          }
+        ]
+      },
+      "queries": {
+        "Model": [
+          {
+            "kind": "getoneeq",
+            "name": "ByID",
+            "conditions": [
+              {"col": "userid"}
+            ]
+          },
+          {
+            "kind": "deleq",
+            "name": "ByIDs",
+            "conditions": [
+              {"col": "userid", "cond": "in"}
+            ]
+          }
+        ],
+        "userProps": [
+          {
+            "kind": "updeq",
+            "name": "ByID",
+            "conditions": [
+              {"col": "userid"}
+            ]
+          }
+        ],
+        "usernameProps": [
+          {
+            "kind": "updeq",
+            "name": "ByIDs",
+            "conditions": [
+              {"col": "userid", "cond": "in"},
+              {"col": "first_name", "cond": "neq"}
+            ]
+          }
+        ],
+        "Info": [
+          {
+            "kind": "getgroup",
+            "name": "All",
+            "order": [
+              {"col": "userid", "dir": "DESC"}
+            ]
+          },
+          {
+            "kind": "getgroupeq",
+            "name": "ByIDs",
+            "conditions": [
+              {"col": "userid", "cond": "in"},
+              {"col": "username", "cond": "like"}
+            ],
+            "order": [
+              {"col": "userid"}
+            ]
+          }
+        ]
+      }
+    }
+  }
+}
+`),
 					Mode:    filemode,
 					ModTime: now,
 				},
@@ -502,75 +570,28 @@ func (t *smModelTable) GetSMManyCond(ctx context.Context, d sqldb.Executor, user
 
 type (
 	//forge:model user
+	//forge:model:query user
 	Model struct {
-		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Userid    string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username  string ` + "`" + `model:"username,VARCHAR(255) NOT NULL UNIQUE"` + "`" + `
+		FirstName string ` + "`" + `model:"first_name,VARCHAR(255) NOT NULL"` + "`" + `
 	}
-)
-`),
-					Mode:    filemode,
-					ModTime: now,
-				},
-			},
-			Err: ErrInvalidSchema,
-		},
-		{
-			Name: "errors on no models",
-			Fsys: fstest.MapFS{
-				"stuff.go": &fstest.MapFile{
-					Data: []byte(`package somepackage
-`),
-					Mode:    filemode,
-					ModTime: now,
-				},
-			},
-			Err: ErrInvalidFile,
-		},
-		{
-			Name: "errors on model directive on non-typedef",
-			Fsys: fstest.MapFS{
-				"stuff.go": &fstest.MapFile{
-					Data: []byte(`package somepackage
 
-//forge:model user
-const (
-	foo = "bar"
-)
-`),
-					Mode:    filemode,
-					ModTime: now,
-				},
-			},
-			Err: ErrInvalidFile,
-		},
-		{
-			Name: "errors on model directive without prefix arg",
-			Fsys: fstest.MapFS{
-				"stuff.go": &fstest.MapFile{
-					Data: []byte(`package somepackage
+	//forge:model:query user
+	userProps struct {
+		Username  string ` + "`" + `model:"username"` + "`" + `
+		FirstName string ` + "`" + `model:"first_name"` + "`" + `
+	}
 
-type (
-	//forge:model
-	Model struct {
-		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+	//forge:model:query user
+	usernameProps struct {
+		Username string ` + "`" + `model:"username"` + "`" + `
 	}
-)
-`),
-					Mode:    filemode,
-					ModTime: now,
-				},
-			},
-			Err: ErrInvalidFile,
-		},
-		{
-			Name: "errors on model tag on multiple fields",
-			Fsys: fstest.MapFS{
-				"stuff.go": &fstest.MapFile{
-					Data: []byte(`package somepackage
 
-type (
-	//forge:model user
-	Model struct {
-		Userid, Other string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+	//forge:model:query user
+	Info struct {
+		Userid   string ` + "`" + `model:"userid"` + "`" + `
+		Username string ` + "`" + `model:"username"` + "`" + `
 	}
 )
 `),
@@ -578,95 +599,15874 @@ type (
 					ModTime: now,
 				},
 			},
-			Err: ErrInvalidFile,
-		},
-		{
-			Name: "errors on malformed model tag",
-			Fsys: fstest.MapFS{
-				"stuff.go": &fstest.MapFile{
-					Data: []byte(`package somepackage
+			Output: map[string]string{
+				"model_gen.go": `// Code generated by go generate forge model dev; DO NOT EDIT.
 
-type (
-	//forge:model user
-	Model struct {
-		Userid string ` + "`" + `model:""` + "`" + `
-	}
+package somepackage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"xorkevin.dev/forge/model/sqldb"
 )
-`),
-					Mode:    filemode,
-					ModTime: now,
-				},
-			},
-			Err: ErrInvalidModel,
-		},
-		{
-			Name: "errors on no model tags",
-			Fsys: fstest.MapFS{
-				"stuff.go": &fstest.MapFile{
-					Data: []byte(`package somepackage
 
 type (
-	//forge:model user
-	Model struct {
-		Userid string
+	userModelTable struct {
+		TableName string
 	}
 )
-`),
-					Mode:    filemode,
-					ModTime: now,
-				},
-			},
-			Err: ErrInvalidModel,
-		},
-		{
-			Name: "errors on model directive on non-struct",
-			Fsys: fstest.MapFS{
-				"stuff.go": &fstest.MapFile{
-					Data: []byte(`package somepackage
 
-type (
-	//forge:model user
-	Model []string
-)
-`),
-					Mode:    filemode,
-					ModTime: now,
-				},
-			},
-			Err: ErrInvalidFile,
-		},
-		{
-			Name: "errors on duplicate model field",
-			Fsys: fstest.MapFS{
-				"stuff.go": &fstest.MapFile{
-					Data: []byte(`package somepackage
+func (t *userModelTable) Setup(ctx context.Context, d sqldb.Executor) error {
+	_, err := d.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+t.TableName+" (userid VARCHAR(31) PRIMARY KEY, username VARCHAR(255) NOT NULL UNIQUE, first_name VARCHAR(255) NOT NULL, UNIQUE (username, first_name));")
+	if err != nil {
+		return err
+	}
+	_, err = d.ExecContext(ctx, "CREATE INDEX IF NOT EXISTS "+t.TableName+"_names_index ON "+t.TableName+" (first_name, username DESC);")
+	if err != nil {
+		return err
+	}
+	return nil
+}
 
-type (
-	//forge:model user
-	Model struct {
-		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
-		Username string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+func (t *userModelTable) Insert(ctx context.Context, d sqldb.Executor, m *Model) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (userid, username, first_name) VALUES (?1, ?2, ?3);", m.Userid, m.Username, m.FirstName)
+	if err != nil {
+		return err
 	}
-)
-`),
-					Mode:    filemode,
-					ModTime: now,
+	return nil
+}
+
+func (t *userModelTable) InsertBulk(ctx context.Context, d sqldb.Executor, models []*Model, allowConflict bool) error {
+	conflictSQL := ""
+	if allowConflict {
+		conflictSQL = " ON CONFLICT DO NOTHING"
+	}
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*3)
+	for c, m := range models {
+		n := c * 3
+		placeholders = append(placeholders, fmt.Sprintf("(?%d, ?%d, ?%d)", n+1, n+2, n+3))
+		args = append(args, m.Userid, m.Username, m.FirstName)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (userid, username, first_name) VALUES "+strings.Join(placeholders, ", ")+conflictSQL+";", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) GetModelByID(ctx context.Context, d sqldb.Executor, userid string) (*Model, error) {
+	m := &Model{}
+	if err := d.QueryRowContext(ctx, "SELECT userid, username, first_name FROM "+t.TableName+" WHERE userid = ?1;", userid).Scan(&m.Userid, &m.Username, &m.FirstName); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (t *userModelTable) DelByIDs(ctx context.Context, d sqldb.Executor, userids []string) error {
+	paramCount := 0
+	args := make([]interface{}, 0, paramCount+len(userids))
+	var placeholdersuserids string
+	{
+		placeholders := make([]string, 0, len(userids))
+		for _, i := range userids {
+			paramCount++
+			placeholders = append(placeholders, fmt.Sprintf("?%d", paramCount))
+			args = append(args, i)
+		}
+		placeholdersuserids = strings.Join(placeholders, ", ")
+	}
+	_, err := d.ExecContext(ctx, "DELETE FROM "+t.TableName+" WHERE userid IN ("+placeholdersuserids+");", args...)
+	return err
+}
+
+func (t *userModelTable) UpduserPropsByID(ctx context.Context, d sqldb.Executor, m *userProps, userid string) error {
+	_, err := d.ExecContext(ctx, "UPDATE "+t.TableName+" SET username = ?1, first_name = ?2 WHERE userid = ?3;", m.Username, m.FirstName, userid)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) UpdusernamePropsByIDs(ctx context.Context, d sqldb.Executor, m *usernameProps, userids []string, firstname string) error {
+	paramCount := 2
+	args := make([]interface{}, 0, paramCount+len(userids))
+	args = append(args, m.Username, firstname)
+	var placeholdersuserids string
+	{
+		placeholders := make([]string, 0, len(userids))
+		for _, i := range userids {
+			paramCount++
+			placeholders = append(placeholders, fmt.Sprintf("?%d", paramCount))
+			args = append(args, i)
+		}
+		placeholdersuserids = strings.Join(placeholders, ", ")
+	}
+	_, err := d.ExecContext(ctx, "UPDATE "+t.TableName+" SET username = ?1 WHERE userid IN ("+placeholdersuserids+") AND first_name <> ?2;", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) GetInfoAll(ctx context.Context, d sqldb.Executor, limit, offset int) (_ []Info, retErr error) {
+	res := make([]Info, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT userid, username FROM "+t.TableName+" ORDER BY userid DESC LIMIT ?1 OFFSET ?2;", limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Info
+		if err := rows.Scan(&m.Userid, &m.Username); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *userModelTable) GetInfoByIDs(ctx context.Context, d sqldb.Executor, userids []string, usernamePrefix string, limit, offset int) (_ []Info, retErr error) {
+	paramCount := 3
+	args := make([]interface{}, 0, paramCount+len(userids))
+	args = append(args, limit, offset, usernamePrefix)
+	var placeholdersuserids string
+	{
+		placeholders := make([]string, 0, len(userids))
+		for _, i := range userids {
+			paramCount++
+			placeholders = append(placeholders, fmt.Sprintf("?%d", paramCount))
+			args = append(args, i)
+		}
+		placeholdersuserids = strings.Join(placeholders, ", ")
+	}
+	res := make([]Info, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT userid, username FROM "+t.TableName+" WHERE userid IN ("+placeholdersuserids+") AND username LIKE ?3 ORDER BY userid LIMIT ?1 OFFSET ?2;", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Info
+		if err := rows.Scan(&m.Userid, &m.Username); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+`,
+			},
+		},
+
+		{
+			Name: "errors on wrong package",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package different
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrEnv,
+		},
+		{
+			Name:    "generates mysql dialect",
+			Dialect: DialectMySQL{},
+			Fsys: fstest.MapFS{
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "model": {
+        "constraints": [
+          {
+            "kind": "UNIQUE",
+            "columns": ["username", "first_name"]
+          }
+        ],
+        "indicies": [
+          {
+            "name": "names",
+            "columns": [{"col": "first_name"}, {"col": "username", "dir": "DESC"}]
+          }
+        ]
+      },
+      "queries": {
+        "Model": [
+          {
+            "kind": "getoneeq",
+            "name": "ByID",
+            "conditions": [
+              {"col": "userid"}
+            ]
+          },
+          {
+            "kind": "deleq",
+            "name": "ByIDs",
+            "conditions": [
+              {"col": "userid", "cond": "in"}
+            ]
+          }
+        ],
+        "userProps": [
+          {
+            "kind": "updeq",
+            "name": "ByID",
+            "conditions": [
+              {"col": "userid"}
+            ]
+          }
+        ],
+        "usernameProps": [
+          {
+            "kind": "updeq",
+            "name": "ByIDs",
+            "conditions": [
+              {"col": "userid", "cond": "in"},
+              {"col": "first_name", "cond": "neq"}
+            ]
+          }
+        ],
+        "Info": [
+          {
+            "kind": "getgroup",
+            "name": "All",
+            "order": [
+              {"col": "userid", "dir": "DESC"}
+            ]
+          },
+          {
+            "kind": "getgroupeq",
+            "name": "ByIDs",
+            "conditions": [
+              {"col": "userid", "cond": "in"},
+              {"col": "username", "cond": "like"}
+            ],
+            "order": [
+              {"col": "userid"}
+            ]
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid    string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username  string ` + "`" + `model:"username,VARCHAR(255) NOT NULL UNIQUE"` + "`" + `
+		FirstName string ` + "`" + `model:"first_name,VARCHAR(255) NOT NULL"` + "`" + `
+	}
+
+	//forge:model:query user
+	userProps struct {
+		Username  string ` + "`" + `model:"username"` + "`" + `
+		FirstName string ` + "`" + `model:"first_name"` + "`" + `
+	}
+
+	//forge:model:query user
+	usernameProps struct {
+		Username string ` + "`" + `model:"username"` + "`" + `
+	}
+
+	//forge:model:query user
+	Info struct {
+		Userid   string ` + "`" + `model:"userid"` + "`" + `
+		Username string ` + "`" + `model:"username"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Output: map[string]string{
+				"model_gen.go": `// Code generated by go generate forge model dev; DO NOT EDIT.
+
+package somepackage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"xorkevin.dev/forge/model/sqldb"
+)
+
+type (
+	userModelTable struct {
+		TableName string
+	}
+)
+
+func (t *userModelTable) Setup(ctx context.Context, d sqldb.Executor) error {
+	_, err := d.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `userid` + "`" + ` VARCHAR(31) PRIMARY KEY, ` + "`" + `username` + "`" + ` VARCHAR(255) NOT NULL UNIQUE, ` + "`" + `first_name` + "`" + ` VARCHAR(255) NOT NULL, UNIQUE (` + "`" + `username` + "`" + `, ` + "`" + `first_name` + "`" + `), INDEX ` + "`" + `"+t.TableName+"_names_index` + "`" + ` (` + "`" + `first_name` + "`" + `, ` + "`" + `username` + "`" + ` DESC));")
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) Insert(ctx context.Context, d sqldb.Executor, m *Model) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `userid` + "`" + `, ` + "`" + `username` + "`" + `, ` + "`" + `first_name` + "`" + `) VALUES (?, ?, ?);", m.Userid, m.Username, m.FirstName)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) InsertBulk(ctx context.Context, d sqldb.Executor, models []*Model, allowConflict bool) error {
+	conflictSQL := ""
+	if allowConflict {
+		conflictSQL = " ON DUPLICATE KEY UPDATE ` + "`" + `userid` + "`" + ` = ` + "`" + `userid` + "`" + `"
+	}
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*3)
+	for _, m := range models {
+		placeholders = append(placeholders, "(?, ?, ?)")
+		args = append(args, m.Userid, m.Username, m.FirstName)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `userid` + "`" + `, ` + "`" + `username` + "`" + `, ` + "`" + `first_name` + "`" + `) VALUES "+strings.Join(placeholders, ", ")+conflictSQL+";", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) GetModelByID(ctx context.Context, d sqldb.Executor, userid string) (*Model, error) {
+	m := &Model{}
+	if err := d.QueryRowContext(ctx, "SELECT ` + "`" + `userid` + "`" + `, ` + "`" + `username` + "`" + `, ` + "`" + `first_name` + "`" + ` FROM ` + "`" + `"+t.TableName+"` + "`" + ` WHERE ` + "`" + `userid` + "`" + ` = ?;", userid).Scan(&m.Userid, &m.Username, &m.FirstName); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (t *userModelTable) DelByIDs(ctx context.Context, d sqldb.Executor, userids []string) error {
+	paramCount := 0
+	args := make([]interface{}, 0, paramCount+len(userids))
+	var placeholdersuserids string
+	{
+		placeholders := make([]string, 0, len(userids))
+		for _, i := range userids {
+			placeholders = append(placeholders, "?")
+			args = append(args, i)
+		}
+		placeholdersuserids = strings.Join(placeholders, ", ")
+	}
+	_, err := d.ExecContext(ctx, "DELETE FROM ` + "`" + `"+t.TableName+"` + "`" + ` WHERE ` + "`" + `userid` + "`" + ` IN ("+placeholdersuserids+");", args...)
+	return err
+}
+
+func (t *userModelTable) UpduserPropsByID(ctx context.Context, d sqldb.Executor, m *userProps, userid string) error {
+	_, err := d.ExecContext(ctx, "UPDATE ` + "`" + `"+t.TableName+"` + "`" + ` SET ` + "`" + `username` + "`" + ` = ?, ` + "`" + `first_name` + "`" + ` = ? WHERE ` + "`" + `userid` + "`" + ` = ?;", m.Username, m.FirstName, userid)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) UpdusernamePropsByIDs(ctx context.Context, d sqldb.Executor, m *usernameProps, userids []string, firstname string) error {
+	paramCount := 2
+	args := make([]interface{}, 0, paramCount+len(userids))
+	args = append(args, m.Username)
+	var placeholdersuserids string
+	{
+		placeholders := make([]string, 0, len(userids))
+		for _, i := range userids {
+			placeholders = append(placeholders, "?")
+			args = append(args, i)
+		}
+		placeholdersuserids = strings.Join(placeholders, ", ")
+	}
+	args = append(args, firstname)
+	_, err := d.ExecContext(ctx, "UPDATE ` + "`" + `"+t.TableName+"` + "`" + ` SET ` + "`" + `username` + "`" + ` = ? WHERE ` + "`" + `userid` + "`" + ` IN ("+placeholdersuserids+") AND ` + "`" + `first_name` + "`" + ` <> ?;", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) GetInfoAll(ctx context.Context, d sqldb.Executor, limit, offset int) (_ []Info, retErr error) {
+	res := make([]Info, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT ` + "`" + `userid` + "`" + `, ` + "`" + `username` + "`" + ` FROM ` + "`" + `"+t.TableName+"` + "`" + ` ORDER BY ` + "`" + `userid` + "`" + ` DESC LIMIT ? OFFSET ?;", limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Info
+		if err := rows.Scan(&m.Userid, &m.Username); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *userModelTable) GetInfoByIDs(ctx context.Context, d sqldb.Executor, userids []string, usernamePrefix string, limit, offset int) (_ []Info, retErr error) {
+	paramCount := 3
+	args := make([]interface{}, 0, paramCount+len(userids))
+	var placeholdersuserids string
+	{
+		placeholders := make([]string, 0, len(userids))
+		for _, i := range userids {
+			placeholders = append(placeholders, "?")
+			args = append(args, i)
+		}
+		placeholdersuserids = strings.Join(placeholders, ", ")
+	}
+	args = append(args, usernamePrefix, limit, offset)
+	res := make([]Info, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT ` + "`" + `userid` + "`" + `, ` + "`" + `username` + "`" + ` FROM ` + "`" + `"+t.TableName+"` + "`" + ` WHERE ` + "`" + `userid` + "`" + ` IN ("+placeholdersuserids+") AND ` + "`" + `username` + "`" + ` LIKE ? ORDER BY ` + "`" + `userid` + "`" + ` LIMIT ? OFFSET ?;", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Info
+		if err := rows.Scan(&m.Userid, &m.Username); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+`,
+			},
+		},
+
+		{
+			Name:    "generates custom dialect",
+			Dialect: testBracketDialect{},
+			Fsys: fstest.MapFS{
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "model": {
+        "indicies": [
+          {
+            "name": "username",
+            "columns": [{"col": "username"}]
+          }
+        ]
+      },
+      "queries": {
+        "Model": [
+          {
+            "kind": "getgroupeq",
+            "name": "ByIDs",
+            "conditions": [
+              {"col": "userid", "cond": "in"}
+            ],
+            "order": [
+              {"col": "userid"}
+            ]
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Output: map[string]string{
+				"model_gen.go": `// Code generated by go generate forge model dev; DO NOT EDIT.
+
+package somepackage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"xorkevin.dev/forge/model/sqldb"
+)
+
+type (
+	userModelTable struct {
+		TableName string
+	}
+)
+
+func (t *userModelTable) Setup(ctx context.Context, d sqldb.Executor) error {
+	_, err := d.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS ["+t.TableName+"] ([userid] VARCHAR(31) PRIMARY KEY, [username] VARCHAR(255) NOT NULL);")
+	if err != nil {
+		return err
+	}
+	_, err = d.ExecContext(ctx, "CREATE INDEX IF NOT EXISTS ["+t.TableName+"_username_index] ON ["+t.TableName+"] ([username]);")
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) Insert(ctx context.Context, d sqldb.Executor, m *Model) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO ["+t.TableName+"] ([userid], [username]) VALUES (:1, :2);", m.Userid, m.Username)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) InsertBulk(ctx context.Context, d sqldb.Executor, models []*Model, allowConflict bool) error {
+	conflictSQL := ""
+	if allowConflict {
+		conflictSQL = " ON CONFLICT DO NOTHING"
+	}
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*2)
+	for c, m := range models {
+		n := c * 2
+		placeholders = append(placeholders, fmt.Sprintf("(:%d, :%d)", n+1, n+2))
+		args = append(args, m.Userid, m.Username)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO ["+t.TableName+"] ([userid], [username]) VALUES "+strings.Join(placeholders, ", ")+conflictSQL+";", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) GetModelByIDs(ctx context.Context, d sqldb.Executor, userids []string, limit, offset int) (_ []Model, retErr error) {
+	paramCount := 2
+	args := make([]interface{}, 0, paramCount+len(userids))
+	args = append(args, limit, offset)
+	var placeholdersuserids string
+	{
+		placeholders := make([]string, 0, len(userids))
+		for _, i := range userids {
+			paramCount++
+			placeholders = append(placeholders, fmt.Sprintf(":%d", paramCount))
+			args = append(args, i)
+		}
+		placeholdersuserids = strings.Join(placeholders, ", ")
+	}
+	res := make([]Model, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT [userid], [username] FROM ["+t.TableName+"] WHERE [userid] IN ("+placeholdersuserids+") ORDER BY [userid] LIMIT :1 OFFSET :2;", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Model
+		if err := rows.Scan(&m.Userid, &m.Username); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+`,
+			},
+		},
+
+		{
+			Name:    "omits unused imports",
+			Dialect: DialectMySQL{},
+			Fsys: fstest.MapFS{
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "getoneeq",
+            "name": "ByID",
+            "conditions": [
+              {"col": "userid"}
+            ]
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Output: map[string]string{
+				"model_gen.go": `// Code generated by go generate forge model dev; DO NOT EDIT.
+
+package somepackage
+
+import (
+	"context"
+	"strings"
+
+	"xorkevin.dev/forge/model/sqldb"
+)
+
+type (
+	userModelTable struct {
+		TableName string
+	}
+)
+
+func (t *userModelTable) Setup(ctx context.Context, d sqldb.Executor) error {
+	_, err := d.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `userid` + "`" + ` VARCHAR(31) PRIMARY KEY, ` + "`" + `username` + "`" + ` VARCHAR(255) NOT NULL);")
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) Insert(ctx context.Context, d sqldb.Executor, m *Model) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `userid` + "`" + `, ` + "`" + `username` + "`" + `) VALUES (?, ?);", m.Userid, m.Username)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) InsertBulk(ctx context.Context, d sqldb.Executor, models []*Model, allowConflict bool) error {
+	conflictSQL := ""
+	if allowConflict {
+		conflictSQL = " ON DUPLICATE KEY UPDATE ` + "`" + `userid` + "`" + ` = ` + "`" + `userid` + "`" + `"
+	}
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*2)
+	for _, m := range models {
+		placeholders = append(placeholders, "(?, ?)")
+		args = append(args, m.Userid, m.Username)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `userid` + "`" + `, ` + "`" + `username` + "`" + `) VALUES "+strings.Join(placeholders, ", ")+conflictSQL+";", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) GetModelByID(ctx context.Context, d sqldb.Executor, userid string) (*Model, error) {
+	m := &Model{}
+	if err := d.QueryRowContext(ctx, "SELECT ` + "`" + `userid` + "`" + `, ` + "`" + `username` + "`" + ` FROM ` + "`" + `"+t.TableName+"` + "`" + ` WHERE ` + "`" + `userid` + "`" + ` = ?;", userid).Scan(&m.Userid, &m.Username); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+`,
+			},
+		},
+
+		{
+			Name: "generates upsert queries",
+			Fsys: fstest.MapFS{
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "upsert",
+            "name": "ByID",
+            "conflict": ["userid"]
+          },
+          {
+            "kind": "upsert",
+            "name": "Ignore",
+            "conflict": ["userid"],
+            "update": []
+          }
+        ],
+        "Props": [
+          {
+            "kind": "upsert",
+            "name": "Props",
+            "conflict": ["userid"],
+            "update": ["username"]
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+		Email    string ` + "`" + `model:"email,VARCHAR(255) NOT NULL"` + "`" + `
+	}
+
+	//forge:model:query user
+	Props struct {
+		Userid   string ` + "`" + `model:"userid"` + "`" + `
+		Username string ` + "`" + `model:"username"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Output: map[string]string{
+				"model_gen.go": `// Code generated by go generate forge model dev; DO NOT EDIT.
+
+package somepackage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"xorkevin.dev/forge/model/sqldb"
+)
+
+type (
+	userModelTable struct {
+		TableName string
+	}
+)
+
+func (t *userModelTable) Setup(ctx context.Context, d sqldb.Executor) error {
+	_, err := d.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+t.TableName+" (userid VARCHAR(31) PRIMARY KEY, username VARCHAR(255) NOT NULL, email VARCHAR(255) NOT NULL);")
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) Insert(ctx context.Context, d sqldb.Executor, m *Model) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (userid, username, email) VALUES ($1, $2, $3);", m.Userid, m.Username, m.Email)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) InsertBulk(ctx context.Context, d sqldb.Executor, models []*Model, allowConflict bool) error {
+	conflictSQL := ""
+	if allowConflict {
+		conflictSQL = " ON CONFLICT DO NOTHING"
+	}
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*3)
+	for c, m := range models {
+		n := c * 3
+		placeholders = append(placeholders, fmt.Sprintf("($%d, $%d, $%d)", n+1, n+2, n+3))
+		args = append(args, m.Userid, m.Username, m.Email)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (userid, username, email) VALUES "+strings.Join(placeholders, ", ")+conflictSQL+";", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) UpsertModelByID(ctx context.Context, d sqldb.Executor, m *Model) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (userid, username, email) VALUES ($1, $2, $3) ON CONFLICT (userid) DO UPDATE SET username = EXCLUDED.username, email = EXCLUDED.email;", m.Userid, m.Username, m.Email)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) UpsertModelByIDBulk(ctx context.Context, d sqldb.Executor, models []*Model) error {
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*3)
+	for c, m := range models {
+		n := c * 3
+		placeholders = append(placeholders, fmt.Sprintf("($%d, $%d, $%d)", n+1, n+2, n+3))
+		args = append(args, m.Userid, m.Username, m.Email)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (userid, username, email) VALUES "+strings.Join(placeholders, ", ")+" ON CONFLICT (userid) DO UPDATE SET username = EXCLUDED.username, email = EXCLUDED.email;", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) UpsertModelIgnore(ctx context.Context, d sqldb.Executor, m *Model) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (userid, username, email) VALUES ($1, $2, $3) ON CONFLICT (userid) DO NOTHING;", m.Userid, m.Username, m.Email)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) UpsertModelIgnoreBulk(ctx context.Context, d sqldb.Executor, models []*Model) error {
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*3)
+	for c, m := range models {
+		n := c * 3
+		placeholders = append(placeholders, fmt.Sprintf("($%d, $%d, $%d)", n+1, n+2, n+3))
+		args = append(args, m.Userid, m.Username, m.Email)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (userid, username, email) VALUES "+strings.Join(placeholders, ", ")+" ON CONFLICT (userid) DO NOTHING;", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) UpsertPropsProps(ctx context.Context, d sqldb.Executor, m *Props) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (userid, username) VALUES ($1, $2) ON CONFLICT (userid) DO UPDATE SET username = EXCLUDED.username;", m.Userid, m.Username)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) UpsertPropsPropsBulk(ctx context.Context, d sqldb.Executor, models []*Props) error {
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*2)
+	for c, m := range models {
+		n := c * 2
+		placeholders = append(placeholders, fmt.Sprintf("($%d, $%d)", n+1, n+2))
+		args = append(args, m.Userid, m.Username)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (userid, username) VALUES "+strings.Join(placeholders, ", ")+" ON CONFLICT (userid) DO UPDATE SET username = EXCLUDED.username;", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+`,
+			},
+		},
+
+		{
+			Name:    "generates mysql upsert queries",
+			Dialect: DialectMySQL{},
+			Fsys: fstest.MapFS{
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "upsert",
+            "name": "ByID",
+            "conflict": ["userid"]
+          },
+          {
+            "kind": "upsert",
+            "name": "Ignore",
+            "conflict": ["userid"],
+            "update": []
+          }
+        ],
+        "Props": [
+          {
+            "kind": "upsert",
+            "name": "Props",
+            "conflict": ["userid"],
+            "update": ["username"]
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+		Email    string ` + "`" + `model:"email,VARCHAR(255) NOT NULL"` + "`" + `
+	}
+
+	//forge:model:query user
+	Props struct {
+		Userid   string ` + "`" + `model:"userid"` + "`" + `
+		Username string ` + "`" + `model:"username"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Output: map[string]string{
+				"model_gen.go": `// Code generated by go generate forge model dev; DO NOT EDIT.
+
+package somepackage
+
+import (
+	"context"
+	"strings"
+
+	"xorkevin.dev/forge/model/sqldb"
+)
+
+type (
+	userModelTable struct {
+		TableName string
+	}
+)
+
+func (t *userModelTable) Setup(ctx context.Context, d sqldb.Executor) error {
+	_, err := d.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `userid` + "`" + ` VARCHAR(31) PRIMARY KEY, ` + "`" + `username` + "`" + ` VARCHAR(255) NOT NULL, ` + "`" + `email` + "`" + ` VARCHAR(255) NOT NULL);")
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) Insert(ctx context.Context, d sqldb.Executor, m *Model) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `userid` + "`" + `, ` + "`" + `username` + "`" + `, ` + "`" + `email` + "`" + `) VALUES (?, ?, ?);", m.Userid, m.Username, m.Email)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) InsertBulk(ctx context.Context, d sqldb.Executor, models []*Model, allowConflict bool) error {
+	conflictSQL := ""
+	if allowConflict {
+		conflictSQL = " ON DUPLICATE KEY UPDATE ` + "`" + `userid` + "`" + ` = ` + "`" + `userid` + "`" + `"
+	}
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*3)
+	for _, m := range models {
+		placeholders = append(placeholders, "(?, ?, ?)")
+		args = append(args, m.Userid, m.Username, m.Email)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `userid` + "`" + `, ` + "`" + `username` + "`" + `, ` + "`" + `email` + "`" + `) VALUES "+strings.Join(placeholders, ", ")+conflictSQL+";", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) UpsertModelByID(ctx context.Context, d sqldb.Executor, m *Model) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `userid` + "`" + `, ` + "`" + `username` + "`" + `, ` + "`" + `email` + "`" + `) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE ` + "`" + `username` + "`" + ` = VALUES(` + "`" + `username` + "`" + `), ` + "`" + `email` + "`" + ` = VALUES(` + "`" + `email` + "`" + `);", m.Userid, m.Username, m.Email)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) UpsertModelByIDBulk(ctx context.Context, d sqldb.Executor, models []*Model) error {
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*3)
+	for _, m := range models {
+		placeholders = append(placeholders, "(?, ?, ?)")
+		args = append(args, m.Userid, m.Username, m.Email)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `userid` + "`" + `, ` + "`" + `username` + "`" + `, ` + "`" + `email` + "`" + `) VALUES "+strings.Join(placeholders, ", ")+" ON DUPLICATE KEY UPDATE ` + "`" + `username` + "`" + ` = VALUES(` + "`" + `username` + "`" + `), ` + "`" + `email` + "`" + ` = VALUES(` + "`" + `email` + "`" + `);", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) UpsertModelIgnore(ctx context.Context, d sqldb.Executor, m *Model) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `userid` + "`" + `, ` + "`" + `username` + "`" + `, ` + "`" + `email` + "`" + `) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE ` + "`" + `userid` + "`" + ` = ` + "`" + `userid` + "`" + `;", m.Userid, m.Username, m.Email)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) UpsertModelIgnoreBulk(ctx context.Context, d sqldb.Executor, models []*Model) error {
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*3)
+	for _, m := range models {
+		placeholders = append(placeholders, "(?, ?, ?)")
+		args = append(args, m.Userid, m.Username, m.Email)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `userid` + "`" + `, ` + "`" + `username` + "`" + `, ` + "`" + `email` + "`" + `) VALUES "+strings.Join(placeholders, ", ")+" ON DUPLICATE KEY UPDATE ` + "`" + `userid` + "`" + ` = ` + "`" + `userid` + "`" + `;", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) UpsertPropsProps(ctx context.Context, d sqldb.Executor, m *Props) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `userid` + "`" + `, ` + "`" + `username` + "`" + `) VALUES (?, ?) ON DUPLICATE KEY UPDATE ` + "`" + `username` + "`" + ` = VALUES(` + "`" + `username` + "`" + `);", m.Userid, m.Username)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) UpsertPropsPropsBulk(ctx context.Context, d sqldb.Executor, models []*Props) error {
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*2)
+	for _, m := range models {
+		placeholders = append(placeholders, "(?, ?)")
+		args = append(args, m.Userid, m.Username)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `userid` + "`" + `, ` + "`" + `username` + "`" + `) VALUES "+strings.Join(placeholders, ", ")+" ON DUPLICATE KEY UPDATE ` + "`" + `username` + "`" + ` = VALUES(` + "`" + `username` + "`" + `);", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+`,
+			},
+		},
+		{
+			Name: "generates count and exists queries",
+			Fsys: fstest.MapFS{
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "count",
+            "name": "All"
+          },
+          {
+            "kind": "counteq",
+            "name": "ByUsernamePrefix",
+            "conditions": [
+              {"col": "userid", "cond": "in"},
+              {"col": "username", "cond": "like"}
+            ]
+          },
+          {
+            "kind": "existseq",
+            "name": "ByEmail",
+            "conditions": [
+              {"col": "email"}
+            ]
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+		Email    string ` + "`" + `model:"email,VARCHAR(255) NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Output: map[string]string{
+				"model_gen.go": `// Code generated by go generate forge model dev; DO NOT EDIT.
+
+package somepackage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"xorkevin.dev/forge/model/sqldb"
+)
+
+type (
+	userModelTable struct {
+		TableName string
+	}
+)
+
+func (t *userModelTable) Setup(ctx context.Context, d sqldb.Executor) error {
+	_, err := d.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+t.TableName+" (userid VARCHAR(31) PRIMARY KEY, username VARCHAR(255) NOT NULL, email VARCHAR(255) NOT NULL);")
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) Insert(ctx context.Context, d sqldb.Executor, m *Model) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (userid, username, email) VALUES ($1, $2, $3);", m.Userid, m.Username, m.Email)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) InsertBulk(ctx context.Context, d sqldb.Executor, models []*Model, allowConflict bool) error {
+	conflictSQL := ""
+	if allowConflict {
+		conflictSQL = " ON CONFLICT DO NOTHING"
+	}
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*3)
+	for c, m := range models {
+		n := c * 3
+		placeholders = append(placeholders, fmt.Sprintf("($%d, $%d, $%d)", n+1, n+2, n+3))
+		args = append(args, m.Userid, m.Username, m.Email)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (userid, username, email) VALUES "+strings.Join(placeholders, ", ")+conflictSQL+";", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) CountModelAll(ctx context.Context, d sqldb.Executor) (int, error) {
+	var count int
+	if err := d.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+t.TableName+";").Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (t *userModelTable) CountModelByUsernamePrefix(ctx context.Context, d sqldb.Executor, userids []string, usernamePrefix string) (int, error) {
+	paramCount := 1
+	args := make([]interface{}, 0, paramCount+len(userids))
+	args = append(args, usernamePrefix)
+	var placeholdersuserids string
+	{
+		placeholders := make([]string, 0, len(userids))
+		for _, i := range userids {
+			paramCount++
+			placeholders = append(placeholders, fmt.Sprintf("($%d)", paramCount))
+			args = append(args, i)
+		}
+		placeholdersuserids = strings.Join(placeholders, ", ")
+	}
+	var count int
+	if err := d.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+t.TableName+" WHERE userid IN (VALUES "+placeholdersuserids+") AND username LIKE $1;", args...).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (t *userModelTable) ExistsModelByEmail(ctx context.Context, d sqldb.Executor, email string) (bool, error) {
+	var exists bool
+	if err := d.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM "+t.TableName+" WHERE email = $1);", email).Scan(&exists); err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+`,
+			},
+		},
+
+		{
+			Name: "generates keyset queries",
+			Fsys: fstest.MapFS{
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "audit": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "getgroupkeyset",
+            "name": "Page",
+            "order": [
+              {"col": "eventid"}
+            ]
+          },
+          {
+            "kind": "getgroupkeyset",
+            "name": "ByUserLatest",
+            "conditions": [
+              {"col": "userid"},
+              {"col": "kind", "cond": "in"}
+            ],
+            "order": [
+              {"col": "creation_time", "dir": "DESC"},
+              {"col": "eventid", "dir": "DESC"}
+            ]
+          },
+          {
+            "kind": "getgroupkeyset",
+            "name": "ByKind",
+            "conditions": [
+              {"col": "kind"}
+            ],
+            "order": [
+              {"col": "userid"},
+              {"col": "creation_time", "dir": "DESC"},
+              {"col": "eventid"}
+            ]
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model audit
+	//forge:model:query audit
+	Model struct {
+		Eventid  string ` + "`" + `model:"eventid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) NOT NULL"` + "`" + `
+		Kind     string ` + "`" + `model:"kind,VARCHAR(31) NOT NULL"` + "`" + `
+		Creation int64  ` + "`" + `model:"creation_time,BIGINT NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Output: map[string]string{
+				"model_gen.go": `// Code generated by go generate forge model dev; DO NOT EDIT.
+
+package somepackage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"xorkevin.dev/forge/model/sqldb"
+)
+
+type (
+	auditModelTable struct {
+		TableName string
+	}
+)
+
+func (t *auditModelTable) Setup(ctx context.Context, d sqldb.Executor) error {
+	_, err := d.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+t.TableName+" (eventid VARCHAR(31) PRIMARY KEY, userid VARCHAR(31) NOT NULL, kind VARCHAR(31) NOT NULL, creation_time BIGINT NOT NULL);")
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *auditModelTable) Insert(ctx context.Context, d sqldb.Executor, m *Model) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (eventid, userid, kind, creation_time) VALUES ($1, $2, $3, $4);", m.Eventid, m.Userid, m.Kind, m.Creation)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *auditModelTable) InsertBulk(ctx context.Context, d sqldb.Executor, models []*Model, allowConflict bool) error {
+	conflictSQL := ""
+	if allowConflict {
+		conflictSQL = " ON CONFLICT DO NOTHING"
+	}
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*4)
+	for c, m := range models {
+		n := c * 4
+		placeholders = append(placeholders, fmt.Sprintf("($%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4))
+		args = append(args, m.Eventid, m.Userid, m.Kind, m.Creation)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (eventid, userid, kind, creation_time) VALUES "+strings.Join(placeholders, ", ")+conflictSQL+";", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *auditModelTable) GetModelPage(ctx context.Context, d sqldb.Executor, limit int) (_ []Model, retErr error) {
+	res := make([]Model, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT eventid, userid, kind, creation_time FROM "+t.TableName+" ORDER BY eventid LIMIT $1;", limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Model
+		if err := rows.Scan(&m.Eventid, &m.Userid, &m.Kind, &m.Creation); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *auditModelTable) GetModelPageAfter(ctx context.Context, d sqldb.Executor, after *Model, limit int) (_ []Model, retErr error) {
+	res := make([]Model, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT eventid, userid, kind, creation_time FROM "+t.TableName+" WHERE eventid > $2 ORDER BY eventid LIMIT $1;", limit, after.Eventid)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Model
+		if err := rows.Scan(&m.Eventid, &m.Userid, &m.Kind, &m.Creation); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *auditModelTable) GetModelByUserLatest(ctx context.Context, d sqldb.Executor, userid string, kinds []string, limit int) (_ []Model, retErr error) {
+	paramCount := 2
+	args := make([]interface{}, 0, paramCount+len(kinds))
+	args = append(args, limit, userid)
+	var placeholderskinds string
+	{
+		placeholders := make([]string, 0, len(kinds))
+		for _, i := range kinds {
+			paramCount++
+			placeholders = append(placeholders, fmt.Sprintf("($%d)", paramCount))
+			args = append(args, i)
+		}
+		placeholderskinds = strings.Join(placeholders, ", ")
+	}
+	res := make([]Model, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT eventid, userid, kind, creation_time FROM "+t.TableName+" WHERE userid = $2 AND kind IN (VALUES "+placeholderskinds+") ORDER BY creation_time DESC, eventid DESC LIMIT $1;", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Model
+		if err := rows.Scan(&m.Eventid, &m.Userid, &m.Kind, &m.Creation); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *auditModelTable) GetModelByUserLatestAfter(ctx context.Context, d sqldb.Executor, userid string, kinds []string, after *Model, limit int) (_ []Model, retErr error) {
+	paramCount := 4
+	args := make([]interface{}, 0, paramCount+len(kinds))
+	args = append(args, limit, after.Creation, after.Eventid, userid)
+	var placeholderskinds string
+	{
+		placeholders := make([]string, 0, len(kinds))
+		for _, i := range kinds {
+			paramCount++
+			placeholders = append(placeholders, fmt.Sprintf("($%d)", paramCount))
+			args = append(args, i)
+		}
+		placeholderskinds = strings.Join(placeholders, ", ")
+	}
+	res := make([]Model, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT eventid, userid, kind, creation_time FROM "+t.TableName+" WHERE (creation_time, eventid) < ($2, $3) AND userid = $4 AND kind IN (VALUES "+placeholderskinds+") ORDER BY creation_time DESC, eventid DESC LIMIT $1;", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Model
+		if err := rows.Scan(&m.Eventid, &m.Userid, &m.Kind, &m.Creation); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *auditModelTable) GetModelByKind(ctx context.Context, d sqldb.Executor, kind string, limit int) (_ []Model, retErr error) {
+	res := make([]Model, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT eventid, userid, kind, creation_time FROM "+t.TableName+" WHERE kind = $2 ORDER BY userid, creation_time DESC, eventid LIMIT $1;", limit, kind)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Model
+		if err := rows.Scan(&m.Eventid, &m.Userid, &m.Kind, &m.Creation); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *auditModelTable) GetModelByKindAfter(ctx context.Context, d sqldb.Executor, kind string, after *Model, limit int) (_ []Model, retErr error) {
+	res := make([]Model, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT eventid, userid, kind, creation_time FROM "+t.TableName+" WHERE (userid > $2 OR (userid = $2 AND creation_time < $3) OR (userid = $2 AND creation_time = $3 AND eventid > $4)) AND kind = $5 ORDER BY userid, creation_time DESC, eventid LIMIT $1;", limit, after.Userid, after.Creation, after.Eventid, kind)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Model
+		if err := rows.Scan(&m.Eventid, &m.Userid, &m.Kind, &m.Creation); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+`,
+			},
+		},
+
+		{
+			Name:    "generates mysql keyset queries",
+			Dialect: DialectMySQL{},
+			Fsys: fstest.MapFS{
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "audit": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "getgroupkeyset",
+            "name": "Page",
+            "order": [
+              {"col": "eventid"}
+            ]
+          },
+          {
+            "kind": "getgroupkeyset",
+            "name": "ByUserLatest",
+            "conditions": [
+              {"col": "userid"},
+              {"col": "kind", "cond": "in"}
+            ],
+            "order": [
+              {"col": "creation_time", "dir": "DESC"},
+              {"col": "eventid", "dir": "DESC"}
+            ]
+          },
+          {
+            "kind": "getgroupkeyset",
+            "name": "ByKind",
+            "conditions": [
+              {"col": "kind"}
+            ],
+            "order": [
+              {"col": "userid"},
+              {"col": "creation_time", "dir": "DESC"},
+              {"col": "eventid"}
+            ]
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model audit
+	//forge:model:query audit
+	Model struct {
+		Eventid  string ` + "`" + `model:"eventid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) NOT NULL"` + "`" + `
+		Kind     string ` + "`" + `model:"kind,VARCHAR(31) NOT NULL"` + "`" + `
+		Creation int64  ` + "`" + `model:"creation_time,BIGINT NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Output: map[string]string{
+				"model_gen.go": `// Code generated by go generate forge model dev; DO NOT EDIT.
+
+package somepackage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"xorkevin.dev/forge/model/sqldb"
+)
+
+type (
+	auditModelTable struct {
+		TableName string
+	}
+)
+
+func (t *auditModelTable) Setup(ctx context.Context, d sqldb.Executor) error {
+	_, err := d.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `eventid` + "`" + ` VARCHAR(31) PRIMARY KEY, ` + "`" + `userid` + "`" + ` VARCHAR(31) NOT NULL, ` + "`" + `kind` + "`" + ` VARCHAR(31) NOT NULL, ` + "`" + `creation_time` + "`" + ` BIGINT NOT NULL);")
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *auditModelTable) Insert(ctx context.Context, d sqldb.Executor, m *Model) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `eventid` + "`" + `, ` + "`" + `userid` + "`" + `, ` + "`" + `kind` + "`" + `, ` + "`" + `creation_time` + "`" + `) VALUES (?, ?, ?, ?);", m.Eventid, m.Userid, m.Kind, m.Creation)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *auditModelTable) InsertBulk(ctx context.Context, d sqldb.Executor, models []*Model, allowConflict bool) error {
+	conflictSQL := ""
+	if allowConflict {
+		conflictSQL = " ON DUPLICATE KEY UPDATE ` + "`" + `eventid` + "`" + ` = ` + "`" + `eventid` + "`" + `"
+	}
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*4)
+	for _, m := range models {
+		placeholders = append(placeholders, "(?, ?, ?, ?)")
+		args = append(args, m.Eventid, m.Userid, m.Kind, m.Creation)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `eventid` + "`" + `, ` + "`" + `userid` + "`" + `, ` + "`" + `kind` + "`" + `, ` + "`" + `creation_time` + "`" + `) VALUES "+strings.Join(placeholders, ", ")+conflictSQL+";", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *auditModelTable) GetModelPage(ctx context.Context, d sqldb.Executor, limit int) (_ []Model, retErr error) {
+	res := make([]Model, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT ` + "`" + `eventid` + "`" + `, ` + "`" + `userid` + "`" + `, ` + "`" + `kind` + "`" + `, ` + "`" + `creation_time` + "`" + ` FROM ` + "`" + `"+t.TableName+"` + "`" + ` ORDER BY ` + "`" + `eventid` + "`" + ` LIMIT ?;", limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Model
+		if err := rows.Scan(&m.Eventid, &m.Userid, &m.Kind, &m.Creation); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *auditModelTable) GetModelPageAfter(ctx context.Context, d sqldb.Executor, after *Model, limit int) (_ []Model, retErr error) {
+	res := make([]Model, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT ` + "`" + `eventid` + "`" + `, ` + "`" + `userid` + "`" + `, ` + "`" + `kind` + "`" + `, ` + "`" + `creation_time` + "`" + ` FROM ` + "`" + `"+t.TableName+"` + "`" + ` WHERE ` + "`" + `eventid` + "`" + ` > ? ORDER BY ` + "`" + `eventid` + "`" + ` LIMIT ?;", after.Eventid, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Model
+		if err := rows.Scan(&m.Eventid, &m.Userid, &m.Kind, &m.Creation); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *auditModelTable) GetModelByUserLatest(ctx context.Context, d sqldb.Executor, userid string, kinds []string, limit int) (_ []Model, retErr error) {
+	paramCount := 2
+	args := make([]interface{}, 0, paramCount+len(kinds))
+	args = append(args, userid)
+	var placeholderskinds string
+	{
+		placeholders := make([]string, 0, len(kinds))
+		for _, i := range kinds {
+			placeholders = append(placeholders, "?")
+			args = append(args, i)
+		}
+		placeholderskinds = strings.Join(placeholders, ", ")
+	}
+	args = append(args, limit)
+	res := make([]Model, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT ` + "`" + `eventid` + "`" + `, ` + "`" + `userid` + "`" + `, ` + "`" + `kind` + "`" + `, ` + "`" + `creation_time` + "`" + ` FROM ` + "`" + `"+t.TableName+"` + "`" + ` WHERE ` + "`" + `userid` + "`" + ` = ? AND ` + "`" + `kind` + "`" + ` IN ("+placeholderskinds+") ORDER BY ` + "`" + `creation_time` + "`" + ` DESC, ` + "`" + `eventid` + "`" + ` DESC LIMIT ?;", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Model
+		if err := rows.Scan(&m.Eventid, &m.Userid, &m.Kind, &m.Creation); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *auditModelTable) GetModelByUserLatestAfter(ctx context.Context, d sqldb.Executor, userid string, kinds []string, after *Model, limit int) (_ []Model, retErr error) {
+	paramCount := 4
+	args := make([]interface{}, 0, paramCount+len(kinds))
+	args = append(args, after.Creation, after.Eventid, userid)
+	var placeholderskinds string
+	{
+		placeholders := make([]string, 0, len(kinds))
+		for _, i := range kinds {
+			placeholders = append(placeholders, "?")
+			args = append(args, i)
+		}
+		placeholderskinds = strings.Join(placeholders, ", ")
+	}
+	args = append(args, limit)
+	res := make([]Model, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT ` + "`" + `eventid` + "`" + `, ` + "`" + `userid` + "`" + `, ` + "`" + `kind` + "`" + `, ` + "`" + `creation_time` + "`" + ` FROM ` + "`" + `"+t.TableName+"` + "`" + ` WHERE (` + "`" + `creation_time` + "`" + `, ` + "`" + `eventid` + "`" + `) < (?, ?) AND ` + "`" + `userid` + "`" + ` = ? AND ` + "`" + `kind` + "`" + ` IN ("+placeholderskinds+") ORDER BY ` + "`" + `creation_time` + "`" + ` DESC, ` + "`" + `eventid` + "`" + ` DESC LIMIT ?;", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Model
+		if err := rows.Scan(&m.Eventid, &m.Userid, &m.Kind, &m.Creation); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *auditModelTable) GetModelByKind(ctx context.Context, d sqldb.Executor, kind string, limit int) (_ []Model, retErr error) {
+	res := make([]Model, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT ` + "`" + `eventid` + "`" + `, ` + "`" + `userid` + "`" + `, ` + "`" + `kind` + "`" + `, ` + "`" + `creation_time` + "`" + ` FROM ` + "`" + `"+t.TableName+"` + "`" + ` WHERE ` + "`" + `kind` + "`" + ` = ? ORDER BY ` + "`" + `userid` + "`" + `, ` + "`" + `creation_time` + "`" + ` DESC, ` + "`" + `eventid` + "`" + ` LIMIT ?;", kind, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Model
+		if err := rows.Scan(&m.Eventid, &m.Userid, &m.Kind, &m.Creation); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *auditModelTable) GetModelByKindAfter(ctx context.Context, d sqldb.Executor, kind string, after *Model, limit int) (_ []Model, retErr error) {
+	res := make([]Model, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT ` + "`" + `eventid` + "`" + `, ` + "`" + `userid` + "`" + `, ` + "`" + `kind` + "`" + `, ` + "`" + `creation_time` + "`" + ` FROM ` + "`" + `"+t.TableName+"` + "`" + ` WHERE (` + "`" + `userid` + "`" + ` > ? OR (` + "`" + `userid` + "`" + ` = ? AND ` + "`" + `creation_time` + "`" + ` < ?) OR (` + "`" + `userid` + "`" + ` = ? AND ` + "`" + `creation_time` + "`" + ` = ? AND ` + "`" + `eventid` + "`" + ` > ?)) AND ` + "`" + `kind` + "`" + ` = ? ORDER BY ` + "`" + `userid` + "`" + `, ` + "`" + `creation_time` + "`" + ` DESC, ` + "`" + `eventid` + "`" + ` LIMIT ?;", after.Userid, after.Userid, after.Creation, after.Userid, after.Creation, after.Eventid, kind, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Model
+		if err := rows.Scan(&m.Eventid, &m.Userid, &m.Kind, &m.Creation); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+`,
+			},
+		},
+
+		{
+			Name: "generates stream queries",
+			Fsys: fstest.MapFS{
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "audit": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "getgroup",
+            "name": "All",
+            "order": [
+              {"col": "eventid"}
+            ],
+            "stream": true
+          },
+          {
+            "kind": "getgroupeq",
+            "name": "ByKinds",
+            "conditions": [
+              {"col": "kind", "cond": "in"},
+              {"col": "creation_time", "cond": "lt"}
+            ],
+            "stream": true
+          },
+          {
+            "kind": "getgroupkeyset",
+            "name": "ByUser",
+            "conditions": [
+              {"col": "userid"}
+            ],
+            "order": [
+              {"col": "eventid"}
+            ],
+            "stream": true
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model audit
+	//forge:model:query audit
+	Model struct {
+		Eventid  string ` + "`" + `model:"eventid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) NOT NULL"` + "`" + `
+		Kind     string ` + "`" + `model:"kind,VARCHAR(31) NOT NULL"` + "`" + `
+		Creation int64  ` + "`" + `model:"creation_time,BIGINT NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Output: map[string]string{
+				"model_gen.go": `// Code generated by go generate forge model dev; DO NOT EDIT.
+
+package somepackage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"xorkevin.dev/forge/model/sqldb"
+)
+
+type (
+	auditModelTable struct {
+		TableName string
+	}
+)
+
+func (t *auditModelTable) Setup(ctx context.Context, d sqldb.Executor) error {
+	_, err := d.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+t.TableName+" (eventid VARCHAR(31) PRIMARY KEY, userid VARCHAR(31) NOT NULL, kind VARCHAR(31) NOT NULL, creation_time BIGINT NOT NULL);")
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *auditModelTable) Insert(ctx context.Context, d sqldb.Executor, m *Model) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (eventid, userid, kind, creation_time) VALUES ($1, $2, $3, $4);", m.Eventid, m.Userid, m.Kind, m.Creation)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *auditModelTable) InsertBulk(ctx context.Context, d sqldb.Executor, models []*Model, allowConflict bool) error {
+	conflictSQL := ""
+	if allowConflict {
+		conflictSQL = " ON CONFLICT DO NOTHING"
+	}
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*4)
+	for c, m := range models {
+		n := c * 4
+		placeholders = append(placeholders, fmt.Sprintf("($%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4))
+		args = append(args, m.Eventid, m.Userid, m.Kind, m.Creation)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (eventid, userid, kind, creation_time) VALUES "+strings.Join(placeholders, ", ")+conflictSQL+";", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *auditModelTable) GetModelAll(ctx context.Context, d sqldb.Executor, limit, offset int) (_ []Model, retErr error) {
+	res := make([]Model, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT eventid, userid, kind, creation_time FROM "+t.TableName+" ORDER BY eventid LIMIT $1 OFFSET $2;", limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Model
+		if err := rows.Scan(&m.Eventid, &m.Userid, &m.Kind, &m.Creation); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *auditModelTable) StreamModelAll(ctx context.Context, d sqldb.Executor, fn func(m Model) error) (retErr error) {
+	rows, err := d.QueryContext(ctx, "SELECT eventid, userid, kind, creation_time FROM "+t.TableName+" ORDER BY eventid;")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Model
+		if err := rows.Scan(&m.Eventid, &m.Userid, &m.Kind, &m.Creation); err != nil {
+			return err
+		}
+		if err := fn(m); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *auditModelTable) GetModelByKinds(ctx context.Context, d sqldb.Executor, kinds []string, creation int64, limit, offset int) (_ []Model, retErr error) {
+	paramCount := 3
+	args := make([]interface{}, 0, paramCount+len(kinds))
+	args = append(args, limit, offset, creation)
+	var placeholderskinds string
+	{
+		placeholders := make([]string, 0, len(kinds))
+		for _, i := range kinds {
+			paramCount++
+			placeholders = append(placeholders, fmt.Sprintf("($%d)", paramCount))
+			args = append(args, i)
+		}
+		placeholderskinds = strings.Join(placeholders, ", ")
+	}
+	res := make([]Model, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT eventid, userid, kind, creation_time FROM "+t.TableName+" WHERE kind IN (VALUES "+placeholderskinds+") AND creation_time < $3 LIMIT $1 OFFSET $2;", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Model
+		if err := rows.Scan(&m.Eventid, &m.Userid, &m.Kind, &m.Creation); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *auditModelTable) StreamModelByKinds(ctx context.Context, d sqldb.Executor, kinds []string, creation int64, fn func(m Model) error) (retErr error) {
+	paramCount := 1
+	args := make([]interface{}, 0, paramCount+len(kinds))
+	args = append(args, creation)
+	var placeholderskinds string
+	{
+		placeholders := make([]string, 0, len(kinds))
+		for _, i := range kinds {
+			paramCount++
+			placeholders = append(placeholders, fmt.Sprintf("($%d)", paramCount))
+			args = append(args, i)
+		}
+		placeholderskinds = strings.Join(placeholders, ", ")
+	}
+	rows, err := d.QueryContext(ctx, "SELECT eventid, userid, kind, creation_time FROM "+t.TableName+" WHERE kind IN (VALUES "+placeholderskinds+") AND creation_time < $1;", args...)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Model
+		if err := rows.Scan(&m.Eventid, &m.Userid, &m.Kind, &m.Creation); err != nil {
+			return err
+		}
+		if err := fn(m); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *auditModelTable) GetModelByUser(ctx context.Context, d sqldb.Executor, userid string, limit int) (_ []Model, retErr error) {
+	res := make([]Model, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT eventid, userid, kind, creation_time FROM "+t.TableName+" WHERE userid = $2 ORDER BY eventid LIMIT $1;", limit, userid)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Model
+		if err := rows.Scan(&m.Eventid, &m.Userid, &m.Kind, &m.Creation); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *auditModelTable) GetModelByUserAfter(ctx context.Context, d sqldb.Executor, userid string, after *Model, limit int) (_ []Model, retErr error) {
+	res := make([]Model, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT eventid, userid, kind, creation_time FROM "+t.TableName+" WHERE eventid > $2 AND userid = $3 ORDER BY eventid LIMIT $1;", limit, after.Eventid, userid)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Model
+		if err := rows.Scan(&m.Eventid, &m.Userid, &m.Kind, &m.Creation); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *auditModelTable) StreamModelByUser(ctx context.Context, d sqldb.Executor, userid string, fn func(m Model) error) (retErr error) {
+	rows, err := d.QueryContext(ctx, "SELECT eventid, userid, kind, creation_time FROM "+t.TableName+" WHERE userid = $1 ORDER BY eventid;", userid)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Model
+		if err := rows.Scan(&m.Eventid, &m.Userid, &m.Kind, &m.Creation); err != nil {
+			return err
+		}
+		if err := fn(m); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return nil
+}
+`,
+			},
+		},
+
+		{
+			Name: "generates returning queries",
+			Fsys: fstest.MapFS{
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "job": {
+      "model": {
+        "returning": ["id", "creation_time"]
+      },
+      "queries": {
+        "Model": [
+          {
+            "kind": "deleq",
+            "name": "ByKind",
+            "conditions": [
+              {"col": "kind", "cond": "in"}
+            ],
+            "returning": "Model"
+          }
+        ],
+        "Claim": [
+          {
+            "kind": "updeq",
+            "name": "ByKind",
+            "conditions": [
+              {"col": "kind"},
+              {"col": "owner"}
+            ],
+            "returning": "Info"
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model job
+	//forge:model:query job
+	Model struct {
+		ID       int64  ` + "`" + `model:"id,BIGSERIAL PRIMARY KEY"` + "`" + `
+		Kind     string ` + "`" + `model:"kind,VARCHAR(31) NOT NULL"` + "`" + `
+		Owner    string ` + "`" + `model:"owner,VARCHAR(31) NOT NULL"` + "`" + `
+		Creation int64  ` + "`" + `model:"creation_time,BIGINT NOT NULL DEFAULT 0"` + "`" + `
+	}
+
+	//forge:model:query job
+	Claim struct {
+		Owner string ` + "`" + `model:"owner"` + "`" + `
+	}
+
+	//forge:model:query job
+	Info struct {
+		ID   int64  ` + "`" + `model:"id"` + "`" + `
+		Kind string ` + "`" + `model:"kind"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Output: map[string]string{
+				"model_gen.go": `// Code generated by go generate forge model dev; DO NOT EDIT.
+
+package somepackage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"xorkevin.dev/forge/model/sqldb"
+)
+
+type (
+	jobModelTable struct {
+		TableName string
+	}
+)
+
+func (t *jobModelTable) Setup(ctx context.Context, d sqldb.Executor) error {
+	_, err := d.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+t.TableName+" (id BIGSERIAL PRIMARY KEY, kind VARCHAR(31) NOT NULL, owner VARCHAR(31) NOT NULL, creation_time BIGINT NOT NULL DEFAULT 0);")
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *jobModelTable) Insert(ctx context.Context, d sqldb.Executor, m *Model) error {
+	if err := d.QueryRowContext(ctx, "INSERT INTO "+t.TableName+" (kind, owner) VALUES ($1, $2) RETURNING id, creation_time;", m.Kind, m.Owner).Scan(&m.ID, &m.Creation); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *jobModelTable) InsertBulk(ctx context.Context, d sqldb.Executor, models []*Model, allowConflict bool) error {
+	conflictSQL := ""
+	if allowConflict {
+		conflictSQL = " ON CONFLICT DO NOTHING"
+	}
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*2)
+	for c, m := range models {
+		n := c * 2
+		placeholders = append(placeholders, fmt.Sprintf("($%d, $%d)", n+1, n+2))
+		args = append(args, m.Kind, m.Owner)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (kind, owner) VALUES "+strings.Join(placeholders, ", ")+conflictSQL+";", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *jobModelTable) DelByKind(ctx context.Context, d sqldb.Executor, kinds []string) (_ []Model, retErr error) {
+	paramCount := 0
+	args := make([]interface{}, 0, paramCount+len(kinds))
+	var placeholderskinds string
+	{
+		placeholders := make([]string, 0, len(kinds))
+		for _, i := range kinds {
+			paramCount++
+			placeholders = append(placeholders, fmt.Sprintf("($%d)", paramCount))
+			args = append(args, i)
+		}
+		placeholderskinds = strings.Join(placeholders, ", ")
+	}
+	rows, err := d.QueryContext(ctx, "DELETE FROM "+t.TableName+" WHERE kind IN (VALUES "+placeholderskinds+") RETURNING id, kind, owner, creation_time;", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	var res []Model
+	for rows.Next() {
+		var r Model
+		if err := rows.Scan(&r.ID, &r.Kind, &r.Owner, &r.Creation); err != nil {
+			return nil, err
+		}
+		res = append(res, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *jobModelTable) UpdClaimByKind(ctx context.Context, d sqldb.Executor, m *Claim, kind string, owner string) (_ []Info, retErr error) {
+	rows, err := d.QueryContext(ctx, "UPDATE "+t.TableName+" SET owner = $1 WHERE kind = $2 AND owner = $3 RETURNING id, kind;", m.Owner, kind, owner)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	var res []Info
+	for rows.Next() {
+		var r Info
+		if err := rows.Scan(&r.ID, &r.Kind); err != nil {
+			return nil, err
+		}
+		res = append(res, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+`,
+			},
+		},
+
+		{
+			Name: "generates affected rows queries",
+			Fsys: fstest.MapFS{
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "updeq",
+            "name": "ByID",
+            "conditions": [
+              {"col": "userid"}
+            ],
+            "affected": "notfound"
+          },
+          {
+            "kind": "updeq",
+            "name": "ByUsernames",
+            "conditions": [
+              {"col": "username", "cond": "in"}
+            ],
+            "affected": "count"
+          },
+          {
+            "kind": "deleq",
+            "name": "ByID",
+            "conditions": [
+              {"col": "userid"}
+            ],
+            "affected": "notfound"
+          },
+          {
+            "kind": "deleq",
+            "name": "ByEmail",
+            "conditions": [
+              {"col": "email"}
+            ],
+            "affected": "count"
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+		Email    string ` + "`" + `model:"email,VARCHAR(255) NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Output: map[string]string{
+				"model_gen.go": `// Code generated by go generate forge model dev; DO NOT EDIT.
+
+package somepackage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"xorkevin.dev/forge/model/sqldb"
+)
+
+type (
+	userModelTable struct {
+		TableName string
+	}
+)
+
+func (t *userModelTable) Setup(ctx context.Context, d sqldb.Executor) error {
+	_, err := d.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+t.TableName+" (userid VARCHAR(31) PRIMARY KEY, username VARCHAR(255) NOT NULL, email VARCHAR(255) NOT NULL);")
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) Insert(ctx context.Context, d sqldb.Executor, m *Model) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (userid, username, email) VALUES ($1, $2, $3);", m.Userid, m.Username, m.Email)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) InsertBulk(ctx context.Context, d sqldb.Executor, models []*Model, allowConflict bool) error {
+	conflictSQL := ""
+	if allowConflict {
+		conflictSQL = " ON CONFLICT DO NOTHING"
+	}
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*3)
+	for c, m := range models {
+		n := c * 3
+		placeholders = append(placeholders, fmt.Sprintf("($%d, $%d, $%d)", n+1, n+2, n+3))
+		args = append(args, m.Userid, m.Username, m.Email)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (userid, username, email) VALUES "+strings.Join(placeholders, ", ")+conflictSQL+";", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) UpdModelByID(ctx context.Context, d sqldb.Executor, m *Model, userid string) error {
+	res, err := d.ExecContext(ctx, "UPDATE "+t.TableName+" SET (userid, username, email) = ($1, $2, $3) WHERE userid = $4;", m.Userid, m.Username, m.Email, userid)
+	if err != nil {
+		return err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return sqldb.ErrNotFound
+	}
+	return nil
+}
+
+func (t *userModelTable) UpdModelByUsernames(ctx context.Context, d sqldb.Executor, m *Model, usernames []string) (int64, error) {
+	paramCount := 3
+	args := make([]interface{}, 0, paramCount+len(usernames))
+	args = append(args, m.Userid, m.Username, m.Email)
+	var placeholdersusernames string
+	{
+		placeholders := make([]string, 0, len(usernames))
+		for _, i := range usernames {
+			paramCount++
+			placeholders = append(placeholders, fmt.Sprintf("($%d)", paramCount))
+			args = append(args, i)
+		}
+		placeholdersusernames = strings.Join(placeholders, ", ")
+	}
+	res, err := d.ExecContext(ctx, "UPDATE "+t.TableName+" SET (userid, username, email) = ($1, $2, $3) WHERE username IN (VALUES "+placeholdersusernames+");", args...)
+	if err != nil {
+		return 0, err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (t *userModelTable) DelByID(ctx context.Context, d sqldb.Executor, userid string) error {
+	res, err := d.ExecContext(ctx, "DELETE FROM "+t.TableName+" WHERE userid = $1;", userid)
+	if err != nil {
+		return err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return sqldb.ErrNotFound
+	}
+	return nil
+}
+
+func (t *userModelTable) DelByEmail(ctx context.Context, d sqldb.Executor, email string) (int64, error) {
+	res, err := d.ExecContext(ctx, "DELETE FROM "+t.TableName+" WHERE email = $1;", email)
+	if err != nil {
+		return 0, err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+`,
+			},
+		},
+
+		{
+			Name:    "generates mysql affected rows queries",
+			Dialect: DialectMySQL{},
+			Fsys: fstest.MapFS{
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "updeq",
+            "name": "ByUsernames",
+            "conditions": [
+              {"col": "username", "cond": "in"}
+            ],
+            "affected": "count"
+          },
+          {
+            "kind": "deleq",
+            "name": "ByID",
+            "conditions": [
+              {"col": "userid"}
+            ],
+            "affected": "notfound"
+          },
+          {
+            "kind": "deleq",
+            "name": "ByEmail",
+            "conditions": [
+              {"col": "email"}
+            ],
+            "affected": "count"
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+		Email    string ` + "`" + `model:"email,VARCHAR(255) NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Output: map[string]string{
+				"model_gen.go": `// Code generated by go generate forge model dev; DO NOT EDIT.
+
+package somepackage
+
+import (
+	"context"
+	"strings"
+
+	"xorkevin.dev/forge/model/sqldb"
+)
+
+type (
+	userModelTable struct {
+		TableName string
+	}
+)
+
+func (t *userModelTable) Setup(ctx context.Context, d sqldb.Executor) error {
+	_, err := d.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `userid` + "`" + ` VARCHAR(31) PRIMARY KEY, ` + "`" + `username` + "`" + ` VARCHAR(255) NOT NULL, ` + "`" + `email` + "`" + ` VARCHAR(255) NOT NULL);")
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) Insert(ctx context.Context, d sqldb.Executor, m *Model) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `userid` + "`" + `, ` + "`" + `username` + "`" + `, ` + "`" + `email` + "`" + `) VALUES (?, ?, ?);", m.Userid, m.Username, m.Email)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) InsertBulk(ctx context.Context, d sqldb.Executor, models []*Model, allowConflict bool) error {
+	conflictSQL := ""
+	if allowConflict {
+		conflictSQL = " ON DUPLICATE KEY UPDATE ` + "`" + `userid` + "`" + ` = ` + "`" + `userid` + "`" + `"
+	}
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*3)
+	for _, m := range models {
+		placeholders = append(placeholders, "(?, ?, ?)")
+		args = append(args, m.Userid, m.Username, m.Email)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `userid` + "`" + `, ` + "`" + `username` + "`" + `, ` + "`" + `email` + "`" + `) VALUES "+strings.Join(placeholders, ", ")+conflictSQL+";", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) UpdModelByUsernames(ctx context.Context, d sqldb.Executor, m *Model, usernames []string) (int64, error) {
+	paramCount := 3
+	args := make([]interface{}, 0, paramCount+len(usernames))
+	args = append(args, m.Userid, m.Username, m.Email)
+	var placeholdersusernames string
+	{
+		placeholders := make([]string, 0, len(usernames))
+		for _, i := range usernames {
+			placeholders = append(placeholders, "?")
+			args = append(args, i)
+		}
+		placeholdersusernames = strings.Join(placeholders, ", ")
+	}
+	res, err := d.ExecContext(ctx, "UPDATE ` + "`" + `"+t.TableName+"` + "`" + ` SET ` + "`" + `userid` + "`" + ` = ?, ` + "`" + `username` + "`" + ` = ?, ` + "`" + `email` + "`" + ` = ? WHERE ` + "`" + `username` + "`" + ` IN ("+placeholdersusernames+");", args...)
+	if err != nil {
+		return 0, err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (t *userModelTable) DelByID(ctx context.Context, d sqldb.Executor, userid string) error {
+	res, err := d.ExecContext(ctx, "DELETE FROM ` + "`" + `"+t.TableName+"` + "`" + ` WHERE ` + "`" + `userid` + "`" + ` = ?;", userid)
+	if err != nil {
+		return err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return sqldb.ErrNotFound
+	}
+	return nil
+}
+
+func (t *userModelTable) DelByEmail(ctx context.Context, d sqldb.Executor, email string) (int64, error) {
+	res, err := d.ExecContext(ctx, "DELETE FROM ` + "`" + `"+t.TableName+"` + "`" + ` WHERE ` + "`" + `email` + "`" + ` = ?;", email)
+	if err != nil {
+		return 0, err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+`,
+			},
+		},
+
+		{
+			Name: "generates patch queries",
+			Fsys: fstest.MapFS{
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "getoneeq",
+            "name": "ByID",
+            "conditions": [
+              {"col": "userid"}
+            ]
+          }
+        ],
+        "Patch": [
+          {
+            "kind": "patcheq",
+            "name": "ByID",
+            "conditions": [
+              {"col": "userid"}
+            ]
+          },
+          {
+            "kind": "patcheq",
+            "name": "ByIDs",
+            "conditions": [
+              {"col": "userid", "cond": "in"},
+              {"col": "bio"}
+            ],
+            "affected": "count"
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+		Email    string ` + "`" + `model:"email,VARCHAR(255) NOT NULL"` + "`" + `
+		Bio      string ` + "`" + `model:"bio,VARCHAR(4095) NOT NULL"` + "`" + `
+	}
+
+	//forge:model:query user
+	Patch struct {
+		Username *string ` + "`" + `model:"username"` + "`" + `
+		Email    *string ` + "`" + `model:"email"` + "`" + `
+		Bio      string  ` + "`" + `model:"bio"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Output: map[string]string{
+				"model_gen.go": `// Code generated by go generate forge model dev; DO NOT EDIT.
+
+package somepackage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"xorkevin.dev/forge/model/sqldb"
+)
+
+type (
+	userModelTable struct {
+		TableName string
+	}
+)
+
+func (t *userModelTable) Setup(ctx context.Context, d sqldb.Executor) error {
+	_, err := d.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+t.TableName+" (userid VARCHAR(31) PRIMARY KEY, username VARCHAR(255) NOT NULL, email VARCHAR(255) NOT NULL, bio VARCHAR(4095) NOT NULL);")
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) Insert(ctx context.Context, d sqldb.Executor, m *Model) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (userid, username, email, bio) VALUES ($1, $2, $3, $4);", m.Userid, m.Username, m.Email, m.Bio)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) InsertBulk(ctx context.Context, d sqldb.Executor, models []*Model, allowConflict bool) error {
+	conflictSQL := ""
+	if allowConflict {
+		conflictSQL = " ON CONFLICT DO NOTHING"
+	}
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*4)
+	for c, m := range models {
+		n := c * 4
+		placeholders = append(placeholders, fmt.Sprintf("($%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4))
+		args = append(args, m.Userid, m.Username, m.Email, m.Bio)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (userid, username, email, bio) VALUES "+strings.Join(placeholders, ", ")+conflictSQL+";", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) GetModelByID(ctx context.Context, d sqldb.Executor, userid string) (*Model, error) {
+	m := &Model{}
+	if err := d.QueryRowContext(ctx, "SELECT userid, username, email, bio FROM "+t.TableName+" WHERE userid = $1;", userid).Scan(&m.Userid, &m.Username, &m.Email, &m.Bio); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (t *userModelTable) PatchPatchByID(ctx context.Context, d sqldb.Executor, m *Patch, userid string) error {
+	paramCount := 1
+	args := make([]interface{}, 0, paramCount+3)
+	args = append(args, userid)
+	set := make([]string, 0, 3)
+	if m.Username != nil {
+		paramCount++
+		set = append(set, fmt.Sprintf("username = $%d", paramCount))
+		args = append(args, *m.Username)
+	}
+	if m.Email != nil {
+		paramCount++
+		set = append(set, fmt.Sprintf("email = $%d", paramCount))
+		args = append(args, *m.Email)
+	}
+	paramCount++
+	set = append(set, fmt.Sprintf("bio = $%d", paramCount))
+	args = append(args, m.Bio)
+	if len(set) == 0 {
+		// assign a column to itself when no fields are set such that the
+		// update still matches rows
+		set = append(set, "username = username")
+	}
+	_, err := d.ExecContext(ctx, "UPDATE "+t.TableName+" SET "+strings.Join(set, ", ")+" WHERE userid = $1;", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) PatchPatchByIDs(ctx context.Context, d sqldb.Executor, m *Patch, userids []string, bio string) (int64, error) {
+	paramCount := 1
+	args := make([]interface{}, 0, paramCount+len(userids))
+	args = append(args, bio)
+	var placeholdersuserids string
+	{
+		placeholders := make([]string, 0, len(userids))
+		for _, i := range userids {
+			paramCount++
+			placeholders = append(placeholders, fmt.Sprintf("($%d)", paramCount))
+			args = append(args, i)
+		}
+		placeholdersuserids = strings.Join(placeholders, ", ")
+	}
+	set := make([]string, 0, 3)
+	if m.Username != nil {
+		paramCount++
+		set = append(set, fmt.Sprintf("username = $%d", paramCount))
+		args = append(args, *m.Username)
+	}
+	if m.Email != nil {
+		paramCount++
+		set = append(set, fmt.Sprintf("email = $%d", paramCount))
+		args = append(args, *m.Email)
+	}
+	paramCount++
+	set = append(set, fmt.Sprintf("bio = $%d", paramCount))
+	args = append(args, m.Bio)
+	if len(set) == 0 {
+		// assign a column to itself when no fields are set such that the
+		// update still matches rows
+		set = append(set, "username = username")
+	}
+	res, err := d.ExecContext(ctx, "UPDATE "+t.TableName+" SET "+strings.Join(set, ", ")+" WHERE userid IN (VALUES "+placeholdersuserids+") AND bio = $1;", args...)
+	if err != nil {
+		return 0, err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+`,
+			},
+		},
+
+		{
+			Name:    "generates mysql patch queries",
+			Dialect: DialectMySQL{},
+			Fsys: fstest.MapFS{
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "getoneeq",
+            "name": "ByID",
+            "conditions": [
+              {"col": "userid"}
+            ]
+          }
+        ],
+        "Patch": [
+          {
+            "kind": "patcheq",
+            "name": "ByID",
+            "conditions": [
+              {"col": "userid"}
+            ]
+          },
+          {
+            "kind": "patcheq",
+            "name": "ByIDs",
+            "conditions": [
+              {"col": "userid", "cond": "in"},
+              {"col": "bio"}
+            ],
+            "affected": "count"
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+		Email    string ` + "`" + `model:"email,VARCHAR(255) NOT NULL"` + "`" + `
+		Bio      string ` + "`" + `model:"bio,VARCHAR(4095) NOT NULL"` + "`" + `
+	}
+
+	//forge:model:query user
+	Patch struct {
+		Username *string ` + "`" + `model:"username"` + "`" + `
+		Email    *string ` + "`" + `model:"email"` + "`" + `
+		Bio      string  ` + "`" + `model:"bio"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Output: map[string]string{
+				"model_gen.go": `// Code generated by go generate forge model dev; DO NOT EDIT.
+
+package somepackage
+
+import (
+	"context"
+	"strings"
+
+	"xorkevin.dev/forge/model/sqldb"
+)
+
+type (
+	userModelTable struct {
+		TableName string
+	}
+)
+
+func (t *userModelTable) Setup(ctx context.Context, d sqldb.Executor) error {
+	_, err := d.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `userid` + "`" + ` VARCHAR(31) PRIMARY KEY, ` + "`" + `username` + "`" + ` VARCHAR(255) NOT NULL, ` + "`" + `email` + "`" + ` VARCHAR(255) NOT NULL, ` + "`" + `bio` + "`" + ` VARCHAR(4095) NOT NULL);")
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) Insert(ctx context.Context, d sqldb.Executor, m *Model) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `userid` + "`" + `, ` + "`" + `username` + "`" + `, ` + "`" + `email` + "`" + `, ` + "`" + `bio` + "`" + `) VALUES (?, ?, ?, ?);", m.Userid, m.Username, m.Email, m.Bio)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) InsertBulk(ctx context.Context, d sqldb.Executor, models []*Model, allowConflict bool) error {
+	conflictSQL := ""
+	if allowConflict {
+		conflictSQL = " ON DUPLICATE KEY UPDATE ` + "`" + `userid` + "`" + ` = ` + "`" + `userid` + "`" + `"
+	}
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*4)
+	for _, m := range models {
+		placeholders = append(placeholders, "(?, ?, ?, ?)")
+		args = append(args, m.Userid, m.Username, m.Email, m.Bio)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `userid` + "`" + `, ` + "`" + `username` + "`" + `, ` + "`" + `email` + "`" + `, ` + "`" + `bio` + "`" + `) VALUES "+strings.Join(placeholders, ", ")+conflictSQL+";", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) GetModelByID(ctx context.Context, d sqldb.Executor, userid string) (*Model, error) {
+	m := &Model{}
+	if err := d.QueryRowContext(ctx, "SELECT ` + "`" + `userid` + "`" + `, ` + "`" + `username` + "`" + `, ` + "`" + `email` + "`" + `, ` + "`" + `bio` + "`" + ` FROM ` + "`" + `"+t.TableName+"` + "`" + ` WHERE ` + "`" + `userid` + "`" + ` = ?;", userid).Scan(&m.Userid, &m.Username, &m.Email, &m.Bio); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (t *userModelTable) PatchPatchByID(ctx context.Context, d sqldb.Executor, m *Patch, userid string) error {
+	set := make([]string, 0, 3)
+	setArgs := make([]interface{}, 0, 3)
+	if m.Username != nil {
+		set = append(set, "` + "`" + `username` + "`" + ` = ?")
+		setArgs = append(setArgs, *m.Username)
+	}
+	if m.Email != nil {
+		set = append(set, "` + "`" + `email` + "`" + ` = ?")
+		setArgs = append(setArgs, *m.Email)
+	}
+	set = append(set, "` + "`" + `bio` + "`" + ` = ?")
+	setArgs = append(setArgs, m.Bio)
+	args := append(setArgs, userid)
+	if len(set) == 0 {
+		// assign a column to itself when no fields are set such that the
+		// update still matches rows
+		set = append(set, "` + "`" + `username` + "`" + ` = ` + "`" + `username` + "`" + `")
+	}
+	_, err := d.ExecContext(ctx, "UPDATE ` + "`" + `"+t.TableName+"` + "`" + ` SET "+strings.Join(set, ", ")+" WHERE ` + "`" + `userid` + "`" + ` = ?;", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) PatchPatchByIDs(ctx context.Context, d sqldb.Executor, m *Patch, userids []string, bio string) (int64, error) {
+	set := make([]string, 0, 3)
+	setArgs := make([]interface{}, 0, 3)
+	if m.Username != nil {
+		set = append(set, "` + "`" + `username` + "`" + ` = ?")
+		setArgs = append(setArgs, *m.Username)
+	}
+	if m.Email != nil {
+		set = append(set, "` + "`" + `email` + "`" + ` = ?")
+		setArgs = append(setArgs, *m.Email)
+	}
+	set = append(set, "` + "`" + `bio` + "`" + ` = ?")
+	setArgs = append(setArgs, m.Bio)
+	paramCount := 1
+	args := make([]interface{}, 0, paramCount+len(userids))
+	var placeholdersuserids string
+	{
+		placeholders := make([]string, 0, len(userids))
+		for _, i := range userids {
+			placeholders = append(placeholders, "?")
+			args = append(args, i)
+		}
+		placeholdersuserids = strings.Join(placeholders, ", ")
+	}
+	args = append(args, bio)
+	args = append(setArgs, args...)
+	if len(set) == 0 {
+		// assign a column to itself when no fields are set such that the
+		// update still matches rows
+		set = append(set, "` + "`" + `username` + "`" + ` = ` + "`" + `username` + "`" + `")
+	}
+	res, err := d.ExecContext(ctx, "UPDATE ` + "`" + `"+t.TableName+"` + "`" + ` SET "+strings.Join(set, ", ")+" WHERE ` + "`" + `userid` + "`" + ` IN ("+placeholdersuserids+") AND ` + "`" + `bio` + "`" + ` = ?;", args...)
+	if err != nil {
+		return 0, err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+`,
+			},
+		},
+
+		{
+			Name: "generates increment queries",
+			Fsys: fstest.MapFS{
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "post": {
+      "queries": {
+        "Views": [
+          {
+            "kind": "increq",
+            "name": "ByID",
+            "conditions": [
+              {"col": "postid"}
+            ],
+            "affected": "notfound"
+          }
+        ],
+        "Counts": [
+          {
+            "kind": "increq",
+            "name": "ByUser",
+            "conditions": [
+              {"col": "userid"},
+              {"col": "postid", "cond": "in"}
+            ]
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model post
+	Model struct {
+		Postid  string ` + "`" + `model:"postid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Userid  string ` + "`" + `model:"userid,VARCHAR(31) NOT NULL"` + "`" + `
+		Views   int64  ` + "`" + `model:"views,BIGINT NOT NULL"` + "`" + `
+		Upvotes int64  ` + "`" + `model:"upvotes,BIGINT NOT NULL"` + "`" + `
+	}
+
+	//forge:model:query post
+	Views struct {
+		Views int64 ` + "`" + `model:"views"` + "`" + `
+	}
+
+	//forge:model:query post
+	Counts struct {
+		Views   int64 ` + "`" + `model:"views"` + "`" + `
+		Upvotes int64 ` + "`" + `model:"upvotes"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Output: map[string]string{
+				"model_gen.go": `// Code generated by go generate forge model dev; DO NOT EDIT.
+
+package somepackage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"xorkevin.dev/forge/model/sqldb"
+)
+
+type (
+	postModelTable struct {
+		TableName string
+	}
+)
+
+func (t *postModelTable) Setup(ctx context.Context, d sqldb.Executor) error {
+	_, err := d.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+t.TableName+" (postid VARCHAR(31) PRIMARY KEY, userid VARCHAR(31) NOT NULL, views BIGINT NOT NULL, upvotes BIGINT NOT NULL);")
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *postModelTable) Insert(ctx context.Context, d sqldb.Executor, m *Model) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (postid, userid, views, upvotes) VALUES ($1, $2, $3, $4);", m.Postid, m.Userid, m.Views, m.Upvotes)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *postModelTable) InsertBulk(ctx context.Context, d sqldb.Executor, models []*Model, allowConflict bool) error {
+	conflictSQL := ""
+	if allowConflict {
+		conflictSQL = " ON CONFLICT DO NOTHING"
+	}
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*4)
+	for c, m := range models {
+		n := c * 4
+		placeholders = append(placeholders, fmt.Sprintf("($%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4))
+		args = append(args, m.Postid, m.Userid, m.Views, m.Upvotes)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (postid, userid, views, upvotes) VALUES "+strings.Join(placeholders, ", ")+conflictSQL+";", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *postModelTable) UpdViewsByID(ctx context.Context, d sqldb.Executor, m *Views, postid string) error {
+	res, err := d.ExecContext(ctx, "UPDATE "+t.TableName+" SET views = views + $1 WHERE postid = $2;", m.Views, postid)
+	if err != nil {
+		return err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return sqldb.ErrNotFound
+	}
+	return nil
+}
+
+func (t *postModelTable) UpdCountsByUser(ctx context.Context, d sqldb.Executor, m *Counts, userid string, postids []string) error {
+	paramCount := 3
+	args := make([]interface{}, 0, paramCount+len(postids))
+	args = append(args, m.Views, m.Upvotes, userid)
+	var placeholderspostids string
+	{
+		placeholders := make([]string, 0, len(postids))
+		for _, i := range postids {
+			paramCount++
+			placeholders = append(placeholders, fmt.Sprintf("($%d)", paramCount))
+			args = append(args, i)
+		}
+		placeholderspostids = strings.Join(placeholders, ", ")
+	}
+	_, err := d.ExecContext(ctx, "UPDATE "+t.TableName+" SET (views, upvotes) = (views + $1, upvotes + $2) WHERE userid = $3 AND postid IN (VALUES "+placeholderspostids+");", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+`,
+			},
+		},
+
+		{
+			Name: "generates condition groups",
+			Fsys: fstest.MapFS{
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "doc": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "getgroupeq",
+            "name": "Visible",
+            "conditions": [
+              {
+                "any": [
+                  {"col": "owner"},
+                  {"col": "shared"}
+                ]
+              }
+            ],
+            "order": [
+              {"col": "docid"}
+            ]
+          },
+          {
+            "kind": "counteq",
+            "name": "ByTagsOrTitle",
+            "conditions": [
+              {"col": "owner"},
+              {
+                "any": [
+                  {"col": "tag", "cond": "in"},
+                  {
+                    "all": [
+                      {"col": "title", "cond": "like"},
+                      {"col": "shared"}
+                    ]
+                  }
+                ]
+              },
+              {"col": "docid", "cond": "in"}
+            ]
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model doc
+	//forge:model:query doc
+	Model struct {
+		Docid  string ` + "`" + `model:"docid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Owner  string ` + "`" + `model:"owner,VARCHAR(31) NOT NULL"` + "`" + `
+		Shared bool   ` + "`" + `model:"shared,BOOLEAN NOT NULL"` + "`" + `
+		Tag    string ` + "`" + `model:"tag,VARCHAR(31) NOT NULL"` + "`" + `
+		Title  string ` + "`" + `model:"title,VARCHAR(255) NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Output: map[string]string{
+				"model_gen.go": `// Code generated by go generate forge model dev; DO NOT EDIT.
+
+package somepackage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"xorkevin.dev/forge/model/sqldb"
+)
+
+type (
+	docModelTable struct {
+		TableName string
+	}
+)
+
+func (t *docModelTable) Setup(ctx context.Context, d sqldb.Executor) error {
+	_, err := d.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+t.TableName+" (docid VARCHAR(31) PRIMARY KEY, owner VARCHAR(31) NOT NULL, shared BOOLEAN NOT NULL, tag VARCHAR(31) NOT NULL, title VARCHAR(255) NOT NULL);")
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *docModelTable) Insert(ctx context.Context, d sqldb.Executor, m *Model) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (docid, owner, shared, tag, title) VALUES ($1, $2, $3, $4, $5);", m.Docid, m.Owner, m.Shared, m.Tag, m.Title)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *docModelTable) InsertBulk(ctx context.Context, d sqldb.Executor, models []*Model, allowConflict bool) error {
+	conflictSQL := ""
+	if allowConflict {
+		conflictSQL = " ON CONFLICT DO NOTHING"
+	}
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*5)
+	for c, m := range models {
+		n := c * 5
+		placeholders = append(placeholders, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5))
+		args = append(args, m.Docid, m.Owner, m.Shared, m.Tag, m.Title)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (docid, owner, shared, tag, title) VALUES "+strings.Join(placeholders, ", ")+conflictSQL+";", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *docModelTable) GetModelVisible(ctx context.Context, d sqldb.Executor, owner string, shared bool, limit, offset int) (_ []Model, retErr error) {
+	res := make([]Model, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT docid, owner, shared, tag, title FROM "+t.TableName+" WHERE (owner = $3 OR shared = $4) ORDER BY docid LIMIT $1 OFFSET $2;", limit, offset, owner, shared)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Model
+		if err := rows.Scan(&m.Docid, &m.Owner, &m.Shared, &m.Tag, &m.Title); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *docModelTable) CountModelByTagsOrTitle(ctx context.Context, d sqldb.Executor, owner string, tags []string, titlePrefix string, shared bool, docids []string) (int, error) {
+	paramCount := 3
+	args := make([]interface{}, 0, paramCount+len(tags)+len(docids))
+	args = append(args, owner, titlePrefix, shared)
+	var placeholderstags string
+	{
+		placeholders := make([]string, 0, len(tags))
+		for _, i := range tags {
+			paramCount++
+			placeholders = append(placeholders, fmt.Sprintf("($%d)", paramCount))
+			args = append(args, i)
+		}
+		placeholderstags = strings.Join(placeholders, ", ")
+	}
+	var placeholdersdocids string
+	{
+		placeholders := make([]string, 0, len(docids))
+		for _, i := range docids {
+			paramCount++
+			placeholders = append(placeholders, fmt.Sprintf("($%d)", paramCount))
+			args = append(args, i)
+		}
+		placeholdersdocids = strings.Join(placeholders, ", ")
+	}
+	var count int
+	if err := d.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+t.TableName+" WHERE owner = $1 AND (tag IN (VALUES "+placeholderstags+") OR (title LIKE $2 AND shared = $3)) AND docid IN (VALUES "+placeholdersdocids+");", args...).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+`,
+			},
+		},
+
+		{
+			Name:    "generates mysql condition groups",
+			Dialect: DialectMySQL{},
+			Fsys: fstest.MapFS{
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "doc": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "getgroupeq",
+            "name": "Visible",
+            "conditions": [
+              {
+                "any": [
+                  {"col": "owner"},
+                  {"col": "shared"}
+                ]
+              }
+            ],
+            "order": [
+              {"col": "docid"}
+            ]
+          },
+          {
+            "kind": "counteq",
+            "name": "ByTagsOrTitle",
+            "conditions": [
+              {"col": "owner"},
+              {
+                "any": [
+                  {"col": "tag", "cond": "in"},
+                  {
+                    "all": [
+                      {"col": "title", "cond": "like"},
+                      {"col": "shared"}
+                    ]
+                  }
+                ]
+              },
+              {"col": "docid", "cond": "in"}
+            ]
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model doc
+	//forge:model:query doc
+	Model struct {
+		Docid  string ` + "`" + `model:"docid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Owner  string ` + "`" + `model:"owner,VARCHAR(31) NOT NULL"` + "`" + `
+		Shared bool   ` + "`" + `model:"shared,BOOLEAN NOT NULL"` + "`" + `
+		Tag    string ` + "`" + `model:"tag,VARCHAR(31) NOT NULL"` + "`" + `
+		Title  string ` + "`" + `model:"title,VARCHAR(255) NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Output: map[string]string{
+				"model_gen.go": `// Code generated by go generate forge model dev; DO NOT EDIT.
+
+package somepackage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"xorkevin.dev/forge/model/sqldb"
+)
+
+type (
+	docModelTable struct {
+		TableName string
+	}
+)
+
+func (t *docModelTable) Setup(ctx context.Context, d sqldb.Executor) error {
+	_, err := d.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `docid` + "`" + ` VARCHAR(31) PRIMARY KEY, ` + "`" + `owner` + "`" + ` VARCHAR(31) NOT NULL, ` + "`" + `shared` + "`" + ` BOOLEAN NOT NULL, ` + "`" + `tag` + "`" + ` VARCHAR(31) NOT NULL, ` + "`" + `title` + "`" + ` VARCHAR(255) NOT NULL);")
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *docModelTable) Insert(ctx context.Context, d sqldb.Executor, m *Model) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `docid` + "`" + `, ` + "`" + `owner` + "`" + `, ` + "`" + `shared` + "`" + `, ` + "`" + `tag` + "`" + `, ` + "`" + `title` + "`" + `) VALUES (?, ?, ?, ?, ?);", m.Docid, m.Owner, m.Shared, m.Tag, m.Title)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *docModelTable) InsertBulk(ctx context.Context, d sqldb.Executor, models []*Model, allowConflict bool) error {
+	conflictSQL := ""
+	if allowConflict {
+		conflictSQL = " ON DUPLICATE KEY UPDATE ` + "`" + `docid` + "`" + ` = ` + "`" + `docid` + "`" + `"
+	}
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*5)
+	for _, m := range models {
+		placeholders = append(placeholders, "(?, ?, ?, ?, ?)")
+		args = append(args, m.Docid, m.Owner, m.Shared, m.Tag, m.Title)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `docid` + "`" + `, ` + "`" + `owner` + "`" + `, ` + "`" + `shared` + "`" + `, ` + "`" + `tag` + "`" + `, ` + "`" + `title` + "`" + `) VALUES "+strings.Join(placeholders, ", ")+conflictSQL+";", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *docModelTable) GetModelVisible(ctx context.Context, d sqldb.Executor, owner string, shared bool, limit, offset int) (_ []Model, retErr error) {
+	res := make([]Model, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT ` + "`" + `docid` + "`" + `, ` + "`" + `owner` + "`" + `, ` + "`" + `shared` + "`" + `, ` + "`" + `tag` + "`" + `, ` + "`" + `title` + "`" + ` FROM ` + "`" + `"+t.TableName+"` + "`" + ` WHERE (` + "`" + `owner` + "`" + ` = ? OR ` + "`" + `shared` + "`" + ` = ?) ORDER BY ` + "`" + `docid` + "`" + ` LIMIT ? OFFSET ?;", owner, shared, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Model
+		if err := rows.Scan(&m.Docid, &m.Owner, &m.Shared, &m.Tag, &m.Title); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *docModelTable) CountModelByTagsOrTitle(ctx context.Context, d sqldb.Executor, owner string, tags []string, titlePrefix string, shared bool, docids []string) (int, error) {
+	paramCount := 3
+	args := make([]interface{}, 0, paramCount+len(tags)+len(docids))
+	args = append(args, owner)
+	var placeholderstags string
+	{
+		placeholders := make([]string, 0, len(tags))
+		for _, i := range tags {
+			placeholders = append(placeholders, "?")
+			args = append(args, i)
+		}
+		placeholderstags = strings.Join(placeholders, ", ")
+	}
+	args = append(args, titlePrefix, shared)
+	var placeholdersdocids string
+	{
+		placeholders := make([]string, 0, len(docids))
+		for _, i := range docids {
+			placeholders = append(placeholders, "?")
+			args = append(args, i)
+		}
+		placeholdersdocids = strings.Join(placeholders, ", ")
+	}
+	var count int
+	if err := d.QueryRowContext(ctx, "SELECT COUNT(*) FROM ` + "`" + `"+t.TableName+"` + "`" + ` WHERE ` + "`" + `owner` + "`" + ` = ? AND (` + "`" + `tag` + "`" + ` IN ("+placeholderstags+") OR (` + "`" + `title` + "`" + ` LIKE ? AND ` + "`" + `shared` + "`" + ` = ?)) AND ` + "`" + `docid` + "`" + ` IN ("+placeholdersdocids+");", args...).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+`,
+			},
+		},
+
+		{
+			Name: "generates condition groups with repeated columns",
+			Fsys: fstest.MapFS{
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "player": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "getgroupeq",
+            "name": "OutsideScores",
+            "conditions": [
+              {
+                "any": [
+                  {"col": "score", "cond": "lt"},
+                  {"col": "score", "cond": "gt"}
+                ]
+              }
+            ]
+          },
+          {
+            "kind": "getmapin",
+            "name": "ByIDs",
+            "conditions": [
+              {
+                "any": [
+                  {"col": "playerid", "cond": "in"},
+                  {"col": "score"}
+                ]
+              },
+              {"col": "playerid", "cond": "in"},
+              {"col": "score", "cond": "between"},
+              {"col": "score", "cond": "between"}
+            ]
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model player
+	//forge:model:query player
+	Model struct {
+		Playerid string ` + "`" + `model:"playerid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Score    int    ` + "`" + `model:"score,INT NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Output: map[string]string{
+				"model_gen.go": `// Code generated by go generate forge model dev; DO NOT EDIT.
+
+package somepackage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"xorkevin.dev/forge/model/sqldb"
+)
+
+type (
+	playerModelTable struct {
+		TableName string
+	}
+)
+
+func (t *playerModelTable) Setup(ctx context.Context, d sqldb.Executor) error {
+	_, err := d.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+t.TableName+" (playerid VARCHAR(31) PRIMARY KEY, score INT NOT NULL);")
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *playerModelTable) Insert(ctx context.Context, d sqldb.Executor, m *Model) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (playerid, score) VALUES ($1, $2);", m.Playerid, m.Score)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *playerModelTable) InsertBulk(ctx context.Context, d sqldb.Executor, models []*Model, allowConflict bool) error {
+	conflictSQL := ""
+	if allowConflict {
+		conflictSQL = " ON CONFLICT DO NOTHING"
+	}
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*2)
+	for c, m := range models {
+		n := c * 2
+		placeholders = append(placeholders, fmt.Sprintf("($%d, $%d)", n+1, n+2))
+		args = append(args, m.Playerid, m.Score)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (playerid, score) VALUES "+strings.Join(placeholders, ", ")+conflictSQL+";", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *playerModelTable) GetModelOutsideScores(ctx context.Context, d sqldb.Executor, score int, score2 int, limit, offset int) (_ []Model, retErr error) {
+	res := make([]Model, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT playerid, score FROM "+t.TableName+" WHERE (score < $3 OR score > $4) LIMIT $1 OFFSET $2;", limit, offset, score, score2)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Model
+		if err := rows.Scan(&m.Playerid, &m.Score); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *playerModelTable) GetModelByIDs(ctx context.Context, d sqldb.Executor, playerids []string, score int, playerids2 []string, scoreStart, scoreEnd int, score2Start, score2End int) (_ map[string]Model, retErr error) {
+	res := map[string]Model{}
+	if len(playerids2) == 0 {
+		return res, nil
+	}
+	paramCount := 5
+	args := make([]interface{}, 0, paramCount+len(playerids)+len(playerids2))
+	args = append(args, score, scoreStart, scoreEnd, score2Start, score2End)
+	var placeholdersplayerids string
+	{
+		placeholders := make([]string, 0, len(playerids))
+		for _, i := range playerids {
+			paramCount++
+			placeholders = append(placeholders, fmt.Sprintf("($%d)", paramCount))
+			args = append(args, i)
+		}
+		placeholdersplayerids = strings.Join(placeholders, ", ")
+	}
+	var placeholdersplayerids2 string
+	{
+		placeholders := make([]string, 0, len(playerids2))
+		for _, i := range playerids2 {
+			paramCount++
+			placeholders = append(placeholders, fmt.Sprintf("($%d)", paramCount))
+			args = append(args, i)
+		}
+		placeholdersplayerids2 = strings.Join(placeholders, ", ")
+	}
+	rows, err := d.QueryContext(ctx, "SELECT playerid, score FROM "+t.TableName+" WHERE (playerid IN (VALUES "+placeholdersplayerids+") OR score = $1) AND playerid IN (VALUES "+placeholdersplayerids2+") AND score BETWEEN $2 AND $3 AND score BETWEEN $4 AND $5;", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Model
+		if err := rows.Scan(&m.Playerid, &m.Score); err != nil {
+			return nil, err
+		}
+		res[m.Playerid] = m
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+`,
+			},
+		},
+
+		{
+			Name: "generates null and between conditions",
+			Fsys: fstest.MapFS{
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "job": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "getgroupeq",
+            "name": "Created",
+            "conditions": [
+              {"col": "queue"},
+              {"col": "created", "cond": "between"}
+            ],
+            "order": [
+              {"col": "created"}
+            ]
+          },
+          {
+            "kind": "counteq",
+            "name": "Pending",
+            "conditions": [
+              {"col": "finished", "cond": "isnull"}
+            ]
+          },
+          {
+            "kind": "deleq",
+            "name": "Finished",
+            "conditions": [
+              {"col": "finished", "cond": "notnull"},
+              {"col": "created", "cond": "between"},
+              {"col": "queue", "cond": "in"}
+            ]
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model job
+	//forge:model:query job
+	Model struct {
+		Jobid    string ` + "`" + `model:"jobid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Queue    string ` + "`" + `model:"queue,VARCHAR(31) NOT NULL"` + "`" + `
+		Finished *int64 ` + "`" + `model:"finished,BIGINT"` + "`" + `
+		Created  int64  ` + "`" + `model:"created,BIGINT NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Output: map[string]string{
+				"model_gen.go": `// Code generated by go generate forge model dev; DO NOT EDIT.
+
+package somepackage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"xorkevin.dev/forge/model/sqldb"
+)
+
+type (
+	jobModelTable struct {
+		TableName string
+	}
+)
+
+func (t *jobModelTable) Setup(ctx context.Context, d sqldb.Executor) error {
+	_, err := d.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+t.TableName+" (jobid VARCHAR(31) PRIMARY KEY, queue VARCHAR(31) NOT NULL, finished BIGINT, created BIGINT NOT NULL);")
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *jobModelTable) Insert(ctx context.Context, d sqldb.Executor, m *Model) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (jobid, queue, finished, created) VALUES ($1, $2, $3, $4);", m.Jobid, m.Queue, m.Finished, m.Created)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *jobModelTable) InsertBulk(ctx context.Context, d sqldb.Executor, models []*Model, allowConflict bool) error {
+	conflictSQL := ""
+	if allowConflict {
+		conflictSQL = " ON CONFLICT DO NOTHING"
+	}
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*4)
+	for c, m := range models {
+		n := c * 4
+		placeholders = append(placeholders, fmt.Sprintf("($%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4))
+		args = append(args, m.Jobid, m.Queue, m.Finished, m.Created)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (jobid, queue, finished, created) VALUES "+strings.Join(placeholders, ", ")+conflictSQL+";", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *jobModelTable) GetModelCreated(ctx context.Context, d sqldb.Executor, queue string, createdStart, createdEnd int64, limit, offset int) (_ []Model, retErr error) {
+	res := make([]Model, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT jobid, queue, finished, created FROM "+t.TableName+" WHERE queue = $3 AND created BETWEEN $4 AND $5 ORDER BY created LIMIT $1 OFFSET $2;", limit, offset, queue, createdStart, createdEnd)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Model
+		if err := rows.Scan(&m.Jobid, &m.Queue, &m.Finished, &m.Created); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *jobModelTable) CountModelPending(ctx context.Context, d sqldb.Executor) (int, error) {
+	var count int
+	if err := d.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+t.TableName+" WHERE finished IS NULL;").Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (t *jobModelTable) DelFinished(ctx context.Context, d sqldb.Executor, createdStart, createdEnd int64, queues []string) error {
+	paramCount := 2
+	args := make([]interface{}, 0, paramCount+len(queues))
+	args = append(args, createdStart, createdEnd)
+	var placeholdersqueues string
+	{
+		placeholders := make([]string, 0, len(queues))
+		for _, i := range queues {
+			paramCount++
+			placeholders = append(placeholders, fmt.Sprintf("($%d)", paramCount))
+			args = append(args, i)
+		}
+		placeholdersqueues = strings.Join(placeholders, ", ")
+	}
+	_, err := d.ExecContext(ctx, "DELETE FROM "+t.TableName+" WHERE finished IS NOT NULL AND created BETWEEN $1 AND $2 AND queue IN (VALUES "+placeholdersqueues+");", args...)
+	return err
+}
+`,
+			},
+		},
+
+		{
+			Name: "generates like mode conditions",
+			Fsys: fstest.MapFS{
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "getgroupeq",
+            "name": "Search",
+            "conditions": [
+              {"col": "username", "cond": "prefix"},
+              {"col": "email", "cond": "icontains"}
+            ],
+            "order": [
+              {"col": "username"}
+            ]
+          },
+          {
+            "kind": "counteq",
+            "name": "Domain",
+            "conditions": [
+              {"col": "email", "cond": "isuffix"},
+              {"col": "userid", "cond": "in"}
+            ]
+          },
+          {
+            "kind": "existseq",
+            "name": "Name",
+            "conditions": [
+              {"col": "username", "cond": "contains"},
+              {"col": "username", "cond": "suffix"},
+              {"col": "email", "cond": "iprefix"}
+            ]
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL UNIQUE"` + "`" + `
+		Email    string ` + "`" + `model:"email,VARCHAR(255) NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Output: map[string]string{
+				"model_gen.go": `// Code generated by go generate forge model dev; DO NOT EDIT.
+
+package somepackage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"xorkevin.dev/forge/model/sqldb"
+)
+
+type (
+	userModelTable struct {
+		TableName string
+	}
+)
+
+func (t *userModelTable) Setup(ctx context.Context, d sqldb.Executor) error {
+	_, err := d.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+t.TableName+" (userid VARCHAR(31) PRIMARY KEY, username VARCHAR(255) NOT NULL UNIQUE, email VARCHAR(255) NOT NULL);")
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) Insert(ctx context.Context, d sqldb.Executor, m *Model) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (userid, username, email) VALUES ($1, $2, $3);", m.Userid, m.Username, m.Email)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) InsertBulk(ctx context.Context, d sqldb.Executor, models []*Model, allowConflict bool) error {
+	conflictSQL := ""
+	if allowConflict {
+		conflictSQL = " ON CONFLICT DO NOTHING"
+	}
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*3)
+	for c, m := range models {
+		n := c * 3
+		placeholders = append(placeholders, fmt.Sprintf("($%d, $%d, $%d)", n+1, n+2, n+3))
+		args = append(args, m.Userid, m.Username, m.Email)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (userid, username, email) VALUES "+strings.Join(placeholders, ", ")+conflictSQL+";", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) GetModelSearch(ctx context.Context, d sqldb.Executor, usernamePrefix string, emailSubstr string, limit, offset int) (_ []Model, retErr error) {
+	res := make([]Model, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT userid, username, email FROM "+t.TableName+" WHERE username LIKE $3 ESCAPE '!' AND email ILIKE $4 ESCAPE '!' ORDER BY username LIMIT $1 OFFSET $2;", limit, offset, sqldb.EscapeLike(usernamePrefix)+"%", "%"+sqldb.EscapeLike(emailSubstr)+"%")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Model
+		if err := rows.Scan(&m.Userid, &m.Username, &m.Email); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *userModelTable) CountModelDomain(ctx context.Context, d sqldb.Executor, emailSuffix string, userids []string) (int, error) {
+	paramCount := 1
+	args := make([]interface{}, 0, paramCount+len(userids))
+	args = append(args, "%"+sqldb.EscapeLike(emailSuffix))
+	var placeholdersuserids string
+	{
+		placeholders := make([]string, 0, len(userids))
+		for _, i := range userids {
+			paramCount++
+			placeholders = append(placeholders, fmt.Sprintf("($%d)", paramCount))
+			args = append(args, i)
+		}
+		placeholdersuserids = strings.Join(placeholders, ", ")
+	}
+	var count int
+	if err := d.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+t.TableName+" WHERE email ILIKE $1 ESCAPE '!' AND userid IN (VALUES "+placeholdersuserids+");", args...).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (t *userModelTable) ExistsModelName(ctx context.Context, d sqldb.Executor, usernameSubstr string, usernameSuffix string, emailPrefix string) (bool, error) {
+	var exists bool
+	if err := d.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM "+t.TableName+" WHERE username LIKE $1 ESCAPE '!' AND username LIKE $2 ESCAPE '!' AND email ILIKE $3 ESCAPE '!');", "%"+sqldb.EscapeLike(usernameSubstr)+"%", "%"+sqldb.EscapeLike(usernameSuffix), sqldb.EscapeLike(emailPrefix)+"%").Scan(&exists); err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+`,
+			},
+		},
+
+		{
+			Name:    "generates sqlite like mode conditions",
+			Dialect: DialectSQLite{},
+			Fsys: fstest.MapFS{
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "getgroupeq",
+            "name": "Search",
+            "conditions": [
+              {"col": "username", "cond": "prefix"},
+              {"col": "email", "cond": "icontains"}
+            ],
+            "order": [
+              {"col": "username"}
+            ]
+          },
+          {
+            "kind": "counteq",
+            "name": "Domain",
+            "conditions": [
+              {"col": "email", "cond": "isuffix"},
+              {"col": "userid", "cond": "in"}
+            ]
+          },
+          {
+            "kind": "existseq",
+            "name": "Name",
+            "conditions": [
+              {"col": "username", "cond": "contains"},
+              {"col": "username", "cond": "suffix"},
+              {"col": "email", "cond": "iprefix"}
+            ]
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL UNIQUE"` + "`" + `
+		Email    string ` + "`" + `model:"email,VARCHAR(255) NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Output: map[string]string{
+				"model_gen.go": `// Code generated by go generate forge model dev; DO NOT EDIT.
+
+package somepackage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"xorkevin.dev/forge/model/sqldb"
+)
+
+type (
+	userModelTable struct {
+		TableName string
+	}
+)
+
+func (t *userModelTable) Setup(ctx context.Context, d sqldb.Executor) error {
+	_, err := d.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+t.TableName+" (userid VARCHAR(31) PRIMARY KEY, username VARCHAR(255) NOT NULL UNIQUE, email VARCHAR(255) NOT NULL);")
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) Insert(ctx context.Context, d sqldb.Executor, m *Model) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (userid, username, email) VALUES (?1, ?2, ?3);", m.Userid, m.Username, m.Email)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) InsertBulk(ctx context.Context, d sqldb.Executor, models []*Model, allowConflict bool) error {
+	conflictSQL := ""
+	if allowConflict {
+		conflictSQL = " ON CONFLICT DO NOTHING"
+	}
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*3)
+	for c, m := range models {
+		n := c * 3
+		placeholders = append(placeholders, fmt.Sprintf("(?%d, ?%d, ?%d)", n+1, n+2, n+3))
+		args = append(args, m.Userid, m.Username, m.Email)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (userid, username, email) VALUES "+strings.Join(placeholders, ", ")+conflictSQL+";", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) GetModelSearch(ctx context.Context, d sqldb.Executor, usernamePrefix string, emailSubstr string, limit, offset int) (_ []Model, retErr error) {
+	res := make([]Model, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT userid, username, email FROM "+t.TableName+" WHERE username LIKE ?3 ESCAPE '!' AND LOWER(email) LIKE LOWER(?4) ESCAPE '!' ORDER BY username LIMIT ?1 OFFSET ?2;", limit, offset, sqldb.EscapeLike(usernamePrefix)+"%", "%"+sqldb.EscapeLike(emailSubstr)+"%")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Model
+		if err := rows.Scan(&m.Userid, &m.Username, &m.Email); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *userModelTable) CountModelDomain(ctx context.Context, d sqldb.Executor, emailSuffix string, userids []string) (int, error) {
+	paramCount := 1
+	args := make([]interface{}, 0, paramCount+len(userids))
+	args = append(args, "%"+sqldb.EscapeLike(emailSuffix))
+	var placeholdersuserids string
+	{
+		placeholders := make([]string, 0, len(userids))
+		for _, i := range userids {
+			paramCount++
+			placeholders = append(placeholders, fmt.Sprintf("?%d", paramCount))
+			args = append(args, i)
+		}
+		placeholdersuserids = strings.Join(placeholders, ", ")
+	}
+	var count int
+	if err := d.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+t.TableName+" WHERE LOWER(email) LIKE LOWER(?1) ESCAPE '!' AND userid IN ("+placeholdersuserids+");", args...).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (t *userModelTable) ExistsModelName(ctx context.Context, d sqldb.Executor, usernameSubstr string, usernameSuffix string, emailPrefix string) (bool, error) {
+	var exists bool
+	if err := d.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM "+t.TableName+" WHERE username LIKE ?1 ESCAPE '!' AND username LIKE ?2 ESCAPE '!' AND LOWER(email) LIKE LOWER(?3) ESCAPE '!');", "%"+sqldb.EscapeLike(usernameSubstr)+"%", "%"+sqldb.EscapeLike(usernameSuffix), sqldb.EscapeLike(emailPrefix)+"%").Scan(&exists); err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+`,
+			},
+		},
+		{
+			Name:    "generates mysql like mode conditions",
+			Dialect: DialectMySQL{},
+			Fsys: fstest.MapFS{
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "getgroupeq",
+            "name": "Search",
+            "conditions": [
+              {"col": "username", "cond": "prefix"},
+              {"col": "email", "cond": "icontains"}
+            ],
+            "order": [
+              {"col": "username"}
+            ]
+          },
+          {
+            "kind": "counteq",
+            "name": "Domain",
+            "conditions": [
+              {"col": "email", "cond": "isuffix"},
+              {"col": "userid", "cond": "in"}
+            ]
+          },
+          {
+            "kind": "existseq",
+            "name": "Name",
+            "conditions": [
+              {"col": "username", "cond": "contains"},
+              {"col": "username", "cond": "suffix"},
+              {"col": "email", "cond": "iprefix"}
+            ]
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL UNIQUE"` + "`" + `
+		Email    string ` + "`" + `model:"email,VARCHAR(255) NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Output: map[string]string{
+				"model_gen.go": `// Code generated by go generate forge model dev; DO NOT EDIT.
+
+package somepackage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"xorkevin.dev/forge/model/sqldb"
+)
+
+type (
+	userModelTable struct {
+		TableName string
+	}
+)
+
+func (t *userModelTable) Setup(ctx context.Context, d sqldb.Executor) error {
+	_, err := d.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `userid` + "`" + ` VARCHAR(31) PRIMARY KEY, ` + "`" + `username` + "`" + ` VARCHAR(255) NOT NULL UNIQUE, ` + "`" + `email` + "`" + ` VARCHAR(255) NOT NULL);")
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) Insert(ctx context.Context, d sqldb.Executor, m *Model) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `userid` + "`" + `, ` + "`" + `username` + "`" + `, ` + "`" + `email` + "`" + `) VALUES (?, ?, ?);", m.Userid, m.Username, m.Email)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) InsertBulk(ctx context.Context, d sqldb.Executor, models []*Model, allowConflict bool) error {
+	conflictSQL := ""
+	if allowConflict {
+		conflictSQL = " ON DUPLICATE KEY UPDATE ` + "`" + `userid` + "`" + ` = ` + "`" + `userid` + "`" + `"
+	}
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*3)
+	for _, m := range models {
+		placeholders = append(placeholders, "(?, ?, ?)")
+		args = append(args, m.Userid, m.Username, m.Email)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `userid` + "`" + `, ` + "`" + `username` + "`" + `, ` + "`" + `email` + "`" + `) VALUES "+strings.Join(placeholders, ", ")+conflictSQL+";", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) GetModelSearch(ctx context.Context, d sqldb.Executor, usernamePrefix string, emailSubstr string, limit, offset int) (_ []Model, retErr error) {
+	res := make([]Model, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT ` + "`" + `userid` + "`" + `, ` + "`" + `username` + "`" + `, ` + "`" + `email` + "`" + ` FROM ` + "`" + `"+t.TableName+"` + "`" + ` WHERE ` + "`" + `username` + "`" + ` LIKE ? ESCAPE '!' AND LOWER(` + "`" + `email` + "`" + `) LIKE LOWER(?) ESCAPE '!' ORDER BY ` + "`" + `username` + "`" + ` LIMIT ? OFFSET ?;", sqldb.EscapeLike(usernamePrefix)+"%", "%"+sqldb.EscapeLike(emailSubstr)+"%", limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Model
+		if err := rows.Scan(&m.Userid, &m.Username, &m.Email); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *userModelTable) CountModelDomain(ctx context.Context, d sqldb.Executor, emailSuffix string, userids []string) (int, error) {
+	paramCount := 1
+	args := make([]interface{}, 0, paramCount+len(userids))
+	args = append(args, "%"+sqldb.EscapeLike(emailSuffix))
+	var placeholdersuserids string
+	{
+		placeholders := make([]string, 0, len(userids))
+		for _, i := range userids {
+			placeholders = append(placeholders, "?")
+			args = append(args, i)
+		}
+		placeholdersuserids = strings.Join(placeholders, ", ")
+	}
+	var count int
+	if err := d.QueryRowContext(ctx, "SELECT COUNT(*) FROM ` + "`" + `"+t.TableName+"` + "`" + ` WHERE LOWER(` + "`" + `email` + "`" + `) LIKE LOWER(?) ESCAPE '!' AND ` + "`" + `userid` + "`" + ` IN ("+placeholdersuserids+");", args...).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (t *userModelTable) ExistsModelName(ctx context.Context, d sqldb.Executor, usernameSubstr string, usernameSuffix string, emailPrefix string) (bool, error) {
+	var exists bool
+	if err := d.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM ` + "`" + `"+t.TableName+"` + "`" + ` WHERE ` + "`" + `username` + "`" + ` LIKE ? ESCAPE '!' AND ` + "`" + `username` + "`" + ` LIKE ? ESCAPE '!' AND LOWER(` + "`" + `email` + "`" + `) LIKE LOWER(?) ESCAPE '!');", "%"+sqldb.EscapeLike(usernameSubstr)+"%", "%"+sqldb.EscapeLike(usernameSuffix), sqldb.EscapeLike(emailPrefix)+"%").Scan(&exists); err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+`,
+			},
+		},
+
+		{
+			Name: "generates array and json conditions",
+			Fsys: fstest.MapFS{
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "post": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "getgroupeq",
+            "name": "Tagged",
+            "conditions": [
+              {"col": "tags", "cond": "arrcontains"},
+              {"col": "status", "cond": "eqany"}
+            ],
+            "order": [
+              {"col": "postid"}
+            ]
+          },
+          {
+            "kind": "counteq",
+            "name": "AnyTags",
+            "conditions": [
+              {"col": "tags", "cond": "arroverlap"}
+            ]
+          },
+          {
+            "kind": "deleq",
+            "name": "Meta",
+            "conditions": [
+              {"col": "meta", "cond": "jsoncontains"},
+              {"col": "meta", "cond": "jsonhaskey"}
+            ]
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model post
+	//forge:model:query post
+	Model struct {
+		Postid string   ` + "`" + `model:"postid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Status string   ` + "`" + `model:"status,VARCHAR(31) NOT NULL"` + "`" + `
+		Tags   []string ` + "`" + `model:"tags,TEXT[] NOT NULL"` + "`" + `
+		Meta   []byte   ` + "`" + `model:"meta,JSONB NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Output: map[string]string{
+				"model_gen.go": `// Code generated by go generate forge model dev; DO NOT EDIT.
+
+package somepackage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"xorkevin.dev/forge/model/sqldb"
+)
+
+type (
+	postModelTable struct {
+		TableName string
+	}
+)
+
+func (t *postModelTable) Setup(ctx context.Context, d sqldb.Executor) error {
+	_, err := d.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+t.TableName+" (postid VARCHAR(31) PRIMARY KEY, status VARCHAR(31) NOT NULL, tags TEXT[] NOT NULL, meta JSONB NOT NULL);")
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *postModelTable) Insert(ctx context.Context, d sqldb.Executor, m *Model) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (postid, status, tags, meta) VALUES ($1, $2, $3, $4);", m.Postid, m.Status, m.Tags, m.Meta)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *postModelTable) InsertBulk(ctx context.Context, d sqldb.Executor, models []*Model, allowConflict bool) error {
+	conflictSQL := ""
+	if allowConflict {
+		conflictSQL = " ON CONFLICT DO NOTHING"
+	}
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*4)
+	for c, m := range models {
+		n := c * 4
+		placeholders = append(placeholders, fmt.Sprintf("($%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4))
+		args = append(args, m.Postid, m.Status, m.Tags, m.Meta)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (postid, status, tags, meta) VALUES "+strings.Join(placeholders, ", ")+conflictSQL+";", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *postModelTable) GetModelTagged(ctx context.Context, d sqldb.Executor, tags []string, statuss []string, limit, offset int) (_ []Model, retErr error) {
+	res := make([]Model, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT postid, status, tags, meta FROM "+t.TableName+" WHERE tags @> $3 AND status = ANY($4) ORDER BY postid LIMIT $1 OFFSET $2;", limit, offset, tags, statuss)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Model
+		if err := rows.Scan(&m.Postid, &m.Status, &m.Tags, &m.Meta); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *postModelTable) CountModelAnyTags(ctx context.Context, d sqldb.Executor, tags []string) (int, error) {
+	var count int
+	if err := d.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+t.TableName+" WHERE tags && $1;", tags).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (t *postModelTable) DelMeta(ctx context.Context, d sqldb.Executor, meta []byte, metaKey string) error {
+	_, err := d.ExecContext(ctx, "DELETE FROM "+t.TableName+" WHERE meta @> $1 AND meta ? $2;", meta, metaKey)
+	return err
+}
+`,
+			},
+		},
+
+		{
+			Name: "generates sort queries",
+			Fsys: fstest.MapFS{
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "getgroup",
+            "name": "All",
+            "sort": [
+              {
+                "name": "Username",
+                "order": [
+                  {"col": "username"}
+                ]
+              },
+              {
+                "name": "EmailDesc",
+                "order": [
+                  {"col": "email", "dir": "DESC"},
+                  {"col": "userid"}
+                ]
+              }
+            ],
+            "stream": true
+          },
+          {
+            "kind": "getgroupeq",
+            "name": "ByEmail",
+            "conditions": [
+              {"col": "email"}
+            ],
+            "sort": [
+              {
+                "name": "Userid",
+                "order": [
+                  {"col": "userid"}
+                ]
+              }
+            ]
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL UNIQUE"` + "`" + `
+		Email    string ` + "`" + `model:"email,VARCHAR(255) NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Output: map[string]string{
+				"model_gen.go": `// Code generated by go generate forge model dev; DO NOT EDIT.
+
+package somepackage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"xorkevin.dev/forge/model/sqldb"
+)
+
+type (
+	userModelTable struct {
+		TableName string
+	}
+)
+
+func (t *userModelTable) Setup(ctx context.Context, d sqldb.Executor) error {
+	_, err := d.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+t.TableName+" (userid VARCHAR(31) PRIMARY KEY, username VARCHAR(255) NOT NULL UNIQUE, email VARCHAR(255) NOT NULL);")
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) Insert(ctx context.Context, d sqldb.Executor, m *Model) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (userid, username, email) VALUES ($1, $2, $3);", m.Userid, m.Username, m.Email)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) InsertBulk(ctx context.Context, d sqldb.Executor, models []*Model, allowConflict bool) error {
+	conflictSQL := ""
+	if allowConflict {
+		conflictSQL = " ON CONFLICT DO NOTHING"
+	}
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*3)
+	for c, m := range models {
+		n := c * 3
+		placeholders = append(placeholders, fmt.Sprintf("($%d, $%d, $%d)", n+1, n+2, n+3))
+		args = append(args, m.Userid, m.Username, m.Email)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (userid, username, email) VALUES "+strings.Join(placeholders, ", ")+conflictSQL+";", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+type (
+	// ModelAllSort is a sort of GetModelAll
+	ModelAllSort int
+)
+
+const (
+	ModelAllSortUsername ModelAllSort = iota
+	ModelAllSortEmailDesc
+)
+
+func (t *userModelTable) GetModelAll(ctx context.Context, d sqldb.Executor, sort ModelAllSort, limit, offset int) (_ []Model, retErr error) {
+	// unknown sorts use the first sort
+	orderBy := "username"
+	switch sort {
+	case ModelAllSortEmailDesc:
+		orderBy = "email DESC, userid"
+	}
+	res := make([]Model, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT userid, username, email FROM "+t.TableName+" ORDER BY "+orderBy+" LIMIT $1 OFFSET $2;", limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Model
+		if err := rows.Scan(&m.Userid, &m.Username, &m.Email); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *userModelTable) StreamModelAll(ctx context.Context, d sqldb.Executor, sort ModelAllSort, fn func(m Model) error) (retErr error) {
+	// unknown sorts use the first sort
+	orderBy := "username"
+	switch sort {
+	case ModelAllSortEmailDesc:
+		orderBy = "email DESC, userid"
+	}
+	rows, err := d.QueryContext(ctx, "SELECT userid, username, email FROM "+t.TableName+" ORDER BY "+orderBy+";")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Model
+		if err := rows.Scan(&m.Userid, &m.Username, &m.Email); err != nil {
+			return err
+		}
+		if err := fn(m); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return nil
+}
+
+type (
+	// ModelByEmailSort is a sort of GetModelByEmail
+	ModelByEmailSort int
+)
+
+const (
+	ModelByEmailSortUserid ModelByEmailSort = iota
+)
+
+func (t *userModelTable) GetModelByEmail(ctx context.Context, d sqldb.Executor, email string, sort ModelByEmailSort, limit, offset int) (_ []Model, retErr error) {
+	// unknown sorts use the first sort
+	orderBy := "userid"
+	res := make([]Model, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT userid, username, email FROM "+t.TableName+" WHERE email = $3 ORDER BY "+orderBy+" LIMIT $1 OFFSET $2;", limit, offset, email)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Model
+		if err := rows.Scan(&m.Userid, &m.Username, &m.Email); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+`,
+			},
+		},
+
+		{
+			Name: "generates filter builder",
+			Fsys: fstest.MapFS{
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "model": {
+        "filter": true
+      },
+      "queries": {
+        "Model": [
+          {
+            "kind": "getoneeq",
+            "name": "ByID",
+            "conditions": [
+              {"col": "userid"}
+            ]
+          }
+        ],
+        "Info": [
+          {
+            "kind": "getgroup",
+            "name": "All"
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL UNIQUE"` + "`" + `
+		Created  int64  ` + "`" + `model:"created,BIGINT NOT NULL"` + "`" + `
+	}
+
+	//forge:model:query user
+	Info struct {
+		Userid   string ` + "`" + `model:"userid"` + "`" + `
+		Username string ` + "`" + `model:"username"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Output: map[string]string{
+				"model_gen.go": `// Code generated by go generate forge model dev; DO NOT EDIT.
+
+package somepackage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"xorkevin.dev/forge/model/sqldb"
+)
+
+type (
+	userModelTable struct {
+		TableName string
+	}
+)
+
+func (t *userModelTable) Setup(ctx context.Context, d sqldb.Executor) error {
+	_, err := d.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+t.TableName+" (userid VARCHAR(31) PRIMARY KEY, username VARCHAR(255) NOT NULL UNIQUE, created BIGINT NOT NULL);")
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) Insert(ctx context.Context, d sqldb.Executor, m *Model) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (userid, username, created) VALUES ($1, $2, $3);", m.Userid, m.Username, m.Created)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) InsertBulk(ctx context.Context, d sqldb.Executor, models []*Model, allowConflict bool) error {
+	conflictSQL := ""
+	if allowConflict {
+		conflictSQL = " ON CONFLICT DO NOTHING"
+	}
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*3)
+	for c, m := range models {
+		n := c * 3
+		placeholders = append(placeholders, fmt.Sprintf("($%d, $%d, $%d)", n+1, n+2, n+3))
+		args = append(args, m.Userid, m.Username, m.Created)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (userid, username, created) VALUES "+strings.Join(placeholders, ", ")+conflictSQL+";", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) GetModelByID(ctx context.Context, d sqldb.Executor, userid string) (*Model, error) {
+	m := &Model{}
+	if err := d.QueryRowContext(ctx, "SELECT userid, username, created FROM "+t.TableName+" WHERE userid = $1;", userid).Scan(&m.Userid, &m.Username, &m.Created); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (t *userModelTable) GetInfoAll(ctx context.Context, d sqldb.Executor, limit, offset int) (_ []Info, retErr error) {
+	res := make([]Info, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT userid, username FROM "+t.TableName+" LIMIT $1 OFFSET $2;", limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Info
+		if err := rows.Scan(&m.Userid, &m.Username); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+type (
+	// userModelFilter builds a query of userModelTable with
+	// conditions joined by AND
+	userModelFilter struct {
+		t      *userModelTable
+		conds  []string
+		args   []interface{}
+		order  []string
+		limit  int
+		offset int
+	}
+)
+
+func (t *userModelTable) Filter() *userModelFilter {
+	return &userModelFilter{
+		t: t,
+	}
+}
+
+func (f *userModelFilter) WhereUseridEq(vUserid string) *userModelFilter {
+	f.args = append(f.args, vUserid)
+	f.conds = append(f.conds, fmt.Sprintf("userid = $%d", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereUseridNeq(vUserid string) *userModelFilter {
+	f.args = append(f.args, vUserid)
+	f.conds = append(f.conds, fmt.Sprintf("userid <> $%d", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereUseridLt(vUserid string) *userModelFilter {
+	f.args = append(f.args, vUserid)
+	f.conds = append(f.conds, fmt.Sprintf("userid < $%d", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereUseridLeq(vUserid string) *userModelFilter {
+	f.args = append(f.args, vUserid)
+	f.conds = append(f.conds, fmt.Sprintf("userid <= $%d", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereUseridGt(vUserid string) *userModelFilter {
+	f.args = append(f.args, vUserid)
+	f.conds = append(f.conds, fmt.Sprintf("userid > $%d", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereUseridGeq(vUserid string) *userModelFilter {
+	f.args = append(f.args, vUserid)
+	f.conds = append(f.conds, fmt.Sprintf("userid >= $%d", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereUseridIn(vUserids []string) *userModelFilter {
+	if len(vUserids) == 0 {
+		// an empty IN list is invalid sql, and matches no rows
+		f.conds = append(f.conds, "1 = 0")
+		return f
+	}
+	placeholders := make([]string, 0, len(vUserids))
+	for _, i := range vUserids {
+		f.args = append(f.args, i)
+		placeholders = append(placeholders, fmt.Sprintf("($%d)", len(f.args)))
+	}
+	f.conds = append(f.conds, "userid IN (VALUES "+strings.Join(placeholders, ", ")+")")
+	return f
+}
+
+// WhereUseridLike matches a raw LIKE pattern with an ESCAPE of
+// sqldb.LikeEscape, and text of the pattern which is matched literally may be
+// escaped by sqldb.EscapeLike
+func (f *userModelFilter) WhereUseridLike(vUseridPattern string) *userModelFilter {
+	f.args = append(f.args, vUseridPattern)
+	f.conds = append(f.conds, fmt.Sprintf("userid LIKE $%d ESCAPE '!'", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereUseridIsNull() *userModelFilter {
+	f.conds = append(f.conds, "userid IS NULL")
+	return f
+}
+
+func (f *userModelFilter) WhereUseridNotNull() *userModelFilter {
+	f.conds = append(f.conds, "userid IS NOT NULL")
+	return f
+}
+
+func (f *userModelFilter) OrderUserid(desc bool) *userModelFilter {
+	if desc {
+		f.order = append(f.order, "userid DESC")
+	} else {
+		f.order = append(f.order, "userid")
+	}
+	return f
+}
+
+func (f *userModelFilter) WhereUsernameEq(vUsername string) *userModelFilter {
+	f.args = append(f.args, vUsername)
+	f.conds = append(f.conds, fmt.Sprintf("username = $%d", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereUsernameNeq(vUsername string) *userModelFilter {
+	f.args = append(f.args, vUsername)
+	f.conds = append(f.conds, fmt.Sprintf("username <> $%d", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereUsernameLt(vUsername string) *userModelFilter {
+	f.args = append(f.args, vUsername)
+	f.conds = append(f.conds, fmt.Sprintf("username < $%d", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereUsernameLeq(vUsername string) *userModelFilter {
+	f.args = append(f.args, vUsername)
+	f.conds = append(f.conds, fmt.Sprintf("username <= $%d", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereUsernameGt(vUsername string) *userModelFilter {
+	f.args = append(f.args, vUsername)
+	f.conds = append(f.conds, fmt.Sprintf("username > $%d", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereUsernameGeq(vUsername string) *userModelFilter {
+	f.args = append(f.args, vUsername)
+	f.conds = append(f.conds, fmt.Sprintf("username >= $%d", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereUsernameIn(vUsernames []string) *userModelFilter {
+	if len(vUsernames) == 0 {
+		// an empty IN list is invalid sql, and matches no rows
+		f.conds = append(f.conds, "1 = 0")
+		return f
+	}
+	placeholders := make([]string, 0, len(vUsernames))
+	for _, i := range vUsernames {
+		f.args = append(f.args, i)
+		placeholders = append(placeholders, fmt.Sprintf("($%d)", len(f.args)))
+	}
+	f.conds = append(f.conds, "username IN (VALUES "+strings.Join(placeholders, ", ")+")")
+	return f
+}
+
+// WhereUsernameLike matches a raw LIKE pattern with an ESCAPE of
+// sqldb.LikeEscape, and text of the pattern which is matched literally may be
+// escaped by sqldb.EscapeLike
+func (f *userModelFilter) WhereUsernameLike(vUsernamePattern string) *userModelFilter {
+	f.args = append(f.args, vUsernamePattern)
+	f.conds = append(f.conds, fmt.Sprintf("username LIKE $%d ESCAPE '!'", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereUsernameIsNull() *userModelFilter {
+	f.conds = append(f.conds, "username IS NULL")
+	return f
+}
+
+func (f *userModelFilter) WhereUsernameNotNull() *userModelFilter {
+	f.conds = append(f.conds, "username IS NOT NULL")
+	return f
+}
+
+func (f *userModelFilter) OrderUsername(desc bool) *userModelFilter {
+	if desc {
+		f.order = append(f.order, "username DESC")
+	} else {
+		f.order = append(f.order, "username")
+	}
+	return f
+}
+
+func (f *userModelFilter) WhereCreatedEq(vCreated int64) *userModelFilter {
+	f.args = append(f.args, vCreated)
+	f.conds = append(f.conds, fmt.Sprintf("created = $%d", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereCreatedNeq(vCreated int64) *userModelFilter {
+	f.args = append(f.args, vCreated)
+	f.conds = append(f.conds, fmt.Sprintf("created <> $%d", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereCreatedLt(vCreated int64) *userModelFilter {
+	f.args = append(f.args, vCreated)
+	f.conds = append(f.conds, fmt.Sprintf("created < $%d", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereCreatedLeq(vCreated int64) *userModelFilter {
+	f.args = append(f.args, vCreated)
+	f.conds = append(f.conds, fmt.Sprintf("created <= $%d", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereCreatedGt(vCreated int64) *userModelFilter {
+	f.args = append(f.args, vCreated)
+	f.conds = append(f.conds, fmt.Sprintf("created > $%d", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereCreatedGeq(vCreated int64) *userModelFilter {
+	f.args = append(f.args, vCreated)
+	f.conds = append(f.conds, fmt.Sprintf("created >= $%d", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereCreatedIn(vCreateds []int64) *userModelFilter {
+	if len(vCreateds) == 0 {
+		// an empty IN list is invalid sql, and matches no rows
+		f.conds = append(f.conds, "1 = 0")
+		return f
+	}
+	placeholders := make([]string, 0, len(vCreateds))
+	for _, i := range vCreateds {
+		f.args = append(f.args, i)
+		placeholders = append(placeholders, fmt.Sprintf("($%d)", len(f.args)))
+	}
+	f.conds = append(f.conds, "created IN (VALUES "+strings.Join(placeholders, ", ")+")")
+	return f
+}
+
+func (f *userModelFilter) WhereCreatedIsNull() *userModelFilter {
+	f.conds = append(f.conds, "created IS NULL")
+	return f
+}
+
+func (f *userModelFilter) WhereCreatedNotNull() *userModelFilter {
+	f.conds = append(f.conds, "created IS NOT NULL")
+	return f
+}
+
+func (f *userModelFilter) OrderCreated(desc bool) *userModelFilter {
+	if desc {
+		f.order = append(f.order, "created DESC")
+	} else {
+		f.order = append(f.order, "created")
+	}
+	return f
+}
+
+// Limit limits the number of rows of the query, and is not applied if zero or
+// negative
+func (f *userModelFilter) Limit(limit int) *userModelFilter {
+	if limit < 0 {
+		limit = 0
+	}
+	f.limit = limit
+	return f
+}
+
+// Offset skips rows of the query, and is only applied with a limit
+func (f *userModelFilter) Offset(offset int) *userModelFilter {
+	f.offset = offset
+	return f
+}
+
+func (f *userModelFilter) query(cols string) (string, []interface{}) {
+	t := f.t
+	query := "SELECT " + cols + " FROM " + t.TableName
+	conds := f.conds
+	if len(conds) != 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	if len(f.order) != 0 {
+		query += " ORDER BY " + strings.Join(f.order, ", ")
+	}
+	args := make([]interface{}, 0, len(f.args)+2)
+	args = append(args, f.args...)
+	if f.limit > 0 {
+		args = append(args, f.limit)
+		if f.offset > 0 {
+			args = append(args, f.offset)
+			query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+		} else {
+			query += fmt.Sprintf(" LIMIT $%d", len(args))
+		}
+	}
+	return query + ";", args
+}
+
+func (f *userModelFilter) GetModel(ctx context.Context, d sqldb.Executor) (_ []Model, retErr error) {
+	query, args := f.query("userid, username, created")
+	res := make([]Model, 0, f.limit)
+	rows, err := d.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Model
+		if err := rows.Scan(&m.Userid, &m.Username, &m.Created); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (f *userModelFilter) GetInfo(ctx context.Context, d sqldb.Executor) (_ []Info, retErr error) {
+	query, args := f.query("userid, username")
+	res := make([]Info, 0, f.limit)
+	rows, err := d.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Info
+		if err := rows.Scan(&m.Userid, &m.Username); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+`,
+			},
+		},
+		{
+			Name:    "generates mysql filter builder",
+			Dialect: DialectMySQL{},
+			Fsys: fstest.MapFS{
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "model": {
+        "filter": true
+      },
+      "queries": {
+        "Model": [
+          {
+            "kind": "getoneeq",
+            "name": "ByID",
+            "conditions": [
+              {"col": "userid"}
+            ]
+          }
+        ],
+        "Info": [
+          {
+            "kind": "getgroup",
+            "name": "All"
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL UNIQUE"` + "`" + `
+		Created  int64  ` + "`" + `model:"created,BIGINT NOT NULL"` + "`" + `
+	}
+
+	//forge:model:query user
+	Info struct {
+		Userid   string ` + "`" + `model:"userid"` + "`" + `
+		Username string ` + "`" + `model:"username"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Output: map[string]string{
+				"model_gen.go": `// Code generated by go generate forge model dev; DO NOT EDIT.
+
+package somepackage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"xorkevin.dev/forge/model/sqldb"
+)
+
+type (
+	userModelTable struct {
+		TableName string
+	}
+)
+
+func (t *userModelTable) Setup(ctx context.Context, d sqldb.Executor) error {
+	_, err := d.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `userid` + "`" + ` VARCHAR(31) PRIMARY KEY, ` + "`" + `username` + "`" + ` VARCHAR(255) NOT NULL UNIQUE, ` + "`" + `created` + "`" + ` BIGINT NOT NULL);")
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) Insert(ctx context.Context, d sqldb.Executor, m *Model) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `userid` + "`" + `, ` + "`" + `username` + "`" + `, ` + "`" + `created` + "`" + `) VALUES (?, ?, ?);", m.Userid, m.Username, m.Created)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) InsertBulk(ctx context.Context, d sqldb.Executor, models []*Model, allowConflict bool) error {
+	conflictSQL := ""
+	if allowConflict {
+		conflictSQL = " ON DUPLICATE KEY UPDATE ` + "`" + `userid` + "`" + ` = ` + "`" + `userid` + "`" + `"
+	}
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*3)
+	for _, m := range models {
+		placeholders = append(placeholders, "(?, ?, ?)")
+		args = append(args, m.Userid, m.Username, m.Created)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `userid` + "`" + `, ` + "`" + `username` + "`" + `, ` + "`" + `created` + "`" + `) VALUES "+strings.Join(placeholders, ", ")+conflictSQL+";", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) GetModelByID(ctx context.Context, d sqldb.Executor, userid string) (*Model, error) {
+	m := &Model{}
+	if err := d.QueryRowContext(ctx, "SELECT ` + "`" + `userid` + "`" + `, ` + "`" + `username` + "`" + `, ` + "`" + `created` + "`" + ` FROM ` + "`" + `"+t.TableName+"` + "`" + ` WHERE ` + "`" + `userid` + "`" + ` = ?;", userid).Scan(&m.Userid, &m.Username, &m.Created); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (t *userModelTable) GetInfoAll(ctx context.Context, d sqldb.Executor, limit, offset int) (_ []Info, retErr error) {
+	res := make([]Info, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT ` + "`" + `userid` + "`" + `, ` + "`" + `username` + "`" + ` FROM ` + "`" + `"+t.TableName+"` + "`" + ` LIMIT ? OFFSET ?;", limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Info
+		if err := rows.Scan(&m.Userid, &m.Username); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+type (
+	// userModelFilter builds a query of userModelTable with
+	// conditions joined by AND
+	userModelFilter struct {
+		t      *userModelTable
+		conds  []string
+		args   []interface{}
+		order  []string
+		limit  int
+		offset int
+	}
+)
+
+func (t *userModelTable) Filter() *userModelFilter {
+	return &userModelFilter{
+		t: t,
+	}
+}
+
+func (f *userModelFilter) WhereUseridEq(vUserid string) *userModelFilter {
+	f.args = append(f.args, vUserid)
+	f.conds = append(f.conds, "` + "`" + `userid` + "`" + ` = ?")
+	return f
+}
+
+func (f *userModelFilter) WhereUseridNeq(vUserid string) *userModelFilter {
+	f.args = append(f.args, vUserid)
+	f.conds = append(f.conds, "` + "`" + `userid` + "`" + ` <> ?")
+	return f
+}
+
+func (f *userModelFilter) WhereUseridLt(vUserid string) *userModelFilter {
+	f.args = append(f.args, vUserid)
+	f.conds = append(f.conds, "` + "`" + `userid` + "`" + ` < ?")
+	return f
+}
+
+func (f *userModelFilter) WhereUseridLeq(vUserid string) *userModelFilter {
+	f.args = append(f.args, vUserid)
+	f.conds = append(f.conds, "` + "`" + `userid` + "`" + ` <= ?")
+	return f
+}
+
+func (f *userModelFilter) WhereUseridGt(vUserid string) *userModelFilter {
+	f.args = append(f.args, vUserid)
+	f.conds = append(f.conds, "` + "`" + `userid` + "`" + ` > ?")
+	return f
+}
+
+func (f *userModelFilter) WhereUseridGeq(vUserid string) *userModelFilter {
+	f.args = append(f.args, vUserid)
+	f.conds = append(f.conds, "` + "`" + `userid` + "`" + ` >= ?")
+	return f
+}
+
+func (f *userModelFilter) WhereUseridIn(vUserids []string) *userModelFilter {
+	if len(vUserids) == 0 {
+		// an empty IN list is invalid sql, and matches no rows
+		f.conds = append(f.conds, "1 = 0")
+		return f
+	}
+	placeholders := make([]string, 0, len(vUserids))
+	for _, i := range vUserids {
+		f.args = append(f.args, i)
+		placeholders = append(placeholders, "?")
+	}
+	f.conds = append(f.conds, "` + "`" + `userid` + "`" + ` IN ("+strings.Join(placeholders, ", ")+")")
+	return f
+}
+
+// WhereUseridLike matches a raw LIKE pattern with an ESCAPE of
+// sqldb.LikeEscape, and text of the pattern which is matched literally may be
+// escaped by sqldb.EscapeLike
+func (f *userModelFilter) WhereUseridLike(vUseridPattern string) *userModelFilter {
+	f.args = append(f.args, vUseridPattern)
+	f.conds = append(f.conds, "` + "`" + `userid` + "`" + ` LIKE ? ESCAPE '!'")
+	return f
+}
+
+func (f *userModelFilter) WhereUseridIsNull() *userModelFilter {
+	f.conds = append(f.conds, "` + "`" + `userid` + "`" + ` IS NULL")
+	return f
+}
+
+func (f *userModelFilter) WhereUseridNotNull() *userModelFilter {
+	f.conds = append(f.conds, "` + "`" + `userid` + "`" + ` IS NOT NULL")
+	return f
+}
+
+func (f *userModelFilter) OrderUserid(desc bool) *userModelFilter {
+	if desc {
+		f.order = append(f.order, "` + "`" + `userid` + "`" + ` DESC")
+	} else {
+		f.order = append(f.order, "` + "`" + `userid` + "`" + `")
+	}
+	return f
+}
+
+func (f *userModelFilter) WhereUsernameEq(vUsername string) *userModelFilter {
+	f.args = append(f.args, vUsername)
+	f.conds = append(f.conds, "` + "`" + `username` + "`" + ` = ?")
+	return f
+}
+
+func (f *userModelFilter) WhereUsernameNeq(vUsername string) *userModelFilter {
+	f.args = append(f.args, vUsername)
+	f.conds = append(f.conds, "` + "`" + `username` + "`" + ` <> ?")
+	return f
+}
+
+func (f *userModelFilter) WhereUsernameLt(vUsername string) *userModelFilter {
+	f.args = append(f.args, vUsername)
+	f.conds = append(f.conds, "` + "`" + `username` + "`" + ` < ?")
+	return f
+}
+
+func (f *userModelFilter) WhereUsernameLeq(vUsername string) *userModelFilter {
+	f.args = append(f.args, vUsername)
+	f.conds = append(f.conds, "` + "`" + `username` + "`" + ` <= ?")
+	return f
+}
+
+func (f *userModelFilter) WhereUsernameGt(vUsername string) *userModelFilter {
+	f.args = append(f.args, vUsername)
+	f.conds = append(f.conds, "` + "`" + `username` + "`" + ` > ?")
+	return f
+}
+
+func (f *userModelFilter) WhereUsernameGeq(vUsername string) *userModelFilter {
+	f.args = append(f.args, vUsername)
+	f.conds = append(f.conds, "` + "`" + `username` + "`" + ` >= ?")
+	return f
+}
+
+func (f *userModelFilter) WhereUsernameIn(vUsernames []string) *userModelFilter {
+	if len(vUsernames) == 0 {
+		// an empty IN list is invalid sql, and matches no rows
+		f.conds = append(f.conds, "1 = 0")
+		return f
+	}
+	placeholders := make([]string, 0, len(vUsernames))
+	for _, i := range vUsernames {
+		f.args = append(f.args, i)
+		placeholders = append(placeholders, "?")
+	}
+	f.conds = append(f.conds, "` + "`" + `username` + "`" + ` IN ("+strings.Join(placeholders, ", ")+")")
+	return f
+}
+
+// WhereUsernameLike matches a raw LIKE pattern with an ESCAPE of
+// sqldb.LikeEscape, and text of the pattern which is matched literally may be
+// escaped by sqldb.EscapeLike
+func (f *userModelFilter) WhereUsernameLike(vUsernamePattern string) *userModelFilter {
+	f.args = append(f.args, vUsernamePattern)
+	f.conds = append(f.conds, "` + "`" + `username` + "`" + ` LIKE ? ESCAPE '!'")
+	return f
+}
+
+func (f *userModelFilter) WhereUsernameIsNull() *userModelFilter {
+	f.conds = append(f.conds, "` + "`" + `username` + "`" + ` IS NULL")
+	return f
+}
+
+func (f *userModelFilter) WhereUsernameNotNull() *userModelFilter {
+	f.conds = append(f.conds, "` + "`" + `username` + "`" + ` IS NOT NULL")
+	return f
+}
+
+func (f *userModelFilter) OrderUsername(desc bool) *userModelFilter {
+	if desc {
+		f.order = append(f.order, "` + "`" + `username` + "`" + ` DESC")
+	} else {
+		f.order = append(f.order, "` + "`" + `username` + "`" + `")
+	}
+	return f
+}
+
+func (f *userModelFilter) WhereCreatedEq(vCreated int64) *userModelFilter {
+	f.args = append(f.args, vCreated)
+	f.conds = append(f.conds, "` + "`" + `created` + "`" + ` = ?")
+	return f
+}
+
+func (f *userModelFilter) WhereCreatedNeq(vCreated int64) *userModelFilter {
+	f.args = append(f.args, vCreated)
+	f.conds = append(f.conds, "` + "`" + `created` + "`" + ` <> ?")
+	return f
+}
+
+func (f *userModelFilter) WhereCreatedLt(vCreated int64) *userModelFilter {
+	f.args = append(f.args, vCreated)
+	f.conds = append(f.conds, "` + "`" + `created` + "`" + ` < ?")
+	return f
+}
+
+func (f *userModelFilter) WhereCreatedLeq(vCreated int64) *userModelFilter {
+	f.args = append(f.args, vCreated)
+	f.conds = append(f.conds, "` + "`" + `created` + "`" + ` <= ?")
+	return f
+}
+
+func (f *userModelFilter) WhereCreatedGt(vCreated int64) *userModelFilter {
+	f.args = append(f.args, vCreated)
+	f.conds = append(f.conds, "` + "`" + `created` + "`" + ` > ?")
+	return f
+}
+
+func (f *userModelFilter) WhereCreatedGeq(vCreated int64) *userModelFilter {
+	f.args = append(f.args, vCreated)
+	f.conds = append(f.conds, "` + "`" + `created` + "`" + ` >= ?")
+	return f
+}
+
+func (f *userModelFilter) WhereCreatedIn(vCreateds []int64) *userModelFilter {
+	if len(vCreateds) == 0 {
+		// an empty IN list is invalid sql, and matches no rows
+		f.conds = append(f.conds, "1 = 0")
+		return f
+	}
+	placeholders := make([]string, 0, len(vCreateds))
+	for _, i := range vCreateds {
+		f.args = append(f.args, i)
+		placeholders = append(placeholders, "?")
+	}
+	f.conds = append(f.conds, "` + "`" + `created` + "`" + ` IN ("+strings.Join(placeholders, ", ")+")")
+	return f
+}
+
+func (f *userModelFilter) WhereCreatedIsNull() *userModelFilter {
+	f.conds = append(f.conds, "` + "`" + `created` + "`" + ` IS NULL")
+	return f
+}
+
+func (f *userModelFilter) WhereCreatedNotNull() *userModelFilter {
+	f.conds = append(f.conds, "` + "`" + `created` + "`" + ` IS NOT NULL")
+	return f
+}
+
+func (f *userModelFilter) OrderCreated(desc bool) *userModelFilter {
+	if desc {
+		f.order = append(f.order, "` + "`" + `created` + "`" + ` DESC")
+	} else {
+		f.order = append(f.order, "` + "`" + `created` + "`" + `")
+	}
+	return f
+}
+
+// Limit limits the number of rows of the query, and is not applied if zero or
+// negative
+func (f *userModelFilter) Limit(limit int) *userModelFilter {
+	if limit < 0 {
+		limit = 0
+	}
+	f.limit = limit
+	return f
+}
+
+// Offset skips rows of the query, and is only applied with a limit
+func (f *userModelFilter) Offset(offset int) *userModelFilter {
+	f.offset = offset
+	return f
+}
+
+func (f *userModelFilter) query(cols string) (string, []interface{}) {
+	t := f.t
+	query := "SELECT " + cols + " FROM " + "` + "`" + `" + t.TableName + "` + "`" + `"
+	conds := f.conds
+	if len(conds) != 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	if len(f.order) != 0 {
+		query += " ORDER BY " + strings.Join(f.order, ", ")
+	}
+	args := make([]interface{}, 0, len(f.args)+2)
+	args = append(args, f.args...)
+	if f.limit > 0 {
+		args = append(args, f.limit)
+		if f.offset > 0 {
+			args = append(args, f.offset)
+			query += " LIMIT ? OFFSET ?"
+		} else {
+			query += " LIMIT ?"
+		}
+	}
+	return query + ";", args
+}
+
+func (f *userModelFilter) GetModel(ctx context.Context, d sqldb.Executor) (_ []Model, retErr error) {
+	query, args := f.query("` + "`" + `userid` + "`" + `, ` + "`" + `username` + "`" + `, ` + "`" + `created` + "`" + `")
+	res := make([]Model, 0, f.limit)
+	rows, err := d.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Model
+		if err := rows.Scan(&m.Userid, &m.Username, &m.Created); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (f *userModelFilter) GetInfo(ctx context.Context, d sqldb.Executor) (_ []Info, retErr error) {
+	query, args := f.query("` + "`" + `userid` + "`" + `, ` + "`" + `username` + "`" + `")
+	res := make([]Info, 0, f.limit)
+	rows, err := d.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Info
+		if err := rows.Scan(&m.Userid, &m.Username); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+`,
+			},
+		},
+		{
+			Name: "generates filter builder for keyword field names",
+			Fsys: fstest.MapFS{
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "model": {
+        "filter": true
+      },
+      "queries": {
+        "Model": [
+          {
+            "kind": "getgroup",
+            "name": "All"
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Type   string ` + "`" + `model:"type,VARCHAR(255) NOT NULL"` + "`" + `
+		F      int    ` + "`" + `model:"f,INT NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Output: map[string]string{
+				"model_gen.go": `// Code generated by go generate forge model dev; DO NOT EDIT.
+
+package somepackage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"xorkevin.dev/forge/model/sqldb"
+)
+
+type (
+	userModelTable struct {
+		TableName string
+	}
+)
+
+func (t *userModelTable) Setup(ctx context.Context, d sqldb.Executor) error {
+	_, err := d.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+t.TableName+" (userid VARCHAR(31) PRIMARY KEY, type VARCHAR(255) NOT NULL, f INT NOT NULL);")
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) Insert(ctx context.Context, d sqldb.Executor, m *Model) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (userid, type, f) VALUES ($1, $2, $3);", m.Userid, m.Type, m.F)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) InsertBulk(ctx context.Context, d sqldb.Executor, models []*Model, allowConflict bool) error {
+	conflictSQL := ""
+	if allowConflict {
+		conflictSQL = " ON CONFLICT DO NOTHING"
+	}
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*3)
+	for c, m := range models {
+		n := c * 3
+		placeholders = append(placeholders, fmt.Sprintf("($%d, $%d, $%d)", n+1, n+2, n+3))
+		args = append(args, m.Userid, m.Type, m.F)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (userid, type, f) VALUES "+strings.Join(placeholders, ", ")+conflictSQL+";", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) GetModelAll(ctx context.Context, d sqldb.Executor, limit, offset int) (_ []Model, retErr error) {
+	res := make([]Model, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT userid, type, f FROM "+t.TableName+" LIMIT $1 OFFSET $2;", limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Model
+		if err := rows.Scan(&m.Userid, &m.Type, &m.F); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+type (
+	// userModelFilter builds a query of userModelTable with
+	// conditions joined by AND
+	userModelFilter struct {
+		t      *userModelTable
+		conds  []string
+		args   []interface{}
+		order  []string
+		limit  int
+		offset int
+	}
+)
+
+func (t *userModelTable) Filter() *userModelFilter {
+	return &userModelFilter{
+		t: t,
+	}
+}
+
+func (f *userModelFilter) WhereUseridEq(vUserid string) *userModelFilter {
+	f.args = append(f.args, vUserid)
+	f.conds = append(f.conds, fmt.Sprintf("userid = $%d", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereUseridNeq(vUserid string) *userModelFilter {
+	f.args = append(f.args, vUserid)
+	f.conds = append(f.conds, fmt.Sprintf("userid <> $%d", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereUseridLt(vUserid string) *userModelFilter {
+	f.args = append(f.args, vUserid)
+	f.conds = append(f.conds, fmt.Sprintf("userid < $%d", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereUseridLeq(vUserid string) *userModelFilter {
+	f.args = append(f.args, vUserid)
+	f.conds = append(f.conds, fmt.Sprintf("userid <= $%d", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereUseridGt(vUserid string) *userModelFilter {
+	f.args = append(f.args, vUserid)
+	f.conds = append(f.conds, fmt.Sprintf("userid > $%d", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereUseridGeq(vUserid string) *userModelFilter {
+	f.args = append(f.args, vUserid)
+	f.conds = append(f.conds, fmt.Sprintf("userid >= $%d", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereUseridIn(vUserids []string) *userModelFilter {
+	if len(vUserids) == 0 {
+		// an empty IN list is invalid sql, and matches no rows
+		f.conds = append(f.conds, "1 = 0")
+		return f
+	}
+	placeholders := make([]string, 0, len(vUserids))
+	for _, i := range vUserids {
+		f.args = append(f.args, i)
+		placeholders = append(placeholders, fmt.Sprintf("($%d)", len(f.args)))
+	}
+	f.conds = append(f.conds, "userid IN (VALUES "+strings.Join(placeholders, ", ")+")")
+	return f
+}
+
+// WhereUseridLike matches a raw LIKE pattern with an ESCAPE of
+// sqldb.LikeEscape, and text of the pattern which is matched literally may be
+// escaped by sqldb.EscapeLike
+func (f *userModelFilter) WhereUseridLike(vUseridPattern string) *userModelFilter {
+	f.args = append(f.args, vUseridPattern)
+	f.conds = append(f.conds, fmt.Sprintf("userid LIKE $%d ESCAPE '!'", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereUseridIsNull() *userModelFilter {
+	f.conds = append(f.conds, "userid IS NULL")
+	return f
+}
+
+func (f *userModelFilter) WhereUseridNotNull() *userModelFilter {
+	f.conds = append(f.conds, "userid IS NOT NULL")
+	return f
+}
+
+func (f *userModelFilter) OrderUserid(desc bool) *userModelFilter {
+	if desc {
+		f.order = append(f.order, "userid DESC")
+	} else {
+		f.order = append(f.order, "userid")
+	}
+	return f
+}
+
+func (f *userModelFilter) WhereTypeEq(vType string) *userModelFilter {
+	f.args = append(f.args, vType)
+	f.conds = append(f.conds, fmt.Sprintf("type = $%d", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereTypeNeq(vType string) *userModelFilter {
+	f.args = append(f.args, vType)
+	f.conds = append(f.conds, fmt.Sprintf("type <> $%d", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereTypeLt(vType string) *userModelFilter {
+	f.args = append(f.args, vType)
+	f.conds = append(f.conds, fmt.Sprintf("type < $%d", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereTypeLeq(vType string) *userModelFilter {
+	f.args = append(f.args, vType)
+	f.conds = append(f.conds, fmt.Sprintf("type <= $%d", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereTypeGt(vType string) *userModelFilter {
+	f.args = append(f.args, vType)
+	f.conds = append(f.conds, fmt.Sprintf("type > $%d", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereTypeGeq(vType string) *userModelFilter {
+	f.args = append(f.args, vType)
+	f.conds = append(f.conds, fmt.Sprintf("type >= $%d", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereTypeIn(vTypes []string) *userModelFilter {
+	if len(vTypes) == 0 {
+		// an empty IN list is invalid sql, and matches no rows
+		f.conds = append(f.conds, "1 = 0")
+		return f
+	}
+	placeholders := make([]string, 0, len(vTypes))
+	for _, i := range vTypes {
+		f.args = append(f.args, i)
+		placeholders = append(placeholders, fmt.Sprintf("($%d)", len(f.args)))
+	}
+	f.conds = append(f.conds, "type IN (VALUES "+strings.Join(placeholders, ", ")+")")
+	return f
+}
+
+// WhereTypeLike matches a raw LIKE pattern with an ESCAPE of
+// sqldb.LikeEscape, and text of the pattern which is matched literally may be
+// escaped by sqldb.EscapeLike
+func (f *userModelFilter) WhereTypeLike(vTypePattern string) *userModelFilter {
+	f.args = append(f.args, vTypePattern)
+	f.conds = append(f.conds, fmt.Sprintf("type LIKE $%d ESCAPE '!'", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereTypeIsNull() *userModelFilter {
+	f.conds = append(f.conds, "type IS NULL")
+	return f
+}
+
+func (f *userModelFilter) WhereTypeNotNull() *userModelFilter {
+	f.conds = append(f.conds, "type IS NOT NULL")
+	return f
+}
+
+func (f *userModelFilter) OrderType(desc bool) *userModelFilter {
+	if desc {
+		f.order = append(f.order, "type DESC")
+	} else {
+		f.order = append(f.order, "type")
+	}
+	return f
+}
+
+func (f *userModelFilter) WhereFEq(vF int) *userModelFilter {
+	f.args = append(f.args, vF)
+	f.conds = append(f.conds, fmt.Sprintf("f = $%d", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereFNeq(vF int) *userModelFilter {
+	f.args = append(f.args, vF)
+	f.conds = append(f.conds, fmt.Sprintf("f <> $%d", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereFLt(vF int) *userModelFilter {
+	f.args = append(f.args, vF)
+	f.conds = append(f.conds, fmt.Sprintf("f < $%d", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereFLeq(vF int) *userModelFilter {
+	f.args = append(f.args, vF)
+	f.conds = append(f.conds, fmt.Sprintf("f <= $%d", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereFGt(vF int) *userModelFilter {
+	f.args = append(f.args, vF)
+	f.conds = append(f.conds, fmt.Sprintf("f > $%d", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereFGeq(vF int) *userModelFilter {
+	f.args = append(f.args, vF)
+	f.conds = append(f.conds, fmt.Sprintf("f >= $%d", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereFIn(vFs []int) *userModelFilter {
+	if len(vFs) == 0 {
+		// an empty IN list is invalid sql, and matches no rows
+		f.conds = append(f.conds, "1 = 0")
+		return f
+	}
+	placeholders := make([]string, 0, len(vFs))
+	for _, i := range vFs {
+		f.args = append(f.args, i)
+		placeholders = append(placeholders, fmt.Sprintf("($%d)", len(f.args)))
+	}
+	f.conds = append(f.conds, "f IN (VALUES "+strings.Join(placeholders, ", ")+")")
+	return f
+}
+
+func (f *userModelFilter) WhereFIsNull() *userModelFilter {
+	f.conds = append(f.conds, "f IS NULL")
+	return f
+}
+
+func (f *userModelFilter) WhereFNotNull() *userModelFilter {
+	f.conds = append(f.conds, "f IS NOT NULL")
+	return f
+}
+
+func (f *userModelFilter) OrderF(desc bool) *userModelFilter {
+	if desc {
+		f.order = append(f.order, "f DESC")
+	} else {
+		f.order = append(f.order, "f")
+	}
+	return f
+}
+
+// Limit limits the number of rows of the query, and is not applied if zero or
+// negative
+func (f *userModelFilter) Limit(limit int) *userModelFilter {
+	if limit < 0 {
+		limit = 0
+	}
+	f.limit = limit
+	return f
+}
+
+// Offset skips rows of the query, and is only applied with a limit
+func (f *userModelFilter) Offset(offset int) *userModelFilter {
+	f.offset = offset
+	return f
+}
+
+func (f *userModelFilter) query(cols string) (string, []interface{}) {
+	t := f.t
+	query := "SELECT " + cols + " FROM " + t.TableName
+	conds := f.conds
+	if len(conds) != 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	if len(f.order) != 0 {
+		query += " ORDER BY " + strings.Join(f.order, ", ")
+	}
+	args := make([]interface{}, 0, len(f.args)+2)
+	args = append(args, f.args...)
+	if f.limit > 0 {
+		args = append(args, f.limit)
+		if f.offset > 0 {
+			args = append(args, f.offset)
+			query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+		} else {
+			query += fmt.Sprintf(" LIMIT $%d", len(args))
+		}
+	}
+	return query + ";", args
+}
+
+func (f *userModelFilter) GetModel(ctx context.Context, d sqldb.Executor) (_ []Model, retErr error) {
+	query, args := f.query("userid, type, f")
+	res := make([]Model, 0, f.limit)
+	rows, err := d.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Model
+		if err := rows.Scan(&m.Userid, &m.Type, &m.F); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+`,
+			},
+		},
+		{
+			Name: "generates join queries",
+			Fsys: fstest.MapFS{
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {},
+    "profile": {}
+  },
+  "joins": {
+    "userProfile": {
+      "from": "user",
+      "join": [
+        {
+          "model": "profile",
+          "kind": "left",
+          "on": [
+            {"col": "profile.userid", "ref": "user.userid"}
+          ]
+        }
+      ],
+      "queries": [
+        {
+          "kind": "getoneeq",
+          "name": "ByID",
+          "conditions": [
+            {"col": "user.userid"}
+          ]
+        },
+        {
+          "kind": "getgroupeq",
+          "name": "ByUsernamePrefix",
+          "conditions": [
+            {"col": "user.username", "cond": "prefix"}
+          ],
+          "order": [
+            {"col": "user.username"}
+          ],
+          "stream": true
+        },
+        {
+          "kind": "counteq",
+          "name": "ByBio",
+          "conditions": [
+            {"col": "profile.bio", "cond": "isnull"}
+          ]
+        }
+      ]
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL UNIQUE"` + "`" + `
+	}
+
+	//forge:model profile
+	Profile struct {
+		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Bio    string ` + "`" + `model:"bio,VARCHAR(4095) NOT NULL"` + "`" + `
+	}
+
+	//forge:model:join userProfile
+	UserProfile struct {
+		Userid   string  ` + "`" + `model:"user.userid"` + "`" + `
+		Username string  ` + "`" + `model:"user.username"` + "`" + `
+		Bio      *string ` + "`" + `model:"profile.bio"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Output: map[string]string{
+				"model_gen.go": `// Code generated by go generate forge model dev; DO NOT EDIT.
+
+package somepackage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"xorkevin.dev/forge/model/sqldb"
+)
+
+type (
+	userModelTable struct {
+		TableName string
+	}
+)
+
+func (t *userModelTable) Setup(ctx context.Context, d sqldb.Executor) error {
+	_, err := d.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+t.TableName+" (userid VARCHAR(31) PRIMARY KEY, username VARCHAR(255) NOT NULL UNIQUE);")
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) Insert(ctx context.Context, d sqldb.Executor, m *Model) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (userid, username) VALUES ($1, $2);", m.Userid, m.Username)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) InsertBulk(ctx context.Context, d sqldb.Executor, models []*Model, allowConflict bool) error {
+	conflictSQL := ""
+	if allowConflict {
+		conflictSQL = " ON CONFLICT DO NOTHING"
+	}
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*2)
+	for c, m := range models {
+		n := c * 2
+		placeholders = append(placeholders, fmt.Sprintf("($%d, $%d)", n+1, n+2))
+		args = append(args, m.Userid, m.Username)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (userid, username) VALUES "+strings.Join(placeholders, ", ")+conflictSQL+";", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+type (
+	profileModelTable struct {
+		TableName string
+	}
+)
+
+func (t *profileModelTable) Setup(ctx context.Context, d sqldb.Executor) error {
+	_, err := d.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+t.TableName+" (userid VARCHAR(31) PRIMARY KEY, bio VARCHAR(4095) NOT NULL);")
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *profileModelTable) Insert(ctx context.Context, d sqldb.Executor, m *Profile) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (userid, bio) VALUES ($1, $2);", m.Userid, m.Bio)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *profileModelTable) InsertBulk(ctx context.Context, d sqldb.Executor, models []*Profile, allowConflict bool) error {
+	conflictSQL := ""
+	if allowConflict {
+		conflictSQL = " ON CONFLICT DO NOTHING"
+	}
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*2)
+	for c, m := range models {
+		n := c * 2
+		placeholders = append(placeholders, fmt.Sprintf("($%d, $%d)", n+1, n+2))
+		args = append(args, m.Userid, m.Bio)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (userid, bio) VALUES "+strings.Join(placeholders, ", ")+conflictSQL+";", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+type (
+	// userProfileModelTable queries the joined tables of userProfile
+	userProfileModelTable struct {
+		UserTableName    string
+		ProfileTableName string
+	}
+)
+
+func (t *userProfileModelTable) GetUserProfileByID(ctx context.Context, d sqldb.Executor, userid string) (*UserProfile, error) {
+	m := &UserProfile{}
+	if err := d.QueryRowContext(ctx, "SELECT t1.userid, t1.username, t2.bio FROM "+t.UserTableName+" t1 LEFT JOIN "+t.ProfileTableName+" t2 ON t2.userid = t1.userid WHERE t1.userid = $1;", userid).Scan(&m.Userid, &m.Username, &m.Bio); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (t *userProfileModelTable) GetUserProfileByUsernamePrefix(ctx context.Context, d sqldb.Executor, usernamePrefix string, limit, offset int) (_ []UserProfile, retErr error) {
+	res := make([]UserProfile, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT t1.userid, t1.username, t2.bio FROM "+t.UserTableName+" t1 LEFT JOIN "+t.ProfileTableName+" t2 ON t2.userid = t1.userid WHERE t1.username LIKE $3 ESCAPE '!' ORDER BY t1.username LIMIT $1 OFFSET $2;", limit, offset, sqldb.EscapeLike(usernamePrefix)+"%")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m UserProfile
+		if err := rows.Scan(&m.Userid, &m.Username, &m.Bio); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *userProfileModelTable) StreamUserProfileByUsernamePrefix(ctx context.Context, d sqldb.Executor, usernamePrefix string, fn func(m UserProfile) error) (retErr error) {
+	rows, err := d.QueryContext(ctx, "SELECT t1.userid, t1.username, t2.bio FROM "+t.UserTableName+" t1 LEFT JOIN "+t.ProfileTableName+" t2 ON t2.userid = t1.userid WHERE t1.username LIKE $1 ESCAPE '!' ORDER BY t1.username;", sqldb.EscapeLike(usernamePrefix)+"%")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m UserProfile
+		if err := rows.Scan(&m.Userid, &m.Username, &m.Bio); err != nil {
+			return err
+		}
+		if err := fn(m); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userProfileModelTable) CountUserProfileByBio(ctx context.Context, d sqldb.Executor) (int, error) {
+	var count int
+	if err := d.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+t.UserTableName+" t1 LEFT JOIN "+t.ProfileTableName+" t2 ON t2.userid = t1.userid WHERE t2.bio IS NULL;").Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+`,
+			},
+		},
+		{
+			Name:    "generates mysql join queries",
+			Dialect: DialectMySQL{},
+			Fsys: fstest.MapFS{
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {},
+    "profile": {}
+  },
+  "joins": {
+    "userProfile": {
+      "from": "user",
+      "join": [
+        {
+          "model": "profile",
+          "kind": "left",
+          "on": [
+            {"col": "profile.userid", "ref": "user.userid"}
+          ]
+        }
+      ],
+      "queries": [
+        {
+          "kind": "getoneeq",
+          "name": "ByID",
+          "conditions": [
+            {"col": "user.userid"}
+          ]
+        },
+        {
+          "kind": "getgroupeq",
+          "name": "ByUsernamePrefix",
+          "conditions": [
+            {"col": "user.username", "cond": "prefix"}
+          ],
+          "order": [
+            {"col": "user.username"}
+          ],
+          "stream": true
+        },
+        {
+          "kind": "counteq",
+          "name": "ByBio",
+          "conditions": [
+            {"col": "profile.bio", "cond": "isnull"}
+          ]
+        }
+      ]
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL UNIQUE"` + "`" + `
+	}
+
+	//forge:model profile
+	Profile struct {
+		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Bio    string ` + "`" + `model:"bio,VARCHAR(4095) NOT NULL"` + "`" + `
+	}
+
+	//forge:model:join userProfile
+	UserProfile struct {
+		Userid   string  ` + "`" + `model:"user.userid"` + "`" + `
+		Username string  ` + "`" + `model:"user.username"` + "`" + `
+		Bio      *string ` + "`" + `model:"profile.bio"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Output: map[string]string{
+				"model_gen.go": `// Code generated by go generate forge model dev; DO NOT EDIT.
+
+package somepackage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"xorkevin.dev/forge/model/sqldb"
+)
+
+type (
+	userModelTable struct {
+		TableName string
+	}
+)
+
+func (t *userModelTable) Setup(ctx context.Context, d sqldb.Executor) error {
+	_, err := d.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `userid` + "`" + ` VARCHAR(31) PRIMARY KEY, ` + "`" + `username` + "`" + ` VARCHAR(255) NOT NULL UNIQUE);")
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) Insert(ctx context.Context, d sqldb.Executor, m *Model) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `userid` + "`" + `, ` + "`" + `username` + "`" + `) VALUES (?, ?);", m.Userid, m.Username)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) InsertBulk(ctx context.Context, d sqldb.Executor, models []*Model, allowConflict bool) error {
+	conflictSQL := ""
+	if allowConflict {
+		conflictSQL = " ON DUPLICATE KEY UPDATE ` + "`" + `userid` + "`" + ` = ` + "`" + `userid` + "`" + `"
+	}
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*2)
+	for _, m := range models {
+		placeholders = append(placeholders, "(?, ?)")
+		args = append(args, m.Userid, m.Username)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `userid` + "`" + `, ` + "`" + `username` + "`" + `) VALUES "+strings.Join(placeholders, ", ")+conflictSQL+";", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+type (
+	profileModelTable struct {
+		TableName string
+	}
+)
+
+func (t *profileModelTable) Setup(ctx context.Context, d sqldb.Executor) error {
+	_, err := d.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `userid` + "`" + ` VARCHAR(31) PRIMARY KEY, ` + "`" + `bio` + "`" + ` VARCHAR(4095) NOT NULL);")
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *profileModelTable) Insert(ctx context.Context, d sqldb.Executor, m *Profile) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `userid` + "`" + `, ` + "`" + `bio` + "`" + `) VALUES (?, ?);", m.Userid, m.Bio)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *profileModelTable) InsertBulk(ctx context.Context, d sqldb.Executor, models []*Profile, allowConflict bool) error {
+	conflictSQL := ""
+	if allowConflict {
+		conflictSQL = " ON DUPLICATE KEY UPDATE ` + "`" + `userid` + "`" + ` = ` + "`" + `userid` + "`" + `"
+	}
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*2)
+	for _, m := range models {
+		placeholders = append(placeholders, "(?, ?)")
+		args = append(args, m.Userid, m.Bio)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `userid` + "`" + `, ` + "`" + `bio` + "`" + `) VALUES "+strings.Join(placeholders, ", ")+conflictSQL+";", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+type (
+	// userProfileModelTable queries the joined tables of userProfile
+	userProfileModelTable struct {
+		UserTableName    string
+		ProfileTableName string
+	}
+)
+
+func (t *userProfileModelTable) GetUserProfileByID(ctx context.Context, d sqldb.Executor, userid string) (*UserProfile, error) {
+	m := &UserProfile{}
+	if err := d.QueryRowContext(ctx, "SELECT ` + "`" + `t1` + "`" + `.` + "`" + `userid` + "`" + `, ` + "`" + `t1` + "`" + `.` + "`" + `username` + "`" + `, ` + "`" + `t2` + "`" + `.` + "`" + `bio` + "`" + ` FROM ` + "`" + `"+t.UserTableName+"` + "`" + ` ` + "`" + `t1` + "`" + ` LEFT JOIN ` + "`" + `"+t.ProfileTableName+"` + "`" + ` ` + "`" + `t2` + "`" + ` ON ` + "`" + `t2` + "`" + `.` + "`" + `userid` + "`" + ` = ` + "`" + `t1` + "`" + `.` + "`" + `userid` + "`" + ` WHERE ` + "`" + `t1` + "`" + `.` + "`" + `userid` + "`" + ` = ?;", userid).Scan(&m.Userid, &m.Username, &m.Bio); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (t *userProfileModelTable) GetUserProfileByUsernamePrefix(ctx context.Context, d sqldb.Executor, usernamePrefix string, limit, offset int) (_ []UserProfile, retErr error) {
+	res := make([]UserProfile, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT ` + "`" + `t1` + "`" + `.` + "`" + `userid` + "`" + `, ` + "`" + `t1` + "`" + `.` + "`" + `username` + "`" + `, ` + "`" + `t2` + "`" + `.` + "`" + `bio` + "`" + ` FROM ` + "`" + `"+t.UserTableName+"` + "`" + ` ` + "`" + `t1` + "`" + ` LEFT JOIN ` + "`" + `"+t.ProfileTableName+"` + "`" + ` ` + "`" + `t2` + "`" + ` ON ` + "`" + `t2` + "`" + `.` + "`" + `userid` + "`" + ` = ` + "`" + `t1` + "`" + `.` + "`" + `userid` + "`" + ` WHERE ` + "`" + `t1` + "`" + `.` + "`" + `username` + "`" + ` LIKE ? ESCAPE '!' ORDER BY ` + "`" + `t1` + "`" + `.` + "`" + `username` + "`" + ` LIMIT ? OFFSET ?;", sqldb.EscapeLike(usernamePrefix)+"%", limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m UserProfile
+		if err := rows.Scan(&m.Userid, &m.Username, &m.Bio); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *userProfileModelTable) StreamUserProfileByUsernamePrefix(ctx context.Context, d sqldb.Executor, usernamePrefix string, fn func(m UserProfile) error) (retErr error) {
+	rows, err := d.QueryContext(ctx, "SELECT ` + "`" + `t1` + "`" + `.` + "`" + `userid` + "`" + `, ` + "`" + `t1` + "`" + `.` + "`" + `username` + "`" + `, ` + "`" + `t2` + "`" + `.` + "`" + `bio` + "`" + ` FROM ` + "`" + `"+t.UserTableName+"` + "`" + ` ` + "`" + `t1` + "`" + ` LEFT JOIN ` + "`" + `"+t.ProfileTableName+"` + "`" + ` ` + "`" + `t2` + "`" + ` ON ` + "`" + `t2` + "`" + `.` + "`" + `userid` + "`" + ` = ` + "`" + `t1` + "`" + `.` + "`" + `userid` + "`" + ` WHERE ` + "`" + `t1` + "`" + `.` + "`" + `username` + "`" + ` LIKE ? ESCAPE '!' ORDER BY ` + "`" + `t1` + "`" + `.` + "`" + `username` + "`" + `;", sqldb.EscapeLike(usernamePrefix)+"%")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m UserProfile
+		if err := rows.Scan(&m.Userid, &m.Username, &m.Bio); err != nil {
+			return err
+		}
+		if err := fn(m); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userProfileModelTable) CountUserProfileByBio(ctx context.Context, d sqldb.Executor) (int, error) {
+	var count int
+	if err := d.QueryRowContext(ctx, "SELECT COUNT(*) FROM ` + "`" + `"+t.UserTableName+"` + "`" + ` ` + "`" + `t1` + "`" + ` LEFT JOIN ` + "`" + `"+t.ProfileTableName+"` + "`" + ` ` + "`" + `t2` + "`" + ` ON ` + "`" + `t2` + "`" + `.` + "`" + `userid` + "`" + ` = ` + "`" + `t1` + "`" + `.` + "`" + `userid` + "`" + ` WHERE ` + "`" + `t2` + "`" + `.` + "`" + `bio` + "`" + ` IS NULL;").Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+`,
+			},
+		},
+
+		{
+			Name: "generates join queries with shared field names",
+			Fsys: fstest.MapFS{
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "post": {},
+    "comment": {}
+  },
+  "joins": {
+    "commentPost": {
+      "from": "comment",
+      "join": [
+        {
+          "model": "post",
+          "on": [
+            {"col": "post.postid", "ref": "comment.postid"}
+          ]
+        }
+      ],
+      "queries": [
+        {
+          "kind": "getgroupeq",
+          "name": "ByAuthorCommenter",
+          "conditions": [
+            {"col": "post.userid"},
+            {"col": "comment.userid"}
+          ],
+          "order": [
+            {"col": "comment.commentid"}
+          ]
+        },
+        {
+          "kind": "counteq",
+          "name": "ByAuthorCommenters",
+          "conditions": [
+            {"col": "post.userid"},
+            {"col": "comment.userid", "cond": "in"}
+          ]
+        }
+      ]
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model post
+	Post struct {
+		Postid string ` + "`" + `model:"postid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Userid string ` + "`" + `model:"userid,VARCHAR(31) NOT NULL"` + "`" + `
+	}
+
+	//forge:model comment
+	Comment struct {
+		Commentid string ` + "`" + `model:"commentid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Postid    string ` + "`" + `model:"postid,VARCHAR(31) NOT NULL"` + "`" + `
+		Userid    string ` + "`" + `model:"userid,VARCHAR(31) NOT NULL"` + "`" + `
+	}
+
+	//forge:model:join commentPost
+	CommentPost struct {
+		Commentid string ` + "`" + `model:"comment.commentid"` + "`" + `
+		Postid    string ` + "`" + `model:"post.postid"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Output: map[string]string{
+				"model_gen.go": `// Code generated by go generate forge model dev; DO NOT EDIT.
+
+package somepackage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"xorkevin.dev/forge/model/sqldb"
+)
+
+type (
+	postModelTable struct {
+		TableName string
+	}
+)
+
+func (t *postModelTable) Setup(ctx context.Context, d sqldb.Executor) error {
+	_, err := d.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+t.TableName+" (postid VARCHAR(31) PRIMARY KEY, userid VARCHAR(31) NOT NULL);")
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *postModelTable) Insert(ctx context.Context, d sqldb.Executor, m *Post) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (postid, userid) VALUES ($1, $2);", m.Postid, m.Userid)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *postModelTable) InsertBulk(ctx context.Context, d sqldb.Executor, models []*Post, allowConflict bool) error {
+	conflictSQL := ""
+	if allowConflict {
+		conflictSQL = " ON CONFLICT DO NOTHING"
+	}
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*2)
+	for c, m := range models {
+		n := c * 2
+		placeholders = append(placeholders, fmt.Sprintf("($%d, $%d)", n+1, n+2))
+		args = append(args, m.Postid, m.Userid)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (postid, userid) VALUES "+strings.Join(placeholders, ", ")+conflictSQL+";", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+type (
+	commentModelTable struct {
+		TableName string
+	}
+)
+
+func (t *commentModelTable) Setup(ctx context.Context, d sqldb.Executor) error {
+	_, err := d.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+t.TableName+" (commentid VARCHAR(31) PRIMARY KEY, postid VARCHAR(31) NOT NULL, userid VARCHAR(31) NOT NULL);")
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *commentModelTable) Insert(ctx context.Context, d sqldb.Executor, m *Comment) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (commentid, postid, userid) VALUES ($1, $2, $3);", m.Commentid, m.Postid, m.Userid)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *commentModelTable) InsertBulk(ctx context.Context, d sqldb.Executor, models []*Comment, allowConflict bool) error {
+	conflictSQL := ""
+	if allowConflict {
+		conflictSQL = " ON CONFLICT DO NOTHING"
+	}
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*3)
+	for c, m := range models {
+		n := c * 3
+		placeholders = append(placeholders, fmt.Sprintf("($%d, $%d, $%d)", n+1, n+2, n+3))
+		args = append(args, m.Commentid, m.Postid, m.Userid)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (commentid, postid, userid) VALUES "+strings.Join(placeholders, ", ")+conflictSQL+";", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+type (
+	// commentPostModelTable queries the joined tables of commentPost
+	commentPostModelTable struct {
+		CommentTableName string
+		PostTableName    string
+	}
+)
+
+func (t *commentPostModelTable) GetCommentPostByAuthorCommenter(ctx context.Context, d sqldb.Executor, postUserid string, commentUserid string, limit, offset int) (_ []CommentPost, retErr error) {
+	res := make([]CommentPost, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT t1.commentid, t2.postid FROM "+t.CommentTableName+" t1 INNER JOIN "+t.PostTableName+" t2 ON t2.postid = t1.postid WHERE t2.userid = $3 AND t1.userid = $4 ORDER BY t1.commentid LIMIT $1 OFFSET $2;", limit, offset, postUserid, commentUserid)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m CommentPost
+		if err := rows.Scan(&m.Commentid, &m.Postid); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *commentPostModelTable) CountCommentPostByAuthorCommenters(ctx context.Context, d sqldb.Executor, postUserid string, commentUserids []string) (int, error) {
+	paramCount := 1
+	args := make([]interface{}, 0, paramCount+len(commentUserids))
+	args = append(args, postUserid)
+	var placeholderscommentUserids string
+	{
+		placeholders := make([]string, 0, len(commentUserids))
+		for _, i := range commentUserids {
+			paramCount++
+			placeholders = append(placeholders, fmt.Sprintf("($%d)", paramCount))
+			args = append(args, i)
+		}
+		placeholderscommentUserids = strings.Join(placeholders, ", ")
+	}
+	var count int
+	if err := d.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+t.CommentTableName+" t1 INNER JOIN "+t.PostTableName+" t2 ON t2.postid = t1.postid WHERE t2.userid = $1 AND t1.userid IN (VALUES "+placeholderscommentUserids+");", args...).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+`,
+			},
+		},
+
+		{
+			Name: "generates aggregate queries",
+			Fsys: fstest.MapFS{
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "order": {
+      "queries": {
+        "StatusStats": [
+          {
+            "kind": "aggregate",
+            "name": "ByStatus",
+            "conditions": [
+              {"col": "updated_at", "cond": "geq"},
+              {"col": "status", "cond": "in"}
+            ],
+            "having": [
+              {"col": "count(*)", "cond": "gt"}
+            ],
+            "order": [
+              {"col": "count(*)", "dir": "DESC"},
+              {"col": "status"}
+            ]
+          }
+        ],
+        "Totals": [
+          {
+            "kind": "aggregate",
+            "name": "All"
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model order
+	Order struct {
+		Orderid string  ` + "`" + `model:"orderid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Status  string  ` + "`" + `model:"status,VARCHAR(31) NOT NULL"` + "`" + `
+		Amount  int64   ` + "`" + `model:"amount,BIGINT NOT NULL"` + "`" + `
+		Rating  float64 ` + "`" + `model:"rating,DOUBLE PRECISION"` + "`" + `
+		Updated int64   ` + "`" + `model:"updated_at,BIGINT NOT NULL"` + "`" + `
+	}
+
+	//forge:model:query order
+	StatusStats struct {
+		Status  string   ` + "`" + `model:"status"` + "`" + `
+		Count   int      ` + "`" + `model:"count(*)"` + "`" + `
+		Total   *int64   ` + "`" + `model:"sum(amount)"` + "`" + `
+		Rating  *float64 ` + "`" + `model:"avg(rating)"` + "`" + `
+		Updated *int64   ` + "`" + `model:"max(updated_at)"` + "`" + `
+	}
+
+	//forge:model:query order
+	Totals struct {
+		Count int64 ` + "`" + `model:"count(orderid)"` + "`" + `
+		Low   int64 ` + "`" + `model:"min(amount)"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Output: map[string]string{
+				"model_gen.go": `// Code generated by go generate forge model dev; DO NOT EDIT.
+
+package somepackage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"xorkevin.dev/forge/model/sqldb"
+)
+
+type (
+	orderModelTable struct {
+		TableName string
+	}
+)
+
+func (t *orderModelTable) Setup(ctx context.Context, d sqldb.Executor) error {
+	_, err := d.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+t.TableName+" (orderid VARCHAR(31) PRIMARY KEY, status VARCHAR(31) NOT NULL, amount BIGINT NOT NULL, rating DOUBLE PRECISION, updated_at BIGINT NOT NULL);")
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *orderModelTable) Insert(ctx context.Context, d sqldb.Executor, m *Order) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (orderid, status, amount, rating, updated_at) VALUES ($1, $2, $3, $4, $5);", m.Orderid, m.Status, m.Amount, m.Rating, m.Updated)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *orderModelTable) InsertBulk(ctx context.Context, d sqldb.Executor, models []*Order, allowConflict bool) error {
+	conflictSQL := ""
+	if allowConflict {
+		conflictSQL = " ON CONFLICT DO NOTHING"
+	}
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*5)
+	for c, m := range models {
+		n := c * 5
+		placeholders = append(placeholders, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5))
+		args = append(args, m.Orderid, m.Status, m.Amount, m.Rating, m.Updated)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (orderid, status, amount, rating, updated_at) VALUES "+strings.Join(placeholders, ", ")+conflictSQL+";", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *orderModelTable) GetStatusStatsByStatus(ctx context.Context, d sqldb.Executor, updated int64, statuss []string, count int, limit, offset int) (_ []StatusStats, retErr error) {
+	paramCount := 4
+	args := make([]interface{}, 0, paramCount+len(statuss))
+	args = append(args, limit, offset, updated, count)
+	var placeholdersstatuss string
+	{
+		placeholders := make([]string, 0, len(statuss))
+		for _, i := range statuss {
+			paramCount++
+			placeholders = append(placeholders, fmt.Sprintf("($%d)", paramCount))
+			args = append(args, i)
+		}
+		placeholdersstatuss = strings.Join(placeholders, ", ")
+	}
+	res := make([]StatusStats, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT status, COUNT(*), SUM(amount), AVG(rating), MAX(updated_at) FROM "+t.TableName+" WHERE updated_at >= $3 AND status IN (VALUES "+placeholdersstatuss+") GROUP BY status HAVING COUNT(*) > $4 ORDER BY COUNT(*) DESC, status LIMIT $1 OFFSET $2;", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m StatusStats
+		if err := rows.Scan(&m.Status, &m.Count, &m.Total, &m.Rating, &m.Updated); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *orderModelTable) GetTotalsAll(ctx context.Context, d sqldb.Executor, limit, offset int) (_ []Totals, retErr error) {
+	res := make([]Totals, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT COUNT(orderid), MIN(amount) FROM "+t.TableName+" LIMIT $1 OFFSET $2;", limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Totals
+		if err := rows.Scan(&m.Count, &m.Low); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+`,
+			},
+		},
+		{
+			Name:    "generates mysql aggregate queries",
+			Dialect: DialectMySQL{},
+			Fsys: fstest.MapFS{
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "order": {
+      "queries": {
+        "StatusStats": [
+          {
+            "kind": "aggregate",
+            "name": "ByStatus",
+            "conditions": [
+              {"col": "updated_at", "cond": "geq"},
+              {"col": "status", "cond": "in"}
+            ],
+            "having": [
+              {"col": "count(*)", "cond": "gt"}
+            ],
+            "order": [
+              {"col": "count(*)", "dir": "DESC"},
+              {"col": "status"}
+            ]
+          }
+        ],
+        "Totals": [
+          {
+            "kind": "aggregate",
+            "name": "All"
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model order
+	Order struct {
+		Orderid string  ` + "`" + `model:"orderid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Status  string  ` + "`" + `model:"status,VARCHAR(31) NOT NULL"` + "`" + `
+		Amount  int64   ` + "`" + `model:"amount,BIGINT NOT NULL"` + "`" + `
+		Rating  float64 ` + "`" + `model:"rating,DOUBLE PRECISION"` + "`" + `
+		Updated int64   ` + "`" + `model:"updated_at,BIGINT NOT NULL"` + "`" + `
+	}
+
+	//forge:model:query order
+	StatusStats struct {
+		Status  string   ` + "`" + `model:"status"` + "`" + `
+		Count   int      ` + "`" + `model:"count(*)"` + "`" + `
+		Total   *int64   ` + "`" + `model:"sum(amount)"` + "`" + `
+		Rating  *float64 ` + "`" + `model:"avg(rating)"` + "`" + `
+		Updated *int64   ` + "`" + `model:"max(updated_at)"` + "`" + `
+	}
+
+	//forge:model:query order
+	Totals struct {
+		Count int64 ` + "`" + `model:"count(orderid)"` + "`" + `
+		Low   int64 ` + "`" + `model:"min(amount)"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Output: map[string]string{
+				"model_gen.go": `// Code generated by go generate forge model dev; DO NOT EDIT.
+
+package somepackage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"xorkevin.dev/forge/model/sqldb"
+)
+
+type (
+	orderModelTable struct {
+		TableName string
+	}
+)
+
+func (t *orderModelTable) Setup(ctx context.Context, d sqldb.Executor) error {
+	_, err := d.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `orderid` + "`" + ` VARCHAR(31) PRIMARY KEY, ` + "`" + `status` + "`" + ` VARCHAR(31) NOT NULL, ` + "`" + `amount` + "`" + ` BIGINT NOT NULL, ` + "`" + `rating` + "`" + ` DOUBLE PRECISION, ` + "`" + `updated_at` + "`" + ` BIGINT NOT NULL);")
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *orderModelTable) Insert(ctx context.Context, d sqldb.Executor, m *Order) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `orderid` + "`" + `, ` + "`" + `status` + "`" + `, ` + "`" + `amount` + "`" + `, ` + "`" + `rating` + "`" + `, ` + "`" + `updated_at` + "`" + `) VALUES (?, ?, ?, ?, ?);", m.Orderid, m.Status, m.Amount, m.Rating, m.Updated)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *orderModelTable) InsertBulk(ctx context.Context, d sqldb.Executor, models []*Order, allowConflict bool) error {
+	conflictSQL := ""
+	if allowConflict {
+		conflictSQL = " ON DUPLICATE KEY UPDATE ` + "`" + `orderid` + "`" + ` = ` + "`" + `orderid` + "`" + `"
+	}
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*5)
+	for _, m := range models {
+		placeholders = append(placeholders, "(?, ?, ?, ?, ?)")
+		args = append(args, m.Orderid, m.Status, m.Amount, m.Rating, m.Updated)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `orderid` + "`" + `, ` + "`" + `status` + "`" + `, ` + "`" + `amount` + "`" + `, ` + "`" + `rating` + "`" + `, ` + "`" + `updated_at` + "`" + `) VALUES "+strings.Join(placeholders, ", ")+conflictSQL+";", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *orderModelTable) GetStatusStatsByStatus(ctx context.Context, d sqldb.Executor, updated int64, statuss []string, count int, limit, offset int) (_ []StatusStats, retErr error) {
+	paramCount := 4
+	args := make([]interface{}, 0, paramCount+len(statuss))
+	args = append(args, updated)
+	var placeholdersstatuss string
+	{
+		placeholders := make([]string, 0, len(statuss))
+		for _, i := range statuss {
+			placeholders = append(placeholders, "?")
+			args = append(args, i)
+		}
+		placeholdersstatuss = strings.Join(placeholders, ", ")
+	}
+	args = append(args, count, limit, offset)
+	res := make([]StatusStats, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT ` + "`" + `status` + "`" + `, COUNT(*), SUM(` + "`" + `amount` + "`" + `), AVG(` + "`" + `rating` + "`" + `), MAX(` + "`" + `updated_at` + "`" + `) FROM ` + "`" + `"+t.TableName+"` + "`" + ` WHERE ` + "`" + `updated_at` + "`" + ` >= ? AND ` + "`" + `status` + "`" + ` IN ("+placeholdersstatuss+") GROUP BY ` + "`" + `status` + "`" + ` HAVING COUNT(*) > ? ORDER BY COUNT(*) DESC, ` + "`" + `status` + "`" + ` LIMIT ? OFFSET ?;", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m StatusStats
+		if err := rows.Scan(&m.Status, &m.Count, &m.Total, &m.Rating, &m.Updated); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *orderModelTable) GetTotalsAll(ctx context.Context, d sqldb.Executor, limit, offset int) (_ []Totals, retErr error) {
+	res := make([]Totals, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT COUNT(` + "`" + `orderid` + "`" + `), MIN(` + "`" + `amount` + "`" + `) FROM ` + "`" + `"+t.TableName+"` + "`" + ` LIMIT ? OFFSET ?;", limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Totals
+		if err := rows.Scan(&m.Count, &m.Low); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+`,
+			},
+		},
+
+		{
+			Name: "generates raw queries",
+			Fsys: fstest.MapFS{
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "raw",
+            "name": "Between",
+            "sql": "SELECT {{columns}} FROM {{table}} WHERE {{created_at}} >= :since AND {{created_at}} < :until AND {{username}} <> '' ORDER BY {{created_at}} LIMIT 8;",
+            "params": [
+              {"name": "since", "col": "created_at"},
+              {"name": "until", "col": "created_at"}
+            ]
+          }
+        ],
+        "Info": [
+          {
+            "kind": "raw",
+            "name": "Similar",
+            "sqlfile": "similar.sql"
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"similar.sql": &fstest.MapFile{
+					Data: []byte(`-- users whose username shares a prefix with the input, or the user itself
+SELECT {{columns}}
+FROM {{table}}
+WHERE {{username}} LIKE :username || '%'
+  OR {{userid}} = :userid
+  OR {{userid}}::text = :userid;
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+		Created  int64  ` + "`" + `model:"created_at,BIGINT NOT NULL"` + "`" + `
+	}
+
+	//forge:model:query user
+	Info struct {
+		Userid   string ` + "`" + `model:"userid"` + "`" + `
+		Username string ` + "`" + `model:"username"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Output: map[string]string{
+				"model_gen.go": `// Code generated by go generate forge model dev; DO NOT EDIT.
+
+package somepackage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"xorkevin.dev/forge/model/sqldb"
+)
+
+type (
+	userModelTable struct {
+		TableName string
+	}
+)
+
+func (t *userModelTable) Setup(ctx context.Context, d sqldb.Executor) error {
+	_, err := d.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+t.TableName+" (userid VARCHAR(31) PRIMARY KEY, username VARCHAR(255) NOT NULL, created_at BIGINT NOT NULL);")
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) Insert(ctx context.Context, d sqldb.Executor, m *Model) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (userid, username, created_at) VALUES ($1, $2, $3);", m.Userid, m.Username, m.Created)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) InsertBulk(ctx context.Context, d sqldb.Executor, models []*Model, allowConflict bool) error {
+	conflictSQL := ""
+	if allowConflict {
+		conflictSQL = " ON CONFLICT DO NOTHING"
+	}
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*3)
+	for c, m := range models {
+		n := c * 3
+		placeholders = append(placeholders, fmt.Sprintf("($%d, $%d, $%d)", n+1, n+2, n+3))
+		args = append(args, m.Userid, m.Username, m.Created)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (userid, username, created_at) VALUES "+strings.Join(placeholders, ", ")+conflictSQL+";", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) GetModelBetween(ctx context.Context, d sqldb.Executor, vSince int64, vUntil int64) (_ []Model, retErr error) {
+	res := []Model{}
+	rows, err := d.QueryContext(ctx, "SELECT userid, username, created_at FROM "+t.TableName+" WHERE created_at >= $1 AND created_at < $2 AND username <> '' ORDER BY created_at LIMIT 8;", vSince, vUntil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Model
+		if err := rows.Scan(&m.Userid, &m.Username, &m.Created); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *userModelTable) GetInfoSimilar(ctx context.Context, d sqldb.Executor, vUsername string, vUserid string) (_ []Info, retErr error) {
+	res := []Info{}
+	rows, err := d.QueryContext(ctx, "SELECT userid, username FROM "+t.TableName+" WHERE username LIKE $1 || '%' OR userid = $2 OR userid::text = $2;", vUsername, vUserid)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Info
+		if err := rows.Scan(&m.Userid, &m.Username); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+`,
+			},
+		},
+		{
+			Name:    "generates mysql raw queries",
+			Dialect: DialectMySQL{},
+			Fsys: fstest.MapFS{
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "raw",
+            "name": "Between",
+            "sql": "SELECT {{columns}} FROM {{table}} WHERE {{created_at}} >= :since AND {{created_at}} < :until AND {{username}} <> '' ORDER BY {{created_at}} LIMIT 8;",
+            "params": [
+              {"name": "since", "col": "created_at"},
+              {"name": "until", "col": "created_at"}
+            ]
+          }
+        ],
+        "Info": [
+          {
+            "kind": "raw",
+            "name": "Similar",
+            "sqlfile": "similar.sql"
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"similar.sql": &fstest.MapFile{
+					Data: []byte(`-- users whose username shares a prefix with the input, or the user itself
+SELECT {{columns}}
+FROM {{table}}
+WHERE {{username}} LIKE CONCAT(:username, '%')
+  OR {{userid}} = :userid
+  OR CAST({{userid}} AS CHAR) = :userid;
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+		Created  int64  ` + "`" + `model:"created_at,BIGINT NOT NULL"` + "`" + `
+	}
+
+	//forge:model:query user
+	Info struct {
+		Userid   string ` + "`" + `model:"userid"` + "`" + `
+		Username string ` + "`" + `model:"username"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Output: map[string]string{
+				"model_gen.go": `// Code generated by go generate forge model dev; DO NOT EDIT.
+
+package somepackage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"xorkevin.dev/forge/model/sqldb"
+)
+
+type (
+	userModelTable struct {
+		TableName string
+	}
+)
+
+func (t *userModelTable) Setup(ctx context.Context, d sqldb.Executor) error {
+	_, err := d.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `userid` + "`" + ` VARCHAR(31) PRIMARY KEY, ` + "`" + `username` + "`" + ` VARCHAR(255) NOT NULL, ` + "`" + `created_at` + "`" + ` BIGINT NOT NULL);")
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) Insert(ctx context.Context, d sqldb.Executor, m *Model) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `userid` + "`" + `, ` + "`" + `username` + "`" + `, ` + "`" + `created_at` + "`" + `) VALUES (?, ?, ?);", m.Userid, m.Username, m.Created)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) InsertBulk(ctx context.Context, d sqldb.Executor, models []*Model, allowConflict bool) error {
+	conflictSQL := ""
+	if allowConflict {
+		conflictSQL = " ON DUPLICATE KEY UPDATE ` + "`" + `userid` + "`" + ` = ` + "`" + `userid` + "`" + `"
+	}
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*3)
+	for _, m := range models {
+		placeholders = append(placeholders, "(?, ?, ?)")
+		args = append(args, m.Userid, m.Username, m.Created)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `userid` + "`" + `, ` + "`" + `username` + "`" + `, ` + "`" + `created_at` + "`" + `) VALUES "+strings.Join(placeholders, ", ")+conflictSQL+";", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) GetModelBetween(ctx context.Context, d sqldb.Executor, vSince int64, vUntil int64) (_ []Model, retErr error) {
+	res := []Model{}
+	rows, err := d.QueryContext(ctx, "SELECT ` + "`" + `userid` + "`" + `, ` + "`" + `username` + "`" + `, ` + "`" + `created_at` + "`" + ` FROM ` + "`" + `"+t.TableName+"` + "`" + ` WHERE ` + "`" + `created_at` + "`" + ` >= ? AND ` + "`" + `created_at` + "`" + ` < ? AND ` + "`" + `username` + "`" + ` <> '' ORDER BY ` + "`" + `created_at` + "`" + ` LIMIT 8;", vSince, vUntil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Model
+		if err := rows.Scan(&m.Userid, &m.Username, &m.Created); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *userModelTable) GetInfoSimilar(ctx context.Context, d sqldb.Executor, vUsername string, vUserid string) (_ []Info, retErr error) {
+	res := []Info{}
+	rows, err := d.QueryContext(ctx, "SELECT ` + "`" + `userid` + "`" + `, ` + "`" + `username` + "`" + ` FROM ` + "`" + `"+t.TableName+"` + "`" + ` WHERE ` + "`" + `username` + "`" + ` LIKE CONCAT(?, '%') OR ` + "`" + `userid` + "`" + ` = ? OR CAST(` + "`" + `userid` + "`" + ` AS CHAR) = ?;", vUsername, vUserid, vUserid)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Info
+		if err := rows.Scan(&m.Userid, &m.Username); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+`,
+			},
+		},
+		{
+			Name: "generates raw queries with keyword param names",
+			Fsys: fstest.MapFS{
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "raw",
+            "name": "ByType",
+            "sql": "SELECT {{columns}} FROM {{table}} WHERE {{username}} = :type AND {{userid}} <> :t AND {{userid}} <> :ctx;",
+            "params": [
+              {"name": "type", "col": "username"},
+              {"name": "t", "col": "userid"},
+              {"name": "ctx", "col": "userid"}
+            ]
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Output: map[string]string{
+				"model_gen.go": `// Code generated by go generate forge model dev; DO NOT EDIT.
+
+package somepackage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"xorkevin.dev/forge/model/sqldb"
+)
+
+type (
+	userModelTable struct {
+		TableName string
+	}
+)
+
+func (t *userModelTable) Setup(ctx context.Context, d sqldb.Executor) error {
+	_, err := d.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+t.TableName+" (userid VARCHAR(31) PRIMARY KEY, username VARCHAR(255) NOT NULL);")
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) Insert(ctx context.Context, d sqldb.Executor, m *Model) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (userid, username) VALUES ($1, $2);", m.Userid, m.Username)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) InsertBulk(ctx context.Context, d sqldb.Executor, models []*Model, allowConflict bool) error {
+	conflictSQL := ""
+	if allowConflict {
+		conflictSQL = " ON CONFLICT DO NOTHING"
+	}
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*2)
+	for c, m := range models {
+		n := c * 2
+		placeholders = append(placeholders, fmt.Sprintf("($%d, $%d)", n+1, n+2))
+		args = append(args, m.Userid, m.Username)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (userid, username) VALUES "+strings.Join(placeholders, ", ")+conflictSQL+";", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) GetModelByType(ctx context.Context, d sqldb.Executor, vType string, vT string, vCtx string) (_ []Model, retErr error) {
+	res := []Model{}
+	rows, err := d.QueryContext(ctx, "SELECT userid, username FROM "+t.TableName+" WHERE username = $1 AND userid <> $2 AND userid <> $3;", vType, vT, vCtx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Model
+		if err := rows.Scan(&m.Userid, &m.Username); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+`,
+			},
+		},
+
+		{
+			Name: "generates getmapin queries",
+			Fsys: fstest.MapFS{
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "post": {
+      "queries": {
+        "Post": [
+          {
+            "kind": "getmapin",
+            "name": "ByIDs",
+            "conditions": [
+              {"col": "postid", "cond": "in"}
+            ],
+            "missing": true
+          },
+          {
+            "kind": "getmapin",
+            "name": "ByUsers",
+            "conditions": [
+              {"col": "status"},
+              {"col": "userid", "cond": "in"}
+            ],
+            "order": [
+              {"col": "postid", "dir": "DESC"}
+            ],
+            "many": true
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model post
+	//forge:model:query post
+	Post struct {
+		Postid string ` + "`" + `model:"postid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Userid string ` + "`" + `model:"userid,VARCHAR(31) NOT NULL"` + "`" + `
+		Status string ` + "`" + `model:"status,VARCHAR(31) NOT NULL"` + "`" + `
+		Body   string ` + "`" + `model:"body,VARCHAR(4095) NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Output: map[string]string{
+				"model_gen.go": `// Code generated by go generate forge model dev; DO NOT EDIT.
+
+package somepackage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"xorkevin.dev/forge/model/sqldb"
+)
+
+type (
+	postModelTable struct {
+		TableName string
+	}
+)
+
+func (t *postModelTable) Setup(ctx context.Context, d sqldb.Executor) error {
+	_, err := d.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+t.TableName+" (postid VARCHAR(31) PRIMARY KEY, userid VARCHAR(31) NOT NULL, status VARCHAR(31) NOT NULL, body VARCHAR(4095) NOT NULL);")
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *postModelTable) Insert(ctx context.Context, d sqldb.Executor, m *Post) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (postid, userid, status, body) VALUES ($1, $2, $3, $4);", m.Postid, m.Userid, m.Status, m.Body)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *postModelTable) InsertBulk(ctx context.Context, d sqldb.Executor, models []*Post, allowConflict bool) error {
+	conflictSQL := ""
+	if allowConflict {
+		conflictSQL = " ON CONFLICT DO NOTHING"
+	}
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*4)
+	for c, m := range models {
+		n := c * 4
+		placeholders = append(placeholders, fmt.Sprintf("($%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4))
+		args = append(args, m.Postid, m.Userid, m.Status, m.Body)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (postid, userid, status, body) VALUES "+strings.Join(placeholders, ", ")+conflictSQL+";", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *postModelTable) GetPostByIDs(ctx context.Context, d sqldb.Executor, postids []string) (_ map[string]Post, _ []string, retErr error) {
+	res := map[string]Post{}
+	if len(postids) == 0 {
+		return res, nil, nil
+	}
+	paramCount := 0
+	args := make([]interface{}, 0, paramCount+len(postids))
+	var placeholderspostids string
+	{
+		placeholders := make([]string, 0, len(postids))
+		for _, i := range postids {
+			paramCount++
+			placeholders = append(placeholders, fmt.Sprintf("($%d)", paramCount))
+			args = append(args, i)
+		}
+		placeholderspostids = strings.Join(placeholders, ", ")
+	}
+	rows, err := d.QueryContext(ctx, "SELECT postid, userid, status, body FROM "+t.TableName+" WHERE postid IN (VALUES "+placeholderspostids+");", args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Post
+		if err := rows.Scan(&m.Postid, &m.Userid, &m.Status, &m.Body); err != nil {
+			return nil, nil, err
+		}
+		res[m.Postid] = m
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	var missing []string
+	for _, i := range postids {
+		if _, ok := res[i]; !ok {
+			missing = append(missing, i)
+		}
+	}
+	return res, missing, nil
+}
+
+func (t *postModelTable) GetPostByUsers(ctx context.Context, d sqldb.Executor, status string, userids []string) (_ map[string][]Post, retErr error) {
+	res := map[string][]Post{}
+	if len(userids) == 0 {
+		return res, nil
+	}
+	paramCount := 1
+	args := make([]interface{}, 0, paramCount+len(userids))
+	args = append(args, status)
+	var placeholdersuserids string
+	{
+		placeholders := make([]string, 0, len(userids))
+		for _, i := range userids {
+			paramCount++
+			placeholders = append(placeholders, fmt.Sprintf("($%d)", paramCount))
+			args = append(args, i)
+		}
+		placeholdersuserids = strings.Join(placeholders, ", ")
+	}
+	rows, err := d.QueryContext(ctx, "SELECT postid, userid, status, body FROM "+t.TableName+" WHERE status = $1 AND userid IN (VALUES "+placeholdersuserids+") ORDER BY postid DESC;", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Post
+		if err := rows.Scan(&m.Postid, &m.Userid, &m.Status, &m.Body); err != nil {
+			return nil, err
+		}
+		res[m.Userid] = append(res[m.Userid], m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+`,
+			},
+		},
+
+		{
+			Name: "generates locking queries",
+			Fsys: fstest.MapFS{
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "job": {
+      "queries": {
+        "Job": [
+          {
+            "kind": "getoneeq",
+            "name": "ByIDForUpdate",
+            "conditions": [
+              {"col": "jobid"}
+            ],
+            "lock": {"mode": "update", "wait": "nowait"}
+          },
+          {
+            "kind": "getgroup",
+            "name": "AllForShare",
+            "order": [
+              {"col": "jobid"}
+            ],
+            "lock": {"mode": "share"}
+          },
+          {
+            "kind": "getgroupeq",
+            "name": "Claim",
+            "conditions": [
+              {"col": "status"}
+            ],
+            "order": [
+              {"col": "created_at"}
+            ],
+            "lock": {"mode": "update", "wait": "skiplocked"},
+            "stream": true
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model job
+	//forge:model:query job
+	Job struct {
+		Jobid   string ` + "`" + `model:"jobid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Status  string ` + "`" + `model:"status,VARCHAR(31) NOT NULL"` + "`" + `
+		Created int64  ` + "`" + `model:"created_at,BIGINT NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Output: map[string]string{
+				"model_gen.go": `// Code generated by go generate forge model dev; DO NOT EDIT.
+
+package somepackage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"xorkevin.dev/forge/model/sqldb"
+)
+
+type (
+	jobModelTable struct {
+		TableName string
+	}
+)
+
+func (t *jobModelTable) Setup(ctx context.Context, d sqldb.Executor) error {
+	_, err := d.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+t.TableName+" (jobid VARCHAR(31) PRIMARY KEY, status VARCHAR(31) NOT NULL, created_at BIGINT NOT NULL);")
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *jobModelTable) Insert(ctx context.Context, d sqldb.Executor, m *Job) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (jobid, status, created_at) VALUES ($1, $2, $3);", m.Jobid, m.Status, m.Created)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *jobModelTable) InsertBulk(ctx context.Context, d sqldb.Executor, models []*Job, allowConflict bool) error {
+	conflictSQL := ""
+	if allowConflict {
+		conflictSQL = " ON CONFLICT DO NOTHING"
+	}
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*3)
+	for c, m := range models {
+		n := c * 3
+		placeholders = append(placeholders, fmt.Sprintf("($%d, $%d, $%d)", n+1, n+2, n+3))
+		args = append(args, m.Jobid, m.Status, m.Created)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (jobid, status, created_at) VALUES "+strings.Join(placeholders, ", ")+conflictSQL+";", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *jobModelTable) GetJobByIDForUpdate(ctx context.Context, d sqldb.Executor, jobid string) (*Job, error) {
+	m := &Job{}
+	if err := d.QueryRowContext(ctx, "SELECT jobid, status, created_at FROM "+t.TableName+" WHERE jobid = $1 FOR UPDATE NOWAIT;", jobid).Scan(&m.Jobid, &m.Status, &m.Created); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (t *jobModelTable) GetJobAllForShare(ctx context.Context, d sqldb.Executor, limit, offset int) (_ []Job, retErr error) {
+	res := make([]Job, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT jobid, status, created_at FROM "+t.TableName+" ORDER BY jobid LIMIT $1 OFFSET $2 FOR SHARE;", limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Job
+		if err := rows.Scan(&m.Jobid, &m.Status, &m.Created); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *jobModelTable) GetJobClaim(ctx context.Context, d sqldb.Executor, status string, limit, offset int) (_ []Job, retErr error) {
+	res := make([]Job, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT jobid, status, created_at FROM "+t.TableName+" WHERE status = $3 ORDER BY created_at LIMIT $1 OFFSET $2 FOR UPDATE SKIP LOCKED;", limit, offset, status)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Job
+		if err := rows.Scan(&m.Jobid, &m.Status, &m.Created); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *jobModelTable) StreamJobClaim(ctx context.Context, d sqldb.Executor, status string, fn func(m Job) error) (retErr error) {
+	rows, err := d.QueryContext(ctx, "SELECT jobid, status, created_at FROM "+t.TableName+" WHERE status = $1 ORDER BY created_at FOR UPDATE SKIP LOCKED;", status)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Job
+		if err := rows.Scan(&m.Jobid, &m.Status, &m.Created); err != nil {
+			return err
+		}
+		if err := fn(m); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return nil
+}
+`,
+			},
+		},
+		{
+			Name:    "generates mysql locking queries",
+			Dialect: DialectMySQL{},
+			Fsys: fstest.MapFS{
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "job": {
+      "queries": {
+        "Job": [
+          {
+            "kind": "getoneeq",
+            "name": "ByIDForUpdate",
+            "conditions": [
+              {"col": "jobid"}
+            ],
+            "lock": {"mode": "update", "wait": "nowait"}
+          },
+          {
+            "kind": "getgroup",
+            "name": "AllForShare",
+            "order": [
+              {"col": "jobid"}
+            ],
+            "lock": {"mode": "share"}
+          },
+          {
+            "kind": "getgroupeq",
+            "name": "Claim",
+            "conditions": [
+              {"col": "status"}
+            ],
+            "order": [
+              {"col": "created_at"}
+            ],
+            "lock": {"mode": "update", "wait": "skiplocked"},
+            "stream": true
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model job
+	//forge:model:query job
+	Job struct {
+		Jobid   string ` + "`" + `model:"jobid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Status  string ` + "`" + `model:"status,VARCHAR(31) NOT NULL"` + "`" + `
+		Created int64  ` + "`" + `model:"created_at,BIGINT NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Output: map[string]string{
+				"model_gen.go": `// Code generated by go generate forge model dev; DO NOT EDIT.
+
+package somepackage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"xorkevin.dev/forge/model/sqldb"
+)
+
+type (
+	jobModelTable struct {
+		TableName string
+	}
+)
+
+func (t *jobModelTable) Setup(ctx context.Context, d sqldb.Executor) error {
+	_, err := d.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `jobid` + "`" + ` VARCHAR(31) PRIMARY KEY, ` + "`" + `status` + "`" + ` VARCHAR(31) NOT NULL, ` + "`" + `created_at` + "`" + ` BIGINT NOT NULL);")
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *jobModelTable) Insert(ctx context.Context, d sqldb.Executor, m *Job) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `jobid` + "`" + `, ` + "`" + `status` + "`" + `, ` + "`" + `created_at` + "`" + `) VALUES (?, ?, ?);", m.Jobid, m.Status, m.Created)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *jobModelTable) InsertBulk(ctx context.Context, d sqldb.Executor, models []*Job, allowConflict bool) error {
+	conflictSQL := ""
+	if allowConflict {
+		conflictSQL = " ON DUPLICATE KEY UPDATE ` + "`" + `jobid` + "`" + ` = ` + "`" + `jobid` + "`" + `"
+	}
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*3)
+	for _, m := range models {
+		placeholders = append(placeholders, "(?, ?, ?)")
+		args = append(args, m.Jobid, m.Status, m.Created)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `jobid` + "`" + `, ` + "`" + `status` + "`" + `, ` + "`" + `created_at` + "`" + `) VALUES "+strings.Join(placeholders, ", ")+conflictSQL+";", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *jobModelTable) GetJobByIDForUpdate(ctx context.Context, d sqldb.Executor, jobid string) (*Job, error) {
+	m := &Job{}
+	if err := d.QueryRowContext(ctx, "SELECT ` + "`" + `jobid` + "`" + `, ` + "`" + `status` + "`" + `, ` + "`" + `created_at` + "`" + ` FROM ` + "`" + `"+t.TableName+"` + "`" + ` WHERE ` + "`" + `jobid` + "`" + ` = ? FOR UPDATE NOWAIT;", jobid).Scan(&m.Jobid, &m.Status, &m.Created); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (t *jobModelTable) GetJobAllForShare(ctx context.Context, d sqldb.Executor, limit, offset int) (_ []Job, retErr error) {
+	res := make([]Job, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT ` + "`" + `jobid` + "`" + `, ` + "`" + `status` + "`" + `, ` + "`" + `created_at` + "`" + ` FROM ` + "`" + `"+t.TableName+"` + "`" + ` ORDER BY ` + "`" + `jobid` + "`" + ` LIMIT ? OFFSET ? LOCK IN SHARE MODE;", limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Job
+		if err := rows.Scan(&m.Jobid, &m.Status, &m.Created); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *jobModelTable) GetJobClaim(ctx context.Context, d sqldb.Executor, status string, limit, offset int) (_ []Job, retErr error) {
+	res := make([]Job, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT ` + "`" + `jobid` + "`" + `, ` + "`" + `status` + "`" + `, ` + "`" + `created_at` + "`" + ` FROM ` + "`" + `"+t.TableName+"` + "`" + ` WHERE ` + "`" + `status` + "`" + ` = ? ORDER BY ` + "`" + `created_at` + "`" + ` LIMIT ? OFFSET ? FOR UPDATE SKIP LOCKED;", status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Job
+		if err := rows.Scan(&m.Jobid, &m.Status, &m.Created); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *jobModelTable) StreamJobClaim(ctx context.Context, d sqldb.Executor, status string, fn func(m Job) error) (retErr error) {
+	rows, err := d.QueryContext(ctx, "SELECT ` + "`" + `jobid` + "`" + `, ` + "`" + `status` + "`" + `, ` + "`" + `created_at` + "`" + ` FROM ` + "`" + `"+t.TableName+"` + "`" + ` WHERE ` + "`" + `status` + "`" + ` = ? ORDER BY ` + "`" + `created_at` + "`" + ` FOR UPDATE SKIP LOCKED;", status)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Job
+		if err := rows.Scan(&m.Jobid, &m.Status, &m.Created); err != nil {
+			return err
+		}
+		if err := fn(m); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return nil
+}
+`,
+			},
+		},
+
+		{
+			Name: "generates soft delete queries",
+			Fsys: fstest.MapFS{
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "model": {
+        "softDelete": "deleted_at",
+        "filter": true
+      },
+      "queries": {
+        "Model": [
+          {
+            "kind": "getoneeq",
+            "name": "ByID",
+            "conditions": [
+              {"col": "userid"}
+            ]
+          },
+          {
+            "kind": "getgroup",
+            "name": "All",
+            "sort": [
+              {"name": "Username", "order": [{"col": "username"}]},
+              {"name": "UsernameDesc", "order": [{"col": "username", "dir": "desc"}]}
+            ]
+          },
+          {
+            "kind": "count",
+            "name": "All"
+          },
+          {
+            "kind": "updeq",
+            "name": "ByID",
+            "conditions": [
+              {"col": "userid"}
+            ]
+          },
+          {
+            "kind": "deleq",
+            "name": "ByID",
+            "conditions": [
+              {"col": "userid"}
+            ],
+            "affected": "notfound"
+          }
+        ]
+      }
+    },
+    "post": {
+      "model": {
+        "softDelete": "deleted"
+      },
+      "queries": {
+        "Post": [
+          {
+            "kind": "getgroupeq",
+            "name": "ByUserid",
+            "conditions": [
+              {"col": "userid"}
+            ],
+            "order": [
+              {"col": "postid"}
+            ]
+          },
+          {
+            "kind": "deleq",
+            "name": "ByUserid",
+            "conditions": [
+              {"col": "userid"}
+            ],
+            "affected": "count"
+          }
+        ]
+      }
+    }
+  },
+  "joins": {
+    "userPost": {
+      "from": "post",
+      "join": [
+        {
+          "model": "user",
+          "on": [
+            {"col": "user.userid", "ref": "post.userid"}
+          ]
+        }
+      ],
+      "queries": [
+        {
+          "kind": "getgroupeq",
+          "name": "ByUserid",
+          "conditions": [
+            {"col": "post.userid"}
+          ],
+          "order": [
+            {"col": "post.postid"}
+          ]
+        }
+      ]
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+import (
+	"time"
+)
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid    string     ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username  string     ` + "`" + `model:"username,VARCHAR(255) NOT NULL UNIQUE"` + "`" + `
+		DeletedAt *time.Time ` + "`" + `model:"deleted_at,TIMESTAMP"` + "`" + `
+	}
+
+	//forge:model post
+	//forge:model:query post
+	Post struct {
+		Postid  string ` + "`" + `model:"postid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Userid  string ` + "`" + `model:"userid,VARCHAR(31) NOT NULL"` + "`" + `
+		Deleted *int64 ` + "`" + `model:"deleted,BIGINT"` + "`" + `
+	}
+
+	//forge:model:join userPost
+	UserPost struct {
+		Postid   string ` + "`" + `model:"post.postid"` + "`" + `
+		Username string ` + "`" + `model:"user.username"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Output: map[string]string{
+				"model_gen.go": `// Code generated by go generate forge model dev; DO NOT EDIT.
+
+package somepackage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"xorkevin.dev/forge/model/sqldb"
+)
+
+type (
+	userModelTable struct {
+		TableName string
+		// Clock returns the current time, and is time.Now if nil
+		Clock func() time.Time
+	}
+)
+
+func (t *userModelTable) now() time.Time {
+	if t.Clock != nil {
+		return t.Clock()
+	}
+	return time.Now()
+}
+
+func (t *userModelTable) Setup(ctx context.Context, d sqldb.Executor) error {
+	_, err := d.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+t.TableName+" (userid VARCHAR(31) PRIMARY KEY, username VARCHAR(255) NOT NULL UNIQUE, deleted_at TIMESTAMP);")
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) Insert(ctx context.Context, d sqldb.Executor, m *Model) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (userid, username, deleted_at) VALUES ($1, $2, $3);", m.Userid, m.Username, m.DeletedAt)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) InsertBulk(ctx context.Context, d sqldb.Executor, models []*Model, allowConflict bool) error {
+	conflictSQL := ""
+	if allowConflict {
+		conflictSQL = " ON CONFLICT DO NOTHING"
+	}
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*3)
+	for c, m := range models {
+		n := c * 3
+		placeholders = append(placeholders, fmt.Sprintf("($%d, $%d, $%d)", n+1, n+2, n+3))
+		args = append(args, m.Userid, m.Username, m.DeletedAt)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (userid, username, deleted_at) VALUES "+strings.Join(placeholders, ", ")+conflictSQL+";", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) GetModelByID(ctx context.Context, d sqldb.Executor, userid string) (*Model, error) {
+	m := &Model{}
+	if err := d.QueryRowContext(ctx, "SELECT userid, username, deleted_at FROM "+t.TableName+" WHERE userid = $1 AND deleted_at IS NULL;", userid).Scan(&m.Userid, &m.Username, &m.DeletedAt); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (t *userModelTable) GetModelByIDIncludingDeleted(ctx context.Context, d sqldb.Executor, userid string) (*Model, error) {
+	m := &Model{}
+	if err := d.QueryRowContext(ctx, "SELECT userid, username, deleted_at FROM "+t.TableName+" WHERE userid = $1;", userid).Scan(&m.Userid, &m.Username, &m.DeletedAt); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+type (
+	// ModelAllSort is a sort of GetModelAll
+	ModelAllSort int
+)
+
+const (
+	ModelAllSortUsername ModelAllSort = iota
+	ModelAllSortUsernameDesc
+)
+
+func (t *userModelTable) GetModelAll(ctx context.Context, d sqldb.Executor, sort ModelAllSort, limit, offset int) (_ []Model, retErr error) {
+	// unknown sorts use the first sort
+	orderBy := "username"
+	switch sort {
+	case ModelAllSortUsernameDesc:
+		orderBy = "username desc"
+	}
+	res := make([]Model, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT userid, username, deleted_at FROM "+t.TableName+" WHERE deleted_at IS NULL ORDER BY "+orderBy+" LIMIT $1 OFFSET $2;", limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Model
+		if err := rows.Scan(&m.Userid, &m.Username, &m.DeletedAt); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *userModelTable) GetModelAllIncludingDeleted(ctx context.Context, d sqldb.Executor, sort ModelAllSort, limit, offset int) (_ []Model, retErr error) {
+	// unknown sorts use the first sort
+	orderBy := "username"
+	switch sort {
+	case ModelAllSortUsernameDesc:
+		orderBy = "username desc"
+	}
+	res := make([]Model, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT userid, username, deleted_at FROM "+t.TableName+" ORDER BY "+orderBy+" LIMIT $1 OFFSET $2;", limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Model
+		if err := rows.Scan(&m.Userid, &m.Username, &m.DeletedAt); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *userModelTable) CountModelAll(ctx context.Context, d sqldb.Executor) (int, error) {
+	var count int
+	if err := d.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+t.TableName+" WHERE deleted_at IS NULL;").Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (t *userModelTable) CountModelAllIncludingDeleted(ctx context.Context, d sqldb.Executor) (int, error) {
+	var count int
+	if err := d.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+t.TableName+";").Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (t *userModelTable) UpdModelByID(ctx context.Context, d sqldb.Executor, m *Model, userid string) error {
+	_, err := d.ExecContext(ctx, "UPDATE "+t.TableName+" SET (userid, username, deleted_at) = ($1, $2, $3) WHERE userid = $4;", m.Userid, m.Username, m.DeletedAt, userid)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) DelByID(ctx context.Context, d sqldb.Executor, userid string) error {
+	res, err := d.ExecContext(ctx, "UPDATE "+t.TableName+" SET deleted_at = $1 WHERE userid = $2 AND deleted_at IS NULL;", t.now(), userid)
+	if err != nil {
+		return err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return sqldb.ErrNotFound
+	}
+	return nil
+}
+
+func (t *userModelTable) HardDelByID(ctx context.Context, d sqldb.Executor, userid string) error {
+	res, err := d.ExecContext(ctx, "DELETE FROM "+t.TableName+" WHERE userid = $1;", userid)
+	if err != nil {
+		return err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return sqldb.ErrNotFound
+	}
+	return nil
+}
+
+type (
+	// userModelFilter builds a query of userModelTable with
+	// conditions joined by AND
+	userModelFilter struct {
+		t      *userModelTable
+		conds  []string
+		args   []interface{}
+		order  []string
+		limit  int
+		offset int
+
+		includingDeleted bool
+	}
+)
+
+func (t *userModelTable) Filter() *userModelFilter {
+	return &userModelFilter{
+		t: t,
+	}
+}
+
+func (f *userModelFilter) WhereUseridEq(vUserid string) *userModelFilter {
+	f.args = append(f.args, vUserid)
+	f.conds = append(f.conds, fmt.Sprintf("userid = $%d", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereUseridNeq(vUserid string) *userModelFilter {
+	f.args = append(f.args, vUserid)
+	f.conds = append(f.conds, fmt.Sprintf("userid <> $%d", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereUseridLt(vUserid string) *userModelFilter {
+	f.args = append(f.args, vUserid)
+	f.conds = append(f.conds, fmt.Sprintf("userid < $%d", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereUseridLeq(vUserid string) *userModelFilter {
+	f.args = append(f.args, vUserid)
+	f.conds = append(f.conds, fmt.Sprintf("userid <= $%d", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereUseridGt(vUserid string) *userModelFilter {
+	f.args = append(f.args, vUserid)
+	f.conds = append(f.conds, fmt.Sprintf("userid > $%d", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereUseridGeq(vUserid string) *userModelFilter {
+	f.args = append(f.args, vUserid)
+	f.conds = append(f.conds, fmt.Sprintf("userid >= $%d", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereUseridIn(vUserids []string) *userModelFilter {
+	if len(vUserids) == 0 {
+		// an empty IN list is invalid sql, and matches no rows
+		f.conds = append(f.conds, "1 = 0")
+		return f
+	}
+	placeholders := make([]string, 0, len(vUserids))
+	for _, i := range vUserids {
+		f.args = append(f.args, i)
+		placeholders = append(placeholders, fmt.Sprintf("($%d)", len(f.args)))
+	}
+	f.conds = append(f.conds, "userid IN (VALUES "+strings.Join(placeholders, ", ")+")")
+	return f
+}
+
+// WhereUseridLike matches a raw LIKE pattern with an ESCAPE of
+// sqldb.LikeEscape, and text of the pattern which is matched literally may be
+// escaped by sqldb.EscapeLike
+func (f *userModelFilter) WhereUseridLike(vUseridPattern string) *userModelFilter {
+	f.args = append(f.args, vUseridPattern)
+	f.conds = append(f.conds, fmt.Sprintf("userid LIKE $%d ESCAPE '!'", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereUseridIsNull() *userModelFilter {
+	f.conds = append(f.conds, "userid IS NULL")
+	return f
+}
+
+func (f *userModelFilter) WhereUseridNotNull() *userModelFilter {
+	f.conds = append(f.conds, "userid IS NOT NULL")
+	return f
+}
+
+func (f *userModelFilter) OrderUserid(desc bool) *userModelFilter {
+	if desc {
+		f.order = append(f.order, "userid DESC")
+	} else {
+		f.order = append(f.order, "userid")
+	}
+	return f
+}
+
+func (f *userModelFilter) WhereUsernameEq(vUsername string) *userModelFilter {
+	f.args = append(f.args, vUsername)
+	f.conds = append(f.conds, fmt.Sprintf("username = $%d", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereUsernameNeq(vUsername string) *userModelFilter {
+	f.args = append(f.args, vUsername)
+	f.conds = append(f.conds, fmt.Sprintf("username <> $%d", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereUsernameLt(vUsername string) *userModelFilter {
+	f.args = append(f.args, vUsername)
+	f.conds = append(f.conds, fmt.Sprintf("username < $%d", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereUsernameLeq(vUsername string) *userModelFilter {
+	f.args = append(f.args, vUsername)
+	f.conds = append(f.conds, fmt.Sprintf("username <= $%d", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereUsernameGt(vUsername string) *userModelFilter {
+	f.args = append(f.args, vUsername)
+	f.conds = append(f.conds, fmt.Sprintf("username > $%d", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereUsernameGeq(vUsername string) *userModelFilter {
+	f.args = append(f.args, vUsername)
+	f.conds = append(f.conds, fmt.Sprintf("username >= $%d", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereUsernameIn(vUsernames []string) *userModelFilter {
+	if len(vUsernames) == 0 {
+		// an empty IN list is invalid sql, and matches no rows
+		f.conds = append(f.conds, "1 = 0")
+		return f
+	}
+	placeholders := make([]string, 0, len(vUsernames))
+	for _, i := range vUsernames {
+		f.args = append(f.args, i)
+		placeholders = append(placeholders, fmt.Sprintf("($%d)", len(f.args)))
+	}
+	f.conds = append(f.conds, "username IN (VALUES "+strings.Join(placeholders, ", ")+")")
+	return f
+}
+
+// WhereUsernameLike matches a raw LIKE pattern with an ESCAPE of
+// sqldb.LikeEscape, and text of the pattern which is matched literally may be
+// escaped by sqldb.EscapeLike
+func (f *userModelFilter) WhereUsernameLike(vUsernamePattern string) *userModelFilter {
+	f.args = append(f.args, vUsernamePattern)
+	f.conds = append(f.conds, fmt.Sprintf("username LIKE $%d ESCAPE '!'", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereUsernameIsNull() *userModelFilter {
+	f.conds = append(f.conds, "username IS NULL")
+	return f
+}
+
+func (f *userModelFilter) WhereUsernameNotNull() *userModelFilter {
+	f.conds = append(f.conds, "username IS NOT NULL")
+	return f
+}
+
+func (f *userModelFilter) OrderUsername(desc bool) *userModelFilter {
+	if desc {
+		f.order = append(f.order, "username DESC")
+	} else {
+		f.order = append(f.order, "username")
+	}
+	return f
+}
+
+func (f *userModelFilter) WhereDeletedAtEq(vDeletedAt *time.Time) *userModelFilter {
+	f.args = append(f.args, vDeletedAt)
+	f.conds = append(f.conds, fmt.Sprintf("deleted_at = $%d", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereDeletedAtNeq(vDeletedAt *time.Time) *userModelFilter {
+	f.args = append(f.args, vDeletedAt)
+	f.conds = append(f.conds, fmt.Sprintf("deleted_at <> $%d", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereDeletedAtLt(vDeletedAt *time.Time) *userModelFilter {
+	f.args = append(f.args, vDeletedAt)
+	f.conds = append(f.conds, fmt.Sprintf("deleted_at < $%d", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereDeletedAtLeq(vDeletedAt *time.Time) *userModelFilter {
+	f.args = append(f.args, vDeletedAt)
+	f.conds = append(f.conds, fmt.Sprintf("deleted_at <= $%d", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereDeletedAtGt(vDeletedAt *time.Time) *userModelFilter {
+	f.args = append(f.args, vDeletedAt)
+	f.conds = append(f.conds, fmt.Sprintf("deleted_at > $%d", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereDeletedAtGeq(vDeletedAt *time.Time) *userModelFilter {
+	f.args = append(f.args, vDeletedAt)
+	f.conds = append(f.conds, fmt.Sprintf("deleted_at >= $%d", len(f.args)))
+	return f
+}
+
+func (f *userModelFilter) WhereDeletedAtIn(vDeletedAts []*time.Time) *userModelFilter {
+	if len(vDeletedAts) == 0 {
+		// an empty IN list is invalid sql, and matches no rows
+		f.conds = append(f.conds, "1 = 0")
+		return f
+	}
+	placeholders := make([]string, 0, len(vDeletedAts))
+	for _, i := range vDeletedAts {
+		f.args = append(f.args, i)
+		placeholders = append(placeholders, fmt.Sprintf("($%d)", len(f.args)))
+	}
+	f.conds = append(f.conds, "deleted_at IN (VALUES "+strings.Join(placeholders, ", ")+")")
+	return f
+}
+
+func (f *userModelFilter) WhereDeletedAtIsNull() *userModelFilter {
+	f.conds = append(f.conds, "deleted_at IS NULL")
+	return f
+}
+
+func (f *userModelFilter) WhereDeletedAtNotNull() *userModelFilter {
+	f.conds = append(f.conds, "deleted_at IS NOT NULL")
+	return f
+}
+
+func (f *userModelFilter) OrderDeletedAt(desc bool) *userModelFilter {
+	if desc {
+		f.order = append(f.order, "deleted_at DESC")
+	} else {
+		f.order = append(f.order, "deleted_at")
+	}
+	return f
+}
+
+// IncludingDeleted includes soft deleted rows in the query
+func (f *userModelFilter) IncludingDeleted() *userModelFilter {
+	f.includingDeleted = true
+	return f
+}
+
+// Limit limits the number of rows of the query, and is not applied if zero or
+// negative
+func (f *userModelFilter) Limit(limit int) *userModelFilter {
+	if limit < 0 {
+		limit = 0
+	}
+	f.limit = limit
+	return f
+}
+
+// Offset skips rows of the query, and is only applied with a limit
+func (f *userModelFilter) Offset(offset int) *userModelFilter {
+	f.offset = offset
+	return f
+}
+
+func (f *userModelFilter) query(cols string) (string, []interface{}) {
+	t := f.t
+	query := "SELECT " + cols + " FROM " + t.TableName
+	conds := f.conds
+	if !f.includingDeleted {
+		conds = append([]string{"deleted_at IS NULL"}, conds...)
+	}
+	if len(conds) != 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	if len(f.order) != 0 {
+		query += " ORDER BY " + strings.Join(f.order, ", ")
+	}
+	args := make([]interface{}, 0, len(f.args)+2)
+	args = append(args, f.args...)
+	if f.limit > 0 {
+		args = append(args, f.limit)
+		if f.offset > 0 {
+			args = append(args, f.offset)
+			query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+		} else {
+			query += fmt.Sprintf(" LIMIT $%d", len(args))
+		}
+	}
+	return query + ";", args
+}
+
+func (f *userModelFilter) GetModel(ctx context.Context, d sqldb.Executor) (_ []Model, retErr error) {
+	query, args := f.query("userid, username, deleted_at")
+	res := make([]Model, 0, f.limit)
+	rows, err := d.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Model
+		if err := rows.Scan(&m.Userid, &m.Username, &m.DeletedAt); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+type (
+	postModelTable struct {
+		TableName string
+		// Clock returns the current time, and is time.Now if nil
+		Clock func() time.Time
+	}
+)
+
+func (t *postModelTable) now() time.Time {
+	if t.Clock != nil {
+		return t.Clock()
+	}
+	return time.Now()
+}
+
+func (t *postModelTable) Setup(ctx context.Context, d sqldb.Executor) error {
+	_, err := d.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+t.TableName+" (postid VARCHAR(31) PRIMARY KEY, userid VARCHAR(31) NOT NULL, deleted BIGINT);")
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *postModelTable) Insert(ctx context.Context, d sqldb.Executor, m *Post) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (postid, userid, deleted) VALUES ($1, $2, $3);", m.Postid, m.Userid, m.Deleted)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *postModelTable) InsertBulk(ctx context.Context, d sqldb.Executor, models []*Post, allowConflict bool) error {
+	conflictSQL := ""
+	if allowConflict {
+		conflictSQL = " ON CONFLICT DO NOTHING"
+	}
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*3)
+	for c, m := range models {
+		n := c * 3
+		placeholders = append(placeholders, fmt.Sprintf("($%d, $%d, $%d)", n+1, n+2, n+3))
+		args = append(args, m.Postid, m.Userid, m.Deleted)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (postid, userid, deleted) VALUES "+strings.Join(placeholders, ", ")+conflictSQL+";", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *postModelTable) GetPostByUserid(ctx context.Context, d sqldb.Executor, userid string, limit, offset int) (_ []Post, retErr error) {
+	res := make([]Post, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT postid, userid, deleted FROM "+t.TableName+" WHERE userid = $3 AND deleted IS NULL ORDER BY postid LIMIT $1 OFFSET $2;", limit, offset, userid)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Post
+		if err := rows.Scan(&m.Postid, &m.Userid, &m.Deleted); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *postModelTable) GetPostByUseridIncludingDeleted(ctx context.Context, d sqldb.Executor, userid string, limit, offset int) (_ []Post, retErr error) {
+	res := make([]Post, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT postid, userid, deleted FROM "+t.TableName+" WHERE userid = $3 ORDER BY postid LIMIT $1 OFFSET $2;", limit, offset, userid)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Post
+		if err := rows.Scan(&m.Postid, &m.Userid, &m.Deleted); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *postModelTable) DelByUserid(ctx context.Context, d sqldb.Executor, userid string) (int64, error) {
+	res, err := d.ExecContext(ctx, "UPDATE "+t.TableName+" SET deleted = $1 WHERE userid = $2 AND deleted IS NULL;", t.now().Unix(), userid)
+	if err != nil {
+		return 0, err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (t *postModelTable) HardDelByUserid(ctx context.Context, d sqldb.Executor, userid string) (int64, error) {
+	res, err := d.ExecContext(ctx, "DELETE FROM "+t.TableName+" WHERE userid = $1;", userid)
+	if err != nil {
+		return 0, err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+type (
+	// userPostModelTable queries the joined tables of userPost
+	userPostModelTable struct {
+		PostTableName string
+		UserTableName string
+	}
+)
+
+func (t *userPostModelTable) GetUserPostByUserid(ctx context.Context, d sqldb.Executor, userid string, limit, offset int) (_ []UserPost, retErr error) {
+	res := make([]UserPost, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT t1.postid, t2.username FROM "+t.PostTableName+" t1 INNER JOIN "+t.UserTableName+" t2 ON t2.userid = t1.userid AND t2.deleted_at IS NULL WHERE t1.userid = $3 AND t1.deleted IS NULL ORDER BY t1.postid LIMIT $1 OFFSET $2;", limit, offset, userid)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m UserPost
+		if err := rows.Scan(&m.Postid, &m.Username); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *userPostModelTable) GetUserPostByUseridIncludingDeleted(ctx context.Context, d sqldb.Executor, userid string, limit, offset int) (_ []UserPost, retErr error) {
+	res := make([]UserPost, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT t1.postid, t2.username FROM "+t.PostTableName+" t1 INNER JOIN "+t.UserTableName+" t2 ON t2.userid = t1.userid WHERE t1.userid = $3 ORDER BY t1.postid LIMIT $1 OFFSET $2;", limit, offset, userid)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m UserPost
+		if err := rows.Scan(&m.Postid, &m.Username); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+`,
+			},
+		},
+		{
+			Name:    "generates mysql soft delete queries",
+			Dialect: DialectMySQL{},
+			Fsys: fstest.MapFS{
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "model": {
+        "softDelete": "deleted_at",
+        "filter": true
+      },
+      "queries": {
+        "Model": [
+          {
+            "kind": "getoneeq",
+            "name": "ByID",
+            "conditions": [
+              {"col": "userid"}
+            ]
+          },
+          {
+            "kind": "getgroup",
+            "name": "All",
+            "sort": [
+              {"name": "Username", "order": [{"col": "username"}]},
+              {"name": "UsernameDesc", "order": [{"col": "username", "dir": "desc"}]}
+            ]
+          },
+          {
+            "kind": "count",
+            "name": "All"
+          },
+          {
+            "kind": "updeq",
+            "name": "ByID",
+            "conditions": [
+              {"col": "userid"}
+            ]
+          },
+          {
+            "kind": "deleq",
+            "name": "ByID",
+            "conditions": [
+              {"col": "userid"}
+            ],
+            "affected": "notfound"
+          }
+        ]
+      }
+    },
+    "post": {
+      "model": {
+        "softDelete": "deleted"
+      },
+      "queries": {
+        "Post": [
+          {
+            "kind": "getgroupeq",
+            "name": "ByUserid",
+            "conditions": [
+              {"col": "userid"}
+            ],
+            "order": [
+              {"col": "postid"}
+            ]
+          },
+          {
+            "kind": "deleq",
+            "name": "ByUserid",
+            "conditions": [
+              {"col": "userid"}
+            ],
+            "affected": "count"
+          }
+        ]
+      }
+    }
+  },
+  "joins": {
+    "userPost": {
+      "from": "post",
+      "join": [
+        {
+          "model": "user",
+          "on": [
+            {"col": "user.userid", "ref": "post.userid"}
+          ]
+        }
+      ],
+      "queries": [
+        {
+          "kind": "getgroupeq",
+          "name": "ByUserid",
+          "conditions": [
+            {"col": "post.userid"}
+          ],
+          "order": [
+            {"col": "post.postid"}
+          ]
+        }
+      ]
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+import (
+	"time"
+)
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid    string     ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username  string     ` + "`" + `model:"username,VARCHAR(255) NOT NULL UNIQUE"` + "`" + `
+		DeletedAt *time.Time ` + "`" + `model:"deleted_at,TIMESTAMP"` + "`" + `
+	}
+
+	//forge:model post
+	//forge:model:query post
+	Post struct {
+		Postid  string ` + "`" + `model:"postid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Userid  string ` + "`" + `model:"userid,VARCHAR(31) NOT NULL"` + "`" + `
+		Deleted *int64 ` + "`" + `model:"deleted,BIGINT"` + "`" + `
+	}
+
+	//forge:model:join userPost
+	UserPost struct {
+		Postid   string ` + "`" + `model:"post.postid"` + "`" + `
+		Username string ` + "`" + `model:"user.username"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Output: map[string]string{
+				"model_gen.go": `// Code generated by go generate forge model dev; DO NOT EDIT.
+
+package somepackage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"xorkevin.dev/forge/model/sqldb"
+)
+
+type (
+	userModelTable struct {
+		TableName string
+		// Clock returns the current time, and is time.Now if nil
+		Clock func() time.Time
+	}
+)
+
+func (t *userModelTable) now() time.Time {
+	if t.Clock != nil {
+		return t.Clock()
+	}
+	return time.Now()
+}
+
+func (t *userModelTable) Setup(ctx context.Context, d sqldb.Executor) error {
+	_, err := d.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `userid` + "`" + ` VARCHAR(31) PRIMARY KEY, ` + "`" + `username` + "`" + ` VARCHAR(255) NOT NULL UNIQUE, ` + "`" + `deleted_at` + "`" + ` TIMESTAMP);")
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) Insert(ctx context.Context, d sqldb.Executor, m *Model) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `userid` + "`" + `, ` + "`" + `username` + "`" + `, ` + "`" + `deleted_at` + "`" + `) VALUES (?, ?, ?);", m.Userid, m.Username, m.DeletedAt)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) InsertBulk(ctx context.Context, d sqldb.Executor, models []*Model, allowConflict bool) error {
+	conflictSQL := ""
+	if allowConflict {
+		conflictSQL = " ON DUPLICATE KEY UPDATE ` + "`" + `userid` + "`" + ` = ` + "`" + `userid` + "`" + `"
+	}
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*3)
+	for _, m := range models {
+		placeholders = append(placeholders, "(?, ?, ?)")
+		args = append(args, m.Userid, m.Username, m.DeletedAt)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `userid` + "`" + `, ` + "`" + `username` + "`" + `, ` + "`" + `deleted_at` + "`" + `) VALUES "+strings.Join(placeholders, ", ")+conflictSQL+";", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) GetModelByID(ctx context.Context, d sqldb.Executor, userid string) (*Model, error) {
+	m := &Model{}
+	if err := d.QueryRowContext(ctx, "SELECT ` + "`" + `userid` + "`" + `, ` + "`" + `username` + "`" + `, ` + "`" + `deleted_at` + "`" + ` FROM ` + "`" + `"+t.TableName+"` + "`" + ` WHERE ` + "`" + `userid` + "`" + ` = ? AND ` + "`" + `deleted_at` + "`" + ` IS NULL;", userid).Scan(&m.Userid, &m.Username, &m.DeletedAt); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (t *userModelTable) GetModelByIDIncludingDeleted(ctx context.Context, d sqldb.Executor, userid string) (*Model, error) {
+	m := &Model{}
+	if err := d.QueryRowContext(ctx, "SELECT ` + "`" + `userid` + "`" + `, ` + "`" + `username` + "`" + `, ` + "`" + `deleted_at` + "`" + ` FROM ` + "`" + `"+t.TableName+"` + "`" + ` WHERE ` + "`" + `userid` + "`" + ` = ?;", userid).Scan(&m.Userid, &m.Username, &m.DeletedAt); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+type (
+	// ModelAllSort is a sort of GetModelAll
+	ModelAllSort int
+)
+
+const (
+	ModelAllSortUsername ModelAllSort = iota
+	ModelAllSortUsernameDesc
+)
+
+func (t *userModelTable) GetModelAll(ctx context.Context, d sqldb.Executor, sort ModelAllSort, limit, offset int) (_ []Model, retErr error) {
+	// unknown sorts use the first sort
+	orderBy := "` + "`" + `username` + "`" + `"
+	switch sort {
+	case ModelAllSortUsernameDesc:
+		orderBy = "` + "`" + `username` + "`" + ` desc"
+	}
+	res := make([]Model, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT ` + "`" + `userid` + "`" + `, ` + "`" + `username` + "`" + `, ` + "`" + `deleted_at` + "`" + ` FROM ` + "`" + `"+t.TableName+"` + "`" + ` WHERE ` + "`" + `deleted_at` + "`" + ` IS NULL ORDER BY "+orderBy+" LIMIT ? OFFSET ?;", limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Model
+		if err := rows.Scan(&m.Userid, &m.Username, &m.DeletedAt); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *userModelTable) GetModelAllIncludingDeleted(ctx context.Context, d sqldb.Executor, sort ModelAllSort, limit, offset int) (_ []Model, retErr error) {
+	// unknown sorts use the first sort
+	orderBy := "` + "`" + `username` + "`" + `"
+	switch sort {
+	case ModelAllSortUsernameDesc:
+		orderBy = "` + "`" + `username` + "`" + ` desc"
+	}
+	res := make([]Model, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT ` + "`" + `userid` + "`" + `, ` + "`" + `username` + "`" + `, ` + "`" + `deleted_at` + "`" + ` FROM ` + "`" + `"+t.TableName+"` + "`" + ` ORDER BY "+orderBy+" LIMIT ? OFFSET ?;", limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Model
+		if err := rows.Scan(&m.Userid, &m.Username, &m.DeletedAt); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *userModelTable) CountModelAll(ctx context.Context, d sqldb.Executor) (int, error) {
+	var count int
+	if err := d.QueryRowContext(ctx, "SELECT COUNT(*) FROM ` + "`" + `"+t.TableName+"` + "`" + ` WHERE ` + "`" + `deleted_at` + "`" + ` IS NULL;").Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (t *userModelTable) CountModelAllIncludingDeleted(ctx context.Context, d sqldb.Executor) (int, error) {
+	var count int
+	if err := d.QueryRowContext(ctx, "SELECT COUNT(*) FROM ` + "`" + `"+t.TableName+"` + "`" + `;").Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (t *userModelTable) UpdModelByID(ctx context.Context, d sqldb.Executor, m *Model, userid string) error {
+	_, err := d.ExecContext(ctx, "UPDATE ` + "`" + `"+t.TableName+"` + "`" + ` SET ` + "`" + `userid` + "`" + ` = ?, ` + "`" + `username` + "`" + ` = ?, ` + "`" + `deleted_at` + "`" + ` = ? WHERE ` + "`" + `userid` + "`" + ` = ?;", m.Userid, m.Username, m.DeletedAt, userid)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) DelByID(ctx context.Context, d sqldb.Executor, userid string) error {
+	res, err := d.ExecContext(ctx, "UPDATE ` + "`" + `"+t.TableName+"` + "`" + ` SET ` + "`" + `deleted_at` + "`" + ` = ? WHERE ` + "`" + `userid` + "`" + ` = ? AND ` + "`" + `deleted_at` + "`" + ` IS NULL;", t.now(), userid)
+	if err != nil {
+		return err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return sqldb.ErrNotFound
+	}
+	return nil
+}
+
+func (t *userModelTable) HardDelByID(ctx context.Context, d sqldb.Executor, userid string) error {
+	res, err := d.ExecContext(ctx, "DELETE FROM ` + "`" + `"+t.TableName+"` + "`" + ` WHERE ` + "`" + `userid` + "`" + ` = ?;", userid)
+	if err != nil {
+		return err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return sqldb.ErrNotFound
+	}
+	return nil
+}
+
+type (
+	// userModelFilter builds a query of userModelTable with
+	// conditions joined by AND
+	userModelFilter struct {
+		t      *userModelTable
+		conds  []string
+		args   []interface{}
+		order  []string
+		limit  int
+		offset int
+
+		includingDeleted bool
+	}
+)
+
+func (t *userModelTable) Filter() *userModelFilter {
+	return &userModelFilter{
+		t: t,
+	}
+}
+
+func (f *userModelFilter) WhereUseridEq(vUserid string) *userModelFilter {
+	f.args = append(f.args, vUserid)
+	f.conds = append(f.conds, "` + "`" + `userid` + "`" + ` = ?")
+	return f
+}
+
+func (f *userModelFilter) WhereUseridNeq(vUserid string) *userModelFilter {
+	f.args = append(f.args, vUserid)
+	f.conds = append(f.conds, "` + "`" + `userid` + "`" + ` <> ?")
+	return f
+}
+
+func (f *userModelFilter) WhereUseridLt(vUserid string) *userModelFilter {
+	f.args = append(f.args, vUserid)
+	f.conds = append(f.conds, "` + "`" + `userid` + "`" + ` < ?")
+	return f
+}
+
+func (f *userModelFilter) WhereUseridLeq(vUserid string) *userModelFilter {
+	f.args = append(f.args, vUserid)
+	f.conds = append(f.conds, "` + "`" + `userid` + "`" + ` <= ?")
+	return f
+}
+
+func (f *userModelFilter) WhereUseridGt(vUserid string) *userModelFilter {
+	f.args = append(f.args, vUserid)
+	f.conds = append(f.conds, "` + "`" + `userid` + "`" + ` > ?")
+	return f
+}
+
+func (f *userModelFilter) WhereUseridGeq(vUserid string) *userModelFilter {
+	f.args = append(f.args, vUserid)
+	f.conds = append(f.conds, "` + "`" + `userid` + "`" + ` >= ?")
+	return f
+}
+
+func (f *userModelFilter) WhereUseridIn(vUserids []string) *userModelFilter {
+	if len(vUserids) == 0 {
+		// an empty IN list is invalid sql, and matches no rows
+		f.conds = append(f.conds, "1 = 0")
+		return f
+	}
+	placeholders := make([]string, 0, len(vUserids))
+	for _, i := range vUserids {
+		f.args = append(f.args, i)
+		placeholders = append(placeholders, "?")
+	}
+	f.conds = append(f.conds, "` + "`" + `userid` + "`" + ` IN ("+strings.Join(placeholders, ", ")+")")
+	return f
+}
+
+// WhereUseridLike matches a raw LIKE pattern with an ESCAPE of
+// sqldb.LikeEscape, and text of the pattern which is matched literally may be
+// escaped by sqldb.EscapeLike
+func (f *userModelFilter) WhereUseridLike(vUseridPattern string) *userModelFilter {
+	f.args = append(f.args, vUseridPattern)
+	f.conds = append(f.conds, "` + "`" + `userid` + "`" + ` LIKE ? ESCAPE '!'")
+	return f
+}
+
+func (f *userModelFilter) WhereUseridIsNull() *userModelFilter {
+	f.conds = append(f.conds, "` + "`" + `userid` + "`" + ` IS NULL")
+	return f
+}
+
+func (f *userModelFilter) WhereUseridNotNull() *userModelFilter {
+	f.conds = append(f.conds, "` + "`" + `userid` + "`" + ` IS NOT NULL")
+	return f
+}
+
+func (f *userModelFilter) OrderUserid(desc bool) *userModelFilter {
+	if desc {
+		f.order = append(f.order, "` + "`" + `userid` + "`" + ` DESC")
+	} else {
+		f.order = append(f.order, "` + "`" + `userid` + "`" + `")
+	}
+	return f
+}
+
+func (f *userModelFilter) WhereUsernameEq(vUsername string) *userModelFilter {
+	f.args = append(f.args, vUsername)
+	f.conds = append(f.conds, "` + "`" + `username` + "`" + ` = ?")
+	return f
+}
+
+func (f *userModelFilter) WhereUsernameNeq(vUsername string) *userModelFilter {
+	f.args = append(f.args, vUsername)
+	f.conds = append(f.conds, "` + "`" + `username` + "`" + ` <> ?")
+	return f
+}
+
+func (f *userModelFilter) WhereUsernameLt(vUsername string) *userModelFilter {
+	f.args = append(f.args, vUsername)
+	f.conds = append(f.conds, "` + "`" + `username` + "`" + ` < ?")
+	return f
+}
+
+func (f *userModelFilter) WhereUsernameLeq(vUsername string) *userModelFilter {
+	f.args = append(f.args, vUsername)
+	f.conds = append(f.conds, "` + "`" + `username` + "`" + ` <= ?")
+	return f
+}
+
+func (f *userModelFilter) WhereUsernameGt(vUsername string) *userModelFilter {
+	f.args = append(f.args, vUsername)
+	f.conds = append(f.conds, "` + "`" + `username` + "`" + ` > ?")
+	return f
+}
+
+func (f *userModelFilter) WhereUsernameGeq(vUsername string) *userModelFilter {
+	f.args = append(f.args, vUsername)
+	f.conds = append(f.conds, "` + "`" + `username` + "`" + ` >= ?")
+	return f
+}
+
+func (f *userModelFilter) WhereUsernameIn(vUsernames []string) *userModelFilter {
+	if len(vUsernames) == 0 {
+		// an empty IN list is invalid sql, and matches no rows
+		f.conds = append(f.conds, "1 = 0")
+		return f
+	}
+	placeholders := make([]string, 0, len(vUsernames))
+	for _, i := range vUsernames {
+		f.args = append(f.args, i)
+		placeholders = append(placeholders, "?")
+	}
+	f.conds = append(f.conds, "` + "`" + `username` + "`" + ` IN ("+strings.Join(placeholders, ", ")+")")
+	return f
+}
+
+// WhereUsernameLike matches a raw LIKE pattern with an ESCAPE of
+// sqldb.LikeEscape, and text of the pattern which is matched literally may be
+// escaped by sqldb.EscapeLike
+func (f *userModelFilter) WhereUsernameLike(vUsernamePattern string) *userModelFilter {
+	f.args = append(f.args, vUsernamePattern)
+	f.conds = append(f.conds, "` + "`" + `username` + "`" + ` LIKE ? ESCAPE '!'")
+	return f
+}
+
+func (f *userModelFilter) WhereUsernameIsNull() *userModelFilter {
+	f.conds = append(f.conds, "` + "`" + `username` + "`" + ` IS NULL")
+	return f
+}
+
+func (f *userModelFilter) WhereUsernameNotNull() *userModelFilter {
+	f.conds = append(f.conds, "` + "`" + `username` + "`" + ` IS NOT NULL")
+	return f
+}
+
+func (f *userModelFilter) OrderUsername(desc bool) *userModelFilter {
+	if desc {
+		f.order = append(f.order, "` + "`" + `username` + "`" + ` DESC")
+	} else {
+		f.order = append(f.order, "` + "`" + `username` + "`" + `")
+	}
+	return f
+}
+
+func (f *userModelFilter) WhereDeletedAtEq(vDeletedAt *time.Time) *userModelFilter {
+	f.args = append(f.args, vDeletedAt)
+	f.conds = append(f.conds, "` + "`" + `deleted_at` + "`" + ` = ?")
+	return f
+}
+
+func (f *userModelFilter) WhereDeletedAtNeq(vDeletedAt *time.Time) *userModelFilter {
+	f.args = append(f.args, vDeletedAt)
+	f.conds = append(f.conds, "` + "`" + `deleted_at` + "`" + ` <> ?")
+	return f
+}
+
+func (f *userModelFilter) WhereDeletedAtLt(vDeletedAt *time.Time) *userModelFilter {
+	f.args = append(f.args, vDeletedAt)
+	f.conds = append(f.conds, "` + "`" + `deleted_at` + "`" + ` < ?")
+	return f
+}
+
+func (f *userModelFilter) WhereDeletedAtLeq(vDeletedAt *time.Time) *userModelFilter {
+	f.args = append(f.args, vDeletedAt)
+	f.conds = append(f.conds, "` + "`" + `deleted_at` + "`" + ` <= ?")
+	return f
+}
+
+func (f *userModelFilter) WhereDeletedAtGt(vDeletedAt *time.Time) *userModelFilter {
+	f.args = append(f.args, vDeletedAt)
+	f.conds = append(f.conds, "` + "`" + `deleted_at` + "`" + ` > ?")
+	return f
+}
+
+func (f *userModelFilter) WhereDeletedAtGeq(vDeletedAt *time.Time) *userModelFilter {
+	f.args = append(f.args, vDeletedAt)
+	f.conds = append(f.conds, "` + "`" + `deleted_at` + "`" + ` >= ?")
+	return f
+}
+
+func (f *userModelFilter) WhereDeletedAtIn(vDeletedAts []*time.Time) *userModelFilter {
+	if len(vDeletedAts) == 0 {
+		// an empty IN list is invalid sql, and matches no rows
+		f.conds = append(f.conds, "1 = 0")
+		return f
+	}
+	placeholders := make([]string, 0, len(vDeletedAts))
+	for _, i := range vDeletedAts {
+		f.args = append(f.args, i)
+		placeholders = append(placeholders, "?")
+	}
+	f.conds = append(f.conds, "` + "`" + `deleted_at` + "`" + ` IN ("+strings.Join(placeholders, ", ")+")")
+	return f
+}
+
+func (f *userModelFilter) WhereDeletedAtIsNull() *userModelFilter {
+	f.conds = append(f.conds, "` + "`" + `deleted_at` + "`" + ` IS NULL")
+	return f
+}
+
+func (f *userModelFilter) WhereDeletedAtNotNull() *userModelFilter {
+	f.conds = append(f.conds, "` + "`" + `deleted_at` + "`" + ` IS NOT NULL")
+	return f
+}
+
+func (f *userModelFilter) OrderDeletedAt(desc bool) *userModelFilter {
+	if desc {
+		f.order = append(f.order, "` + "`" + `deleted_at` + "`" + ` DESC")
+	} else {
+		f.order = append(f.order, "` + "`" + `deleted_at` + "`" + `")
+	}
+	return f
+}
+
+// IncludingDeleted includes soft deleted rows in the query
+func (f *userModelFilter) IncludingDeleted() *userModelFilter {
+	f.includingDeleted = true
+	return f
+}
+
+// Limit limits the number of rows of the query, and is not applied if zero or
+// negative
+func (f *userModelFilter) Limit(limit int) *userModelFilter {
+	if limit < 0 {
+		limit = 0
+	}
+	f.limit = limit
+	return f
+}
+
+// Offset skips rows of the query, and is only applied with a limit
+func (f *userModelFilter) Offset(offset int) *userModelFilter {
+	f.offset = offset
+	return f
+}
+
+func (f *userModelFilter) query(cols string) (string, []interface{}) {
+	t := f.t
+	query := "SELECT " + cols + " FROM " + "` + "`" + `" + t.TableName + "` + "`" + `"
+	conds := f.conds
+	if !f.includingDeleted {
+		conds = append([]string{"` + "`" + `deleted_at` + "`" + ` IS NULL"}, conds...)
+	}
+	if len(conds) != 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	if len(f.order) != 0 {
+		query += " ORDER BY " + strings.Join(f.order, ", ")
+	}
+	args := make([]interface{}, 0, len(f.args)+2)
+	args = append(args, f.args...)
+	if f.limit > 0 {
+		args = append(args, f.limit)
+		if f.offset > 0 {
+			args = append(args, f.offset)
+			query += " LIMIT ? OFFSET ?"
+		} else {
+			query += " LIMIT ?"
+		}
+	}
+	return query + ";", args
+}
+
+func (f *userModelFilter) GetModel(ctx context.Context, d sqldb.Executor) (_ []Model, retErr error) {
+	query, args := f.query("` + "`" + `userid` + "`" + `, ` + "`" + `username` + "`" + `, ` + "`" + `deleted_at` + "`" + `")
+	res := make([]Model, 0, f.limit)
+	rows, err := d.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Model
+		if err := rows.Scan(&m.Userid, &m.Username, &m.DeletedAt); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+type (
+	postModelTable struct {
+		TableName string
+		// Clock returns the current time, and is time.Now if nil
+		Clock func() time.Time
+	}
+)
+
+func (t *postModelTable) now() time.Time {
+	if t.Clock != nil {
+		return t.Clock()
+	}
+	return time.Now()
+}
+
+func (t *postModelTable) Setup(ctx context.Context, d sqldb.Executor) error {
+	_, err := d.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `postid` + "`" + ` VARCHAR(31) PRIMARY KEY, ` + "`" + `userid` + "`" + ` VARCHAR(31) NOT NULL, ` + "`" + `deleted` + "`" + ` BIGINT);")
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *postModelTable) Insert(ctx context.Context, d sqldb.Executor, m *Post) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `postid` + "`" + `, ` + "`" + `userid` + "`" + `, ` + "`" + `deleted` + "`" + `) VALUES (?, ?, ?);", m.Postid, m.Userid, m.Deleted)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *postModelTable) InsertBulk(ctx context.Context, d sqldb.Executor, models []*Post, allowConflict bool) error {
+	conflictSQL := ""
+	if allowConflict {
+		conflictSQL = " ON DUPLICATE KEY UPDATE ` + "`" + `postid` + "`" + ` = ` + "`" + `postid` + "`" + `"
+	}
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*3)
+	for _, m := range models {
+		placeholders = append(placeholders, "(?, ?, ?)")
+		args = append(args, m.Postid, m.Userid, m.Deleted)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `postid` + "`" + `, ` + "`" + `userid` + "`" + `, ` + "`" + `deleted` + "`" + `) VALUES "+strings.Join(placeholders, ", ")+conflictSQL+";", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *postModelTable) GetPostByUserid(ctx context.Context, d sqldb.Executor, userid string, limit, offset int) (_ []Post, retErr error) {
+	res := make([]Post, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT ` + "`" + `postid` + "`" + `, ` + "`" + `userid` + "`" + `, ` + "`" + `deleted` + "`" + ` FROM ` + "`" + `"+t.TableName+"` + "`" + ` WHERE ` + "`" + `userid` + "`" + ` = ? AND ` + "`" + `deleted` + "`" + ` IS NULL ORDER BY ` + "`" + `postid` + "`" + ` LIMIT ? OFFSET ?;", userid, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Post
+		if err := rows.Scan(&m.Postid, &m.Userid, &m.Deleted); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *postModelTable) GetPostByUseridIncludingDeleted(ctx context.Context, d sqldb.Executor, userid string, limit, offset int) (_ []Post, retErr error) {
+	res := make([]Post, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT ` + "`" + `postid` + "`" + `, ` + "`" + `userid` + "`" + `, ` + "`" + `deleted` + "`" + ` FROM ` + "`" + `"+t.TableName+"` + "`" + ` WHERE ` + "`" + `userid` + "`" + ` = ? ORDER BY ` + "`" + `postid` + "`" + ` LIMIT ? OFFSET ?;", userid, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m Post
+		if err := rows.Scan(&m.Postid, &m.Userid, &m.Deleted); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *postModelTable) DelByUserid(ctx context.Context, d sqldb.Executor, userid string) (int64, error) {
+	res, err := d.ExecContext(ctx, "UPDATE ` + "`" + `"+t.TableName+"` + "`" + ` SET ` + "`" + `deleted` + "`" + ` = ? WHERE ` + "`" + `userid` + "`" + ` = ? AND ` + "`" + `deleted` + "`" + ` IS NULL;", t.now().Unix(), userid)
+	if err != nil {
+		return 0, err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (t *postModelTable) HardDelByUserid(ctx context.Context, d sqldb.Executor, userid string) (int64, error) {
+	res, err := d.ExecContext(ctx, "DELETE FROM ` + "`" + `"+t.TableName+"` + "`" + ` WHERE ` + "`" + `userid` + "`" + ` = ?;", userid)
+	if err != nil {
+		return 0, err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+type (
+	// userPostModelTable queries the joined tables of userPost
+	userPostModelTable struct {
+		PostTableName string
+		UserTableName string
+	}
+)
+
+func (t *userPostModelTable) GetUserPostByUserid(ctx context.Context, d sqldb.Executor, userid string, limit, offset int) (_ []UserPost, retErr error) {
+	res := make([]UserPost, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT ` + "`" + `t1` + "`" + `.` + "`" + `postid` + "`" + `, ` + "`" + `t2` + "`" + `.` + "`" + `username` + "`" + ` FROM ` + "`" + `"+t.PostTableName+"` + "`" + ` ` + "`" + `t1` + "`" + ` INNER JOIN ` + "`" + `"+t.UserTableName+"` + "`" + ` ` + "`" + `t2` + "`" + ` ON ` + "`" + `t2` + "`" + `.` + "`" + `userid` + "`" + ` = ` + "`" + `t1` + "`" + `.` + "`" + `userid` + "`" + ` AND ` + "`" + `t2` + "`" + `.` + "`" + `deleted_at` + "`" + ` IS NULL WHERE ` + "`" + `t1` + "`" + `.` + "`" + `userid` + "`" + ` = ? AND ` + "`" + `t1` + "`" + `.` + "`" + `deleted` + "`" + ` IS NULL ORDER BY ` + "`" + `t1` + "`" + `.` + "`" + `postid` + "`" + ` LIMIT ? OFFSET ?;", userid, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m UserPost
+		if err := rows.Scan(&m.Postid, &m.Username); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *userPostModelTable) GetUserPostByUseridIncludingDeleted(ctx context.Context, d sqldb.Executor, userid string, limit, offset int) (_ []UserPost, retErr error) {
+	res := make([]UserPost, 0, limit)
+	rows, err := d.QueryContext(ctx, "SELECT ` + "`" + `t1` + "`" + `.` + "`" + `postid` + "`" + `, ` + "`" + `t2` + "`" + `.` + "`" + `username` + "`" + ` FROM ` + "`" + `"+t.PostTableName+"` + "`" + ` ` + "`" + `t1` + "`" + ` INNER JOIN ` + "`" + `"+t.UserTableName+"` + "`" + ` ` + "`" + `t2` + "`" + ` ON ` + "`" + `t2` + "`" + `.` + "`" + `userid` + "`" + ` = ` + "`" + `t1` + "`" + `.` + "`" + `userid` + "`" + ` WHERE ` + "`" + `t1` + "`" + `.` + "`" + `userid` + "`" + ` = ? ORDER BY ` + "`" + `t1` + "`" + `.` + "`" + `postid` + "`" + ` LIMIT ? OFFSET ?;", userid, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("Failed to close db rows: %w", err))
+		}
+	}()
+	for rows.Next() {
+		var m UserPost
+		if err := rows.Scan(&m.Postid, &m.Username); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+`,
+			},
+		},
+		{
+			Name: "generates versioned update queries",
+			Fsys: fstest.MapFS{
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "model": {
+        "version": "version"
+      },
+      "queries": {
+        "Model": [
+          {
+            "kind": "updeq",
+            "name": "ByID",
+            "conditions": [
+              {"col": "userid"}
+            ]
+          },
+          {
+            "kind": "upsert",
+            "name": "Ignore",
+            "conflict": ["userid"],
+            "update": []
+          }
+        ],
+        "Info": [
+          {
+            "kind": "updeq",
+            "name": "ByIDs",
+            "conditions": [
+              {"col": "userid", "cond": "in"}
+            ]
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL UNIQUE"` + "`" + `
+		Version  int64  ` + "`" + `model:"version,BIGINT NOT NULL"` + "`" + `
+	}
+
+	//forge:model:query user
+	Info struct {
+		Username string ` + "`" + `model:"username"` + "`" + `
+		Version  int64  ` + "`" + `model:"version"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Output: map[string]string{
+				"model_gen.go": `// Code generated by go generate forge model dev; DO NOT EDIT.
+
+package somepackage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"xorkevin.dev/forge/model/sqldb"
+)
+
+type (
+	userModelTable struct {
+		TableName string
+	}
+)
+
+func (t *userModelTable) Setup(ctx context.Context, d sqldb.Executor) error {
+	_, err := d.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+t.TableName+" (userid VARCHAR(31) PRIMARY KEY, username VARCHAR(255) NOT NULL UNIQUE, version BIGINT NOT NULL);")
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) Insert(ctx context.Context, d sqldb.Executor, m *Model) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (userid, username, version) VALUES ($1, $2, $3);", m.Userid, m.Username, m.Version)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) InsertBulk(ctx context.Context, d sqldb.Executor, models []*Model, allowConflict bool) error {
+	conflictSQL := ""
+	if allowConflict {
+		conflictSQL = " ON CONFLICT DO NOTHING"
+	}
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*3)
+	for c, m := range models {
+		n := c * 3
+		placeholders = append(placeholders, fmt.Sprintf("($%d, $%d, $%d)", n+1, n+2, n+3))
+		args = append(args, m.Userid, m.Username, m.Version)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (userid, username, version) VALUES "+strings.Join(placeholders, ", ")+conflictSQL+";", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) UpdModelByID(ctx context.Context, d sqldb.Executor, m *Model, userid string) error {
+	res, err := d.ExecContext(ctx, "UPDATE "+t.TableName+" SET (userid, username, version) = ($1, $2, version + 1) WHERE version = $3 AND userid = $4;", m.Userid, m.Username, m.Version, userid)
+	if err != nil {
+		return err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return sqldb.ErrConflict
+	}
+	m.Version++
+	return nil
+}
+
+func (t *userModelTable) UpsertModelIgnore(ctx context.Context, d sqldb.Executor, m *Model) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (userid, username, version) VALUES ($1, $2, $3) ON CONFLICT (userid) DO NOTHING;", m.Userid, m.Username, m.Version)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) UpsertModelIgnoreBulk(ctx context.Context, d sqldb.Executor, models []*Model) error {
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*3)
+	for c, m := range models {
+		n := c * 3
+		placeholders = append(placeholders, fmt.Sprintf("($%d, $%d, $%d)", n+1, n+2, n+3))
+		args = append(args, m.Userid, m.Username, m.Version)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (userid, username, version) VALUES "+strings.Join(placeholders, ", ")+" ON CONFLICT (userid) DO NOTHING;", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) UpdInfoByIDs(ctx context.Context, d sqldb.Executor, m *Info, userids []string) error {
+	paramCount := 2
+	args := make([]interface{}, 0, paramCount+len(userids))
+	args = append(args, m.Username, m.Version)
+	var placeholdersuserids string
+	{
+		placeholders := make([]string, 0, len(userids))
+		for _, i := range userids {
+			paramCount++
+			placeholders = append(placeholders, fmt.Sprintf("($%d)", paramCount))
+			args = append(args, i)
+		}
+		placeholdersuserids = strings.Join(placeholders, ", ")
+	}
+	res, err := d.ExecContext(ctx, "UPDATE "+t.TableName+" SET (username, version) = ($1, version + 1) WHERE version = $2 AND userid IN (VALUES "+placeholdersuserids+");", args...)
+	if err != nil {
+		return err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return sqldb.ErrConflict
+	}
+	m.Version++
+	return nil
+}
+`,
+			},
+		},
+		{
+			Name:    "generates mysql versioned update queries",
+			Dialect: DialectMySQL{},
+			Fsys: fstest.MapFS{
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "model": {
+        "version": "version"
+      },
+      "queries": {
+        "Model": [
+          {
+            "kind": "updeq",
+            "name": "ByID",
+            "conditions": [
+              {"col": "userid"}
+            ]
+          },
+          {
+            "kind": "upsert",
+            "name": "Ignore",
+            "conflict": ["userid"],
+            "update": []
+          }
+        ],
+        "Info": [
+          {
+            "kind": "updeq",
+            "name": "ByIDs",
+            "conditions": [
+              {"col": "userid", "cond": "in"}
+            ]
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL UNIQUE"` + "`" + `
+		Version  int64  ` + "`" + `model:"version,BIGINT NOT NULL"` + "`" + `
+	}
+
+	//forge:model:query user
+	Info struct {
+		Username string ` + "`" + `model:"username"` + "`" + `
+		Version  int64  ` + "`" + `model:"version"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Output: map[string]string{
+				"model_gen.go": `// Code generated by go generate forge model dev; DO NOT EDIT.
+
+package somepackage
+
+import (
+	"context"
+	"strings"
+
+	"xorkevin.dev/forge/model/sqldb"
+)
+
+type (
+	userModelTable struct {
+		TableName string
+	}
+)
+
+func (t *userModelTable) Setup(ctx context.Context, d sqldb.Executor) error {
+	_, err := d.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `userid` + "`" + ` VARCHAR(31) PRIMARY KEY, ` + "`" + `username` + "`" + ` VARCHAR(255) NOT NULL UNIQUE, ` + "`" + `version` + "`" + ` BIGINT NOT NULL);")
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) Insert(ctx context.Context, d sqldb.Executor, m *Model) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `userid` + "`" + `, ` + "`" + `username` + "`" + `, ` + "`" + `version` + "`" + `) VALUES (?, ?, ?);", m.Userid, m.Username, m.Version)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) InsertBulk(ctx context.Context, d sqldb.Executor, models []*Model, allowConflict bool) error {
+	conflictSQL := ""
+	if allowConflict {
+		conflictSQL = " ON DUPLICATE KEY UPDATE ` + "`" + `userid` + "`" + ` = ` + "`" + `userid` + "`" + `"
+	}
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*3)
+	for _, m := range models {
+		placeholders = append(placeholders, "(?, ?, ?)")
+		args = append(args, m.Userid, m.Username, m.Version)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `userid` + "`" + `, ` + "`" + `username` + "`" + `, ` + "`" + `version` + "`" + `) VALUES "+strings.Join(placeholders, ", ")+conflictSQL+";", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) UpdModelByID(ctx context.Context, d sqldb.Executor, m *Model, userid string) error {
+	res, err := d.ExecContext(ctx, "UPDATE ` + "`" + `"+t.TableName+"` + "`" + ` SET ` + "`" + `userid` + "`" + ` = ?, ` + "`" + `username` + "`" + ` = ?, ` + "`" + `version` + "`" + ` = ` + "`" + `version` + "`" + ` + 1 WHERE ` + "`" + `version` + "`" + ` = ? AND ` + "`" + `userid` + "`" + ` = ?;", m.Userid, m.Username, m.Version, userid)
+	if err != nil {
+		return err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return sqldb.ErrConflict
+	}
+	m.Version++
+	return nil
+}
+
+func (t *userModelTable) UpsertModelIgnore(ctx context.Context, d sqldb.Executor, m *Model) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `userid` + "`" + `, ` + "`" + `username` + "`" + `, ` + "`" + `version` + "`" + `) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE ` + "`" + `userid` + "`" + ` = ` + "`" + `userid` + "`" + `;", m.Userid, m.Username, m.Version)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) UpsertModelIgnoreBulk(ctx context.Context, d sqldb.Executor, models []*Model) error {
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*3)
+	for _, m := range models {
+		placeholders = append(placeholders, "(?, ?, ?)")
+		args = append(args, m.Userid, m.Username, m.Version)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `userid` + "`" + `, ` + "`" + `username` + "`" + `, ` + "`" + `version` + "`" + `) VALUES "+strings.Join(placeholders, ", ")+" ON DUPLICATE KEY UPDATE ` + "`" + `userid` + "`" + ` = ` + "`" + `userid` + "`" + `;", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) UpdInfoByIDs(ctx context.Context, d sqldb.Executor, m *Info, userids []string) error {
+	paramCount := 2
+	args := make([]interface{}, 0, paramCount+len(userids))
+	args = append(args, m.Username, m.Version)
+	var placeholdersuserids string
+	{
+		placeholders := make([]string, 0, len(userids))
+		for _, i := range userids {
+			placeholders = append(placeholders, "?")
+			args = append(args, i)
+		}
+		placeholdersuserids = strings.Join(placeholders, ", ")
+	}
+	res, err := d.ExecContext(ctx, "UPDATE ` + "`" + `"+t.TableName+"` + "`" + ` SET ` + "`" + `username` + "`" + ` = ?, ` + "`" + `version` + "`" + ` = ` + "`" + `version` + "`" + ` + 1 WHERE ` + "`" + `version` + "`" + ` = ? AND ` + "`" + `userid` + "`" + ` IN ("+placeholdersuserids+");", args...)
+	if err != nil {
+		return err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return sqldb.ErrConflict
+	}
+	m.Version++
+	return nil
+}
+`,
+			},
+		},
+		{
+			Name: "generates auto time queries",
+			Fsys: fstest.MapFS{
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "model": {
+        "version": "version",
+        "autoTime": {
+          "insert": ["creation_time", "update_time"],
+          "update": ["update_time"]
+        }
+      },
+      "queries": {
+        "Model": [
+          {
+            "kind": "updeq",
+            "name": "ByID",
+            "conditions": [
+              {"col": "userid"}
+            ]
+          },
+          {
+            "kind": "upsert",
+            "name": "Ignore",
+            "conflict": ["userid"],
+            "update": []
+          }
+        ],
+        "Info": [
+          {
+            "kind": "getoneeq",
+            "name": "ByID",
+            "conditions": [
+              {"col": "userid"}
+            ]
+          }
+        ]
+      }
+    },
+    "event": {
+      "model": {
+        "autoTime": {
+          "insert": ["creation_time", "update_time"],
+          "update": ["update_time"],
+          "source": "sql"
+        }
+      },
+      "queries": {
+        "Event": [
+          {
+            "kind": "updeq",
+            "name": "ByID",
+            "conditions": [
+              {"col": "eventid"}
+            ]
+          },
+          {
+            "kind": "upsert",
+            "name": "ByID",
+            "conflict": ["eventid"]
+          }
+        ],
+        "EventCount": [
+          {
+            "kind": "increq",
+            "name": "ByID",
+            "conditions": [
+              {"col": "eventid"}
+            ]
+          }
+        ],
+        "EventPatch": [
+          {
+            "kind": "patcheq",
+            "name": "ByID",
+            "conditions": [
+              {"col": "eventid"}
+            ]
+          }
+        ]
+      }
+    },
+    "post": {
+      "model": {
+        "autoTime": {
+          "update": ["update_time"]
+        }
+      },
+      "queries": {
+        "PostBody": [
+          {
+            "kind": "updeq",
+            "name": "ByID",
+            "conditions": [
+              {"col": "postid"}
+            ]
+          }
+        ],
+        "PostViews": [
+          {
+            "kind": "increq",
+            "name": "ByID",
+            "conditions": [
+              {"col": "postid"}
+            ]
+          }
+        ],
+        "PostUpsert": [
+          {
+            "kind": "upsert",
+            "name": "ByID",
+            "conflict": ["postid"],
+            "update": ["body"]
+          }
+        ],
+        "PostPatch": [
+          {
+            "kind": "patcheq",
+            "name": "ByID",
+            "conditions": [
+              {"col": "postid"}
+            ]
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+import (
+	"time"
+)
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid       string    ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username     string    ` + "`" + `model:"username,VARCHAR(255) NOT NULL UNIQUE"` + "`" + `
+		CreationTime time.Time ` + "`" + `model:"creation_time,TIMESTAMP NOT NULL"` + "`" + `
+		UpdateTime   int64     ` + "`" + `model:"update_time,BIGINT NOT NULL"` + "`" + `
+		Version      int64     ` + "`" + `model:"version,BIGINT NOT NULL"` + "`" + `
+	}
+
+	//forge:model:query user
+	Info struct {
+		Username string ` + "`" + `model:"username"` + "`" + `
+	}
+
+	//forge:model event
+	//forge:model:query event
+	Event struct {
+		Eventid      string    ` + "`" + `model:"eventid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Kind         string    ` + "`" + `model:"kind,VARCHAR(255) NOT NULL"` + "`" + `
+		Count        int64     ` + "`" + `model:"count,BIGINT NOT NULL"` + "`" + `
+		CreationTime time.Time ` + "`" + `model:"creation_time,TIMESTAMP NOT NULL"` + "`" + `
+		UpdateTime   time.Time ` + "`" + `model:"update_time,TIMESTAMP NOT NULL"` + "`" + `
+	}
+
+	//forge:model:query event
+	EventCount struct {
+		Count int64 ` + "`" + `model:"count"` + "`" + `
+	}
+
+	//forge:model:query event
+	EventPatch struct {
+		Kind *string ` + "`" + `model:"kind"` + "`" + `
+	}
+
+	//forge:model post
+	Post struct {
+		Postid     string    ` + "`" + `model:"postid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Body       string    ` + "`" + `model:"body,VARCHAR(4095) NOT NULL"` + "`" + `
+		Views      int64     ` + "`" + `model:"views,BIGINT NOT NULL"` + "`" + `
+		UpdateTime time.Time ` + "`" + `model:"update_time,TIMESTAMP NOT NULL"` + "`" + `
+	}
+
+	//forge:model:query post
+	PostBody struct {
+		Body string ` + "`" + `model:"body"` + "`" + `
+	}
+
+	//forge:model:query post
+	PostViews struct {
+		Views      int64     ` + "`" + `model:"views"` + "`" + `
+		UpdateTime time.Time ` + "`" + `model:"update_time"` + "`" + `
+	}
+
+	//forge:model:query post
+	PostUpsert struct {
+		Postid string ` + "`" + `model:"postid"` + "`" + `
+		Body   string ` + "`" + `model:"body"` + "`" + `
+		Views  int64  ` + "`" + `model:"views"` + "`" + `
+	}
+
+	//forge:model:query post
+	PostPatch struct {
+		Body       *string    ` + "`" + `model:"body"` + "`" + `
+		UpdateTime *time.Time ` + "`" + `model:"update_time"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Output: map[string]string{
+				"model_gen.go": `// Code generated by go generate forge model dev; DO NOT EDIT.
+
+package somepackage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"xorkevin.dev/forge/model/sqldb"
+)
+
+type (
+	userModelTable struct {
+		TableName string
+		// Clock returns the current time, and is time.Now if nil
+		Clock func() time.Time
+	}
+)
+
+func (t *userModelTable) now() time.Time {
+	if t.Clock != nil {
+		return t.Clock()
+	}
+	return time.Now()
+}
+
+func (t *userModelTable) Setup(ctx context.Context, d sqldb.Executor) error {
+	_, err := d.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+t.TableName+" (userid VARCHAR(31) PRIMARY KEY, username VARCHAR(255) NOT NULL UNIQUE, creation_time TIMESTAMP NOT NULL, update_time BIGINT NOT NULL, version BIGINT NOT NULL);")
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) Insert(ctx context.Context, d sqldb.Executor, m *Model) error {
+	now := t.now()
+	m.CreationTime = now
+	m.UpdateTime = now.Unix()
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (userid, username, creation_time, update_time, version) VALUES ($1, $2, $3, $4, $5);", m.Userid, m.Username, m.CreationTime, m.UpdateTime, m.Version)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) InsertBulk(ctx context.Context, d sqldb.Executor, models []*Model, allowConflict bool) error {
+	conflictSQL := ""
+	if allowConflict {
+		conflictSQL = " ON CONFLICT DO NOTHING"
+	}
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*5)
+	now := t.now()
+	for c, m := range models {
+		m.CreationTime = now
+		m.UpdateTime = now.Unix()
+		n := c * 5
+		placeholders = append(placeholders, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5))
+		args = append(args, m.Userid, m.Username, m.CreationTime, m.UpdateTime, m.Version)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (userid, username, creation_time, update_time, version) VALUES "+strings.Join(placeholders, ", ")+conflictSQL+";", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) UpdModelByID(ctx context.Context, d sqldb.Executor, m *Model, userid string) error {
+	now := t.now()
+	m.UpdateTime = now.Unix()
+	res, err := d.ExecContext(ctx, "UPDATE "+t.TableName+" SET (userid, username, creation_time, update_time, version) = ($1, $2, $3, $4, version + 1) WHERE version = $5 AND userid = $6;", m.Userid, m.Username, m.CreationTime, m.UpdateTime, m.Version, userid)
+	if err != nil {
+		return err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return sqldb.ErrConflict
+	}
+	m.Version++
+	return nil
+}
+
+func (t *userModelTable) UpsertModelIgnore(ctx context.Context, d sqldb.Executor, m *Model) error {
+	now := t.now()
+	m.CreationTime = now
+	m.UpdateTime = now.Unix()
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (userid, username, creation_time, update_time, version) VALUES ($1, $2, $3, $4, $5) ON CONFLICT (userid) DO NOTHING;", m.Userid, m.Username, m.CreationTime, m.UpdateTime, m.Version)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) UpsertModelIgnoreBulk(ctx context.Context, d sqldb.Executor, models []*Model) error {
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*5)
+	now := t.now()
+	for c, m := range models {
+		m.CreationTime = now
+		m.UpdateTime = now.Unix()
+		n := c * 5
+		placeholders = append(placeholders, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5))
+		args = append(args, m.Userid, m.Username, m.CreationTime, m.UpdateTime, m.Version)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (userid, username, creation_time, update_time, version) VALUES "+strings.Join(placeholders, ", ")+" ON CONFLICT (userid) DO NOTHING;", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) GetInfoByID(ctx context.Context, d sqldb.Executor, userid string) (*Info, error) {
+	m := &Info{}
+	if err := d.QueryRowContext(ctx, "SELECT username FROM "+t.TableName+" WHERE userid = $1;", userid).Scan(&m.Username); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+type (
+	eventModelTable struct {
+		TableName string
+	}
+)
+
+func (t *eventModelTable) Setup(ctx context.Context, d sqldb.Executor) error {
+	_, err := d.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+t.TableName+" (eventid VARCHAR(31) PRIMARY KEY, kind VARCHAR(255) NOT NULL, count BIGINT NOT NULL, creation_time TIMESTAMP NOT NULL, update_time TIMESTAMP NOT NULL);")
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *eventModelTable) Insert(ctx context.Context, d sqldb.Executor, m *Event) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (eventid, kind, count, creation_time, update_time) VALUES ($1, $2, $3, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);", m.Eventid, m.Kind, m.Count)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *eventModelTable) InsertBulk(ctx context.Context, d sqldb.Executor, models []*Event, allowConflict bool) error {
+	conflictSQL := ""
+	if allowConflict {
+		conflictSQL = " ON CONFLICT DO NOTHING"
+	}
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*3)
+	for c, m := range models {
+		n := c * 3
+		placeholders = append(placeholders, fmt.Sprintf("($%d, $%d, $%d, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)", n+1, n+2, n+3))
+		args = append(args, m.Eventid, m.Kind, m.Count)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (eventid, kind, count, creation_time, update_time) VALUES "+strings.Join(placeholders, ", ")+conflictSQL+";", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *eventModelTable) UpdEventByID(ctx context.Context, d sqldb.Executor, m *Event, eventid string) error {
+	_, err := d.ExecContext(ctx, "UPDATE "+t.TableName+" SET (eventid, kind, count, creation_time, update_time) = ($1, $2, $3, $4, CURRENT_TIMESTAMP) WHERE eventid = $5;", m.Eventid, m.Kind, m.Count, m.CreationTime, eventid)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *eventModelTable) UpsertEventByID(ctx context.Context, d sqldb.Executor, m *Event) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (eventid, kind, count, creation_time, update_time) VALUES ($1, $2, $3, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP) ON CONFLICT (eventid) DO UPDATE SET kind = EXCLUDED.kind, count = EXCLUDED.count, update_time = EXCLUDED.update_time;", m.Eventid, m.Kind, m.Count)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *eventModelTable) UpsertEventByIDBulk(ctx context.Context, d sqldb.Executor, models []*Event) error {
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*3)
+	for c, m := range models {
+		n := c * 3
+		placeholders = append(placeholders, fmt.Sprintf("($%d, $%d, $%d, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)", n+1, n+2, n+3))
+		args = append(args, m.Eventid, m.Kind, m.Count)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (eventid, kind, count, creation_time, update_time) VALUES "+strings.Join(placeholders, ", ")+" ON CONFLICT (eventid) DO UPDATE SET kind = EXCLUDED.kind, count = EXCLUDED.count, update_time = EXCLUDED.update_time;", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *eventModelTable) UpdEventCountByID(ctx context.Context, d sqldb.Executor, m *EventCount, eventid string) error {
+	_, err := d.ExecContext(ctx, "UPDATE "+t.TableName+" SET (count, update_time) = (count + $1, CURRENT_TIMESTAMP) WHERE eventid = $2;", m.Count, eventid)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *eventModelTable) PatchEventPatchByID(ctx context.Context, d sqldb.Executor, m *EventPatch, eventid string) error {
+	paramCount := 1
+	args := make([]interface{}, 0, paramCount+2)
+	args = append(args, eventid)
+	set := make([]string, 0, 2)
+	if m.Kind != nil {
+		paramCount++
+		set = append(set, fmt.Sprintf("kind = $%d", paramCount))
+		args = append(args, *m.Kind)
+	}
+	set = append(set, "update_time = CURRENT_TIMESTAMP")
+	_, err := d.ExecContext(ctx, "UPDATE "+t.TableName+" SET "+strings.Join(set, ", ")+" WHERE eventid = $1;", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+type (
+	postModelTable struct {
+		TableName string
+		// Clock returns the current time, and is time.Now if nil
+		Clock func() time.Time
+	}
+)
+
+func (t *postModelTable) now() time.Time {
+	if t.Clock != nil {
+		return t.Clock()
+	}
+	return time.Now()
+}
+
+func (t *postModelTable) Setup(ctx context.Context, d sqldb.Executor) error {
+	_, err := d.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+t.TableName+" (postid VARCHAR(31) PRIMARY KEY, body VARCHAR(4095) NOT NULL, views BIGINT NOT NULL, update_time TIMESTAMP NOT NULL);")
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *postModelTable) Insert(ctx context.Context, d sqldb.Executor, m *Post) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (postid, body, views, update_time) VALUES ($1, $2, $3, $4);", m.Postid, m.Body, m.Views, m.UpdateTime)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *postModelTable) InsertBulk(ctx context.Context, d sqldb.Executor, models []*Post, allowConflict bool) error {
+	conflictSQL := ""
+	if allowConflict {
+		conflictSQL = " ON CONFLICT DO NOTHING"
+	}
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*4)
+	for c, m := range models {
+		n := c * 4
+		placeholders = append(placeholders, fmt.Sprintf("($%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4))
+		args = append(args, m.Postid, m.Body, m.Views, m.UpdateTime)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (postid, body, views, update_time) VALUES "+strings.Join(placeholders, ", ")+conflictSQL+";", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *postModelTable) UpdPostBodyByID(ctx context.Context, d sqldb.Executor, m *PostBody, postid string) error {
+	now := t.now()
+	_, err := d.ExecContext(ctx, "UPDATE "+t.TableName+" SET (body, update_time) = ($1, $2) WHERE postid = $3;", m.Body, now, postid)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *postModelTable) UpdPostViewsByID(ctx context.Context, d sqldb.Executor, m *PostViews, postid string) error {
+	now := t.now()
+	m.UpdateTime = now
+	_, err := d.ExecContext(ctx, "UPDATE "+t.TableName+" SET (views, update_time) = (views + $1, $2) WHERE postid = $3;", m.Views, m.UpdateTime, postid)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *postModelTable) UpsertPostUpsertByID(ctx context.Context, d sqldb.Executor, m *PostUpsert) error {
+	now := t.now()
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (postid, body, views, update_time) VALUES ($1, $2, $3, $4) ON CONFLICT (postid) DO UPDATE SET body = EXCLUDED.body, update_time = EXCLUDED.update_time;", m.Postid, m.Body, m.Views, now)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *postModelTable) UpsertPostUpsertByIDBulk(ctx context.Context, d sqldb.Executor, models []*PostUpsert) error {
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*4)
+	now := t.now()
+	for c, m := range models {
+		n := c * 4
+		placeholders = append(placeholders, fmt.Sprintf("($%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4))
+		args = append(args, m.Postid, m.Body, m.Views, now)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO "+t.TableName+" (postid, body, views, update_time) VALUES "+strings.Join(placeholders, ", ")+" ON CONFLICT (postid) DO UPDATE SET body = EXCLUDED.body, update_time = EXCLUDED.update_time;", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *postModelTable) PatchPostPatchByID(ctx context.Context, d sqldb.Executor, m *PostPatch, postid string) error {
+	now := t.now()
+	paramCount := 1
+	args := make([]interface{}, 0, paramCount+2)
+	args = append(args, postid)
+	set := make([]string, 0, 2)
+	if m.Body != nil {
+		paramCount++
+		set = append(set, fmt.Sprintf("body = $%d", paramCount))
+		args = append(args, *m.Body)
+	}
+	paramCount++
+	set = append(set, fmt.Sprintf("update_time = $%d", paramCount))
+	args = append(args, now)
+	_, err := d.ExecContext(ctx, "UPDATE "+t.TableName+" SET "+strings.Join(set, ", ")+" WHERE postid = $1;", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+`,
+			},
+		},
+		{
+			Name:    "generates mysql auto time queries",
+			Dialect: DialectMySQL{},
+			Fsys: fstest.MapFS{
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "model": {
+        "version": "version",
+        "autoTime": {
+          "insert": ["creation_time", "update_time"],
+          "update": ["update_time"]
+        }
+      },
+      "queries": {
+        "Model": [
+          {
+            "kind": "updeq",
+            "name": "ByID",
+            "conditions": [
+              {"col": "userid"}
+            ]
+          },
+          {
+            "kind": "upsert",
+            "name": "Ignore",
+            "conflict": ["userid"],
+            "update": []
+          }
+        ],
+        "Info": [
+          {
+            "kind": "getoneeq",
+            "name": "ByID",
+            "conditions": [
+              {"col": "userid"}
+            ]
+          }
+        ]
+      }
+    },
+    "event": {
+      "model": {
+        "autoTime": {
+          "insert": ["creation_time", "update_time"],
+          "update": ["update_time"],
+          "source": "sql"
+        }
+      },
+      "queries": {
+        "Event": [
+          {
+            "kind": "updeq",
+            "name": "ByID",
+            "conditions": [
+              {"col": "eventid"}
+            ]
+          },
+          {
+            "kind": "upsert",
+            "name": "ByID",
+            "conflict": ["eventid"]
+          }
+        ],
+        "EventCount": [
+          {
+            "kind": "increq",
+            "name": "ByID",
+            "conditions": [
+              {"col": "eventid"}
+            ]
+          }
+        ],
+        "EventPatch": [
+          {
+            "kind": "patcheq",
+            "name": "ByID",
+            "conditions": [
+              {"col": "eventid"}
+            ]
+          }
+        ]
+      }
+    },
+    "post": {
+      "model": {
+        "autoTime": {
+          "update": ["update_time"]
+        }
+      },
+      "queries": {
+        "PostBody": [
+          {
+            "kind": "updeq",
+            "name": "ByID",
+            "conditions": [
+              {"col": "postid"}
+            ]
+          }
+        ],
+        "PostViews": [
+          {
+            "kind": "increq",
+            "name": "ByID",
+            "conditions": [
+              {"col": "postid"}
+            ]
+          }
+        ],
+        "PostUpsert": [
+          {
+            "kind": "upsert",
+            "name": "ByID",
+            "conflict": ["postid"],
+            "update": ["body"]
+          }
+        ],
+        "PostPatch": [
+          {
+            "kind": "patcheq",
+            "name": "ByID",
+            "conditions": [
+              {"col": "postid"}
+            ]
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+import (
+	"time"
+)
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid       string    ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username     string    ` + "`" + `model:"username,VARCHAR(255) NOT NULL UNIQUE"` + "`" + `
+		CreationTime time.Time ` + "`" + `model:"creation_time,TIMESTAMP NOT NULL"` + "`" + `
+		UpdateTime   int64     ` + "`" + `model:"update_time,BIGINT NOT NULL"` + "`" + `
+		Version      int64     ` + "`" + `model:"version,BIGINT NOT NULL"` + "`" + `
+	}
+
+	//forge:model:query user
+	Info struct {
+		Username string ` + "`" + `model:"username"` + "`" + `
+	}
+
+	//forge:model event
+	//forge:model:query event
+	Event struct {
+		Eventid      string    ` + "`" + `model:"eventid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Kind         string    ` + "`" + `model:"kind,VARCHAR(255) NOT NULL"` + "`" + `
+		Count        int64     ` + "`" + `model:"count,BIGINT NOT NULL"` + "`" + `
+		CreationTime time.Time ` + "`" + `model:"creation_time,TIMESTAMP NOT NULL"` + "`" + `
+		UpdateTime   time.Time ` + "`" + `model:"update_time,TIMESTAMP NOT NULL"` + "`" + `
+	}
+
+	//forge:model:query event
+	EventCount struct {
+		Count int64 ` + "`" + `model:"count"` + "`" + `
+	}
+
+	//forge:model:query event
+	EventPatch struct {
+		Kind *string ` + "`" + `model:"kind"` + "`" + `
+	}
+
+	//forge:model post
+	Post struct {
+		Postid     string    ` + "`" + `model:"postid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Body       string    ` + "`" + `model:"body,VARCHAR(4095) NOT NULL"` + "`" + `
+		Views      int64     ` + "`" + `model:"views,BIGINT NOT NULL"` + "`" + `
+		UpdateTime time.Time ` + "`" + `model:"update_time,TIMESTAMP NOT NULL"` + "`" + `
+	}
+
+	//forge:model:query post
+	PostBody struct {
+		Body string ` + "`" + `model:"body"` + "`" + `
+	}
+
+	//forge:model:query post
+	PostViews struct {
+		Views      int64     ` + "`" + `model:"views"` + "`" + `
+		UpdateTime time.Time ` + "`" + `model:"update_time"` + "`" + `
+	}
+
+	//forge:model:query post
+	PostUpsert struct {
+		Postid string ` + "`" + `model:"postid"` + "`" + `
+		Body   string ` + "`" + `model:"body"` + "`" + `
+		Views  int64  ` + "`" + `model:"views"` + "`" + `
+	}
+
+	//forge:model:query post
+	PostPatch struct {
+		Body       *string    ` + "`" + `model:"body"` + "`" + `
+		UpdateTime *time.Time ` + "`" + `model:"update_time"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Output: map[string]string{
+				"model_gen.go": `// Code generated by go generate forge model dev; DO NOT EDIT.
+
+package somepackage
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"xorkevin.dev/forge/model/sqldb"
+)
+
+type (
+	userModelTable struct {
+		TableName string
+		// Clock returns the current time, and is time.Now if nil
+		Clock func() time.Time
+	}
+)
+
+func (t *userModelTable) now() time.Time {
+	if t.Clock != nil {
+		return t.Clock()
+	}
+	return time.Now()
+}
+
+func (t *userModelTable) Setup(ctx context.Context, d sqldb.Executor) error {
+	_, err := d.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `userid` + "`" + ` VARCHAR(31) PRIMARY KEY, ` + "`" + `username` + "`" + ` VARCHAR(255) NOT NULL UNIQUE, ` + "`" + `creation_time` + "`" + ` TIMESTAMP NOT NULL, ` + "`" + `update_time` + "`" + ` BIGINT NOT NULL, ` + "`" + `version` + "`" + ` BIGINT NOT NULL);")
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) Insert(ctx context.Context, d sqldb.Executor, m *Model) error {
+	now := t.now()
+	m.CreationTime = now
+	m.UpdateTime = now.Unix()
+	_, err := d.ExecContext(ctx, "INSERT INTO ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `userid` + "`" + `, ` + "`" + `username` + "`" + `, ` + "`" + `creation_time` + "`" + `, ` + "`" + `update_time` + "`" + `, ` + "`" + `version` + "`" + `) VALUES (?, ?, ?, ?, ?);", m.Userid, m.Username, m.CreationTime, m.UpdateTime, m.Version)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) InsertBulk(ctx context.Context, d sqldb.Executor, models []*Model, allowConflict bool) error {
+	conflictSQL := ""
+	if allowConflict {
+		conflictSQL = " ON DUPLICATE KEY UPDATE ` + "`" + `userid` + "`" + ` = ` + "`" + `userid` + "`" + `"
+	}
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*5)
+	now := t.now()
+	for _, m := range models {
+		m.CreationTime = now
+		m.UpdateTime = now.Unix()
+		placeholders = append(placeholders, "(?, ?, ?, ?, ?)")
+		args = append(args, m.Userid, m.Username, m.CreationTime, m.UpdateTime, m.Version)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `userid` + "`" + `, ` + "`" + `username` + "`" + `, ` + "`" + `creation_time` + "`" + `, ` + "`" + `update_time` + "`" + `, ` + "`" + `version` + "`" + `) VALUES "+strings.Join(placeholders, ", ")+conflictSQL+";", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) UpdModelByID(ctx context.Context, d sqldb.Executor, m *Model, userid string) error {
+	now := t.now()
+	m.UpdateTime = now.Unix()
+	res, err := d.ExecContext(ctx, "UPDATE ` + "`" + `"+t.TableName+"` + "`" + ` SET ` + "`" + `userid` + "`" + ` = ?, ` + "`" + `username` + "`" + ` = ?, ` + "`" + `creation_time` + "`" + ` = ?, ` + "`" + `update_time` + "`" + ` = ?, ` + "`" + `version` + "`" + ` = ` + "`" + `version` + "`" + ` + 1 WHERE ` + "`" + `version` + "`" + ` = ? AND ` + "`" + `userid` + "`" + ` = ?;", m.Userid, m.Username, m.CreationTime, m.UpdateTime, m.Version, userid)
+	if err != nil {
+		return err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return sqldb.ErrConflict
+	}
+	m.Version++
+	return nil
+}
+
+func (t *userModelTable) UpsertModelIgnore(ctx context.Context, d sqldb.Executor, m *Model) error {
+	now := t.now()
+	m.CreationTime = now
+	m.UpdateTime = now.Unix()
+	_, err := d.ExecContext(ctx, "INSERT INTO ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `userid` + "`" + `, ` + "`" + `username` + "`" + `, ` + "`" + `creation_time` + "`" + `, ` + "`" + `update_time` + "`" + `, ` + "`" + `version` + "`" + `) VALUES (?, ?, ?, ?, ?) ON DUPLICATE KEY UPDATE ` + "`" + `userid` + "`" + ` = ` + "`" + `userid` + "`" + `;", m.Userid, m.Username, m.CreationTime, m.UpdateTime, m.Version)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) UpsertModelIgnoreBulk(ctx context.Context, d sqldb.Executor, models []*Model) error {
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*5)
+	now := t.now()
+	for _, m := range models {
+		m.CreationTime = now
+		m.UpdateTime = now.Unix()
+		placeholders = append(placeholders, "(?, ?, ?, ?, ?)")
+		args = append(args, m.Userid, m.Username, m.CreationTime, m.UpdateTime, m.Version)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `userid` + "`" + `, ` + "`" + `username` + "`" + `, ` + "`" + `creation_time` + "`" + `, ` + "`" + `update_time` + "`" + `, ` + "`" + `version` + "`" + `) VALUES "+strings.Join(placeholders, ", ")+" ON DUPLICATE KEY UPDATE ` + "`" + `userid` + "`" + ` = ` + "`" + `userid` + "`" + `;", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *userModelTable) GetInfoByID(ctx context.Context, d sqldb.Executor, userid string) (*Info, error) {
+	m := &Info{}
+	if err := d.QueryRowContext(ctx, "SELECT ` + "`" + `username` + "`" + ` FROM ` + "`" + `"+t.TableName+"` + "`" + ` WHERE ` + "`" + `userid` + "`" + ` = ?;", userid).Scan(&m.Username); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+type (
+	eventModelTable struct {
+		TableName string
+	}
+)
+
+func (t *eventModelTable) Setup(ctx context.Context, d sqldb.Executor) error {
+	_, err := d.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `eventid` + "`" + ` VARCHAR(31) PRIMARY KEY, ` + "`" + `kind` + "`" + ` VARCHAR(255) NOT NULL, ` + "`" + `count` + "`" + ` BIGINT NOT NULL, ` + "`" + `creation_time` + "`" + ` TIMESTAMP NOT NULL, ` + "`" + `update_time` + "`" + ` TIMESTAMP NOT NULL);")
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *eventModelTable) Insert(ctx context.Context, d sqldb.Executor, m *Event) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `eventid` + "`" + `, ` + "`" + `kind` + "`" + `, ` + "`" + `count` + "`" + `, ` + "`" + `creation_time` + "`" + `, ` + "`" + `update_time` + "`" + `) VALUES (?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);", m.Eventid, m.Kind, m.Count)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *eventModelTable) InsertBulk(ctx context.Context, d sqldb.Executor, models []*Event, allowConflict bool) error {
+	conflictSQL := ""
+	if allowConflict {
+		conflictSQL = " ON DUPLICATE KEY UPDATE ` + "`" + `eventid` + "`" + ` = ` + "`" + `eventid` + "`" + `"
+	}
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*3)
+	for _, m := range models {
+		placeholders = append(placeholders, "(?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)")
+		args = append(args, m.Eventid, m.Kind, m.Count)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `eventid` + "`" + `, ` + "`" + `kind` + "`" + `, ` + "`" + `count` + "`" + `, ` + "`" + `creation_time` + "`" + `, ` + "`" + `update_time` + "`" + `) VALUES "+strings.Join(placeholders, ", ")+conflictSQL+";", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *eventModelTable) UpdEventByID(ctx context.Context, d sqldb.Executor, m *Event, eventid string) error {
+	_, err := d.ExecContext(ctx, "UPDATE ` + "`" + `"+t.TableName+"` + "`" + ` SET ` + "`" + `eventid` + "`" + ` = ?, ` + "`" + `kind` + "`" + ` = ?, ` + "`" + `count` + "`" + ` = ?, ` + "`" + `creation_time` + "`" + ` = ?, ` + "`" + `update_time` + "`" + ` = CURRENT_TIMESTAMP WHERE ` + "`" + `eventid` + "`" + ` = ?;", m.Eventid, m.Kind, m.Count, m.CreationTime, eventid)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *eventModelTable) UpsertEventByID(ctx context.Context, d sqldb.Executor, m *Event) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `eventid` + "`" + `, ` + "`" + `kind` + "`" + `, ` + "`" + `count` + "`" + `, ` + "`" + `creation_time` + "`" + `, ` + "`" + `update_time` + "`" + `) VALUES (?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP) ON DUPLICATE KEY UPDATE ` + "`" + `kind` + "`" + ` = VALUES(` + "`" + `kind` + "`" + `), ` + "`" + `count` + "`" + ` = VALUES(` + "`" + `count` + "`" + `), ` + "`" + `update_time` + "`" + ` = VALUES(` + "`" + `update_time` + "`" + `);", m.Eventid, m.Kind, m.Count)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *eventModelTable) UpsertEventByIDBulk(ctx context.Context, d sqldb.Executor, models []*Event) error {
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*3)
+	for _, m := range models {
+		placeholders = append(placeholders, "(?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)")
+		args = append(args, m.Eventid, m.Kind, m.Count)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `eventid` + "`" + `, ` + "`" + `kind` + "`" + `, ` + "`" + `count` + "`" + `, ` + "`" + `creation_time` + "`" + `, ` + "`" + `update_time` + "`" + `) VALUES "+strings.Join(placeholders, ", ")+" ON DUPLICATE KEY UPDATE ` + "`" + `kind` + "`" + ` = VALUES(` + "`" + `kind` + "`" + `), ` + "`" + `count` + "`" + ` = VALUES(` + "`" + `count` + "`" + `), ` + "`" + `update_time` + "`" + ` = VALUES(` + "`" + `update_time` + "`" + `);", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *eventModelTable) UpdEventCountByID(ctx context.Context, d sqldb.Executor, m *EventCount, eventid string) error {
+	_, err := d.ExecContext(ctx, "UPDATE ` + "`" + `"+t.TableName+"` + "`" + ` SET ` + "`" + `count` + "`" + ` = ` + "`" + `count` + "`" + ` + ?, ` + "`" + `update_time` + "`" + ` = CURRENT_TIMESTAMP WHERE ` + "`" + `eventid` + "`" + ` = ?;", m.Count, eventid)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *eventModelTable) PatchEventPatchByID(ctx context.Context, d sqldb.Executor, m *EventPatch, eventid string) error {
+	set := make([]string, 0, 2)
+	setArgs := make([]interface{}, 0, 2)
+	if m.Kind != nil {
+		set = append(set, "` + "`" + `kind` + "`" + ` = ?")
+		setArgs = append(setArgs, *m.Kind)
+	}
+	set = append(set, "` + "`" + `update_time` + "`" + ` = CURRENT_TIMESTAMP")
+	args := append(setArgs, eventid)
+	_, err := d.ExecContext(ctx, "UPDATE ` + "`" + `"+t.TableName+"` + "`" + ` SET "+strings.Join(set, ", ")+" WHERE ` + "`" + `eventid` + "`" + ` = ?;", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+type (
+	postModelTable struct {
+		TableName string
+		// Clock returns the current time, and is time.Now if nil
+		Clock func() time.Time
+	}
+)
+
+func (t *postModelTable) now() time.Time {
+	if t.Clock != nil {
+		return t.Clock()
+	}
+	return time.Now()
+}
+
+func (t *postModelTable) Setup(ctx context.Context, d sqldb.Executor) error {
+	_, err := d.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `postid` + "`" + ` VARCHAR(31) PRIMARY KEY, ` + "`" + `body` + "`" + ` VARCHAR(4095) NOT NULL, ` + "`" + `views` + "`" + ` BIGINT NOT NULL, ` + "`" + `update_time` + "`" + ` TIMESTAMP NOT NULL);")
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *postModelTable) Insert(ctx context.Context, d sqldb.Executor, m *Post) error {
+	_, err := d.ExecContext(ctx, "INSERT INTO ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `postid` + "`" + `, ` + "`" + `body` + "`" + `, ` + "`" + `views` + "`" + `, ` + "`" + `update_time` + "`" + `) VALUES (?, ?, ?, ?);", m.Postid, m.Body, m.Views, m.UpdateTime)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *postModelTable) InsertBulk(ctx context.Context, d sqldb.Executor, models []*Post, allowConflict bool) error {
+	conflictSQL := ""
+	if allowConflict {
+		conflictSQL = " ON DUPLICATE KEY UPDATE ` + "`" + `postid` + "`" + ` = ` + "`" + `postid` + "`" + `"
+	}
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*4)
+	for _, m := range models {
+		placeholders = append(placeholders, "(?, ?, ?, ?)")
+		args = append(args, m.Postid, m.Body, m.Views, m.UpdateTime)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `postid` + "`" + `, ` + "`" + `body` + "`" + `, ` + "`" + `views` + "`" + `, ` + "`" + `update_time` + "`" + `) VALUES "+strings.Join(placeholders, ", ")+conflictSQL+";", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *postModelTable) UpdPostBodyByID(ctx context.Context, d sqldb.Executor, m *PostBody, postid string) error {
+	now := t.now()
+	_, err := d.ExecContext(ctx, "UPDATE ` + "`" + `"+t.TableName+"` + "`" + ` SET ` + "`" + `body` + "`" + ` = ?, ` + "`" + `update_time` + "`" + ` = ? WHERE ` + "`" + `postid` + "`" + ` = ?;", m.Body, now, postid)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *postModelTable) UpdPostViewsByID(ctx context.Context, d sqldb.Executor, m *PostViews, postid string) error {
+	now := t.now()
+	m.UpdateTime = now
+	_, err := d.ExecContext(ctx, "UPDATE ` + "`" + `"+t.TableName+"` + "`" + ` SET ` + "`" + `views` + "`" + ` = ` + "`" + `views` + "`" + ` + ?, ` + "`" + `update_time` + "`" + ` = ? WHERE ` + "`" + `postid` + "`" + ` = ?;", m.Views, m.UpdateTime, postid)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *postModelTable) UpsertPostUpsertByID(ctx context.Context, d sqldb.Executor, m *PostUpsert) error {
+	now := t.now()
+	_, err := d.ExecContext(ctx, "INSERT INTO ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `postid` + "`" + `, ` + "`" + `body` + "`" + `, ` + "`" + `views` + "`" + `, ` + "`" + `update_time` + "`" + `) VALUES (?, ?, ?, ?) ON DUPLICATE KEY UPDATE ` + "`" + `body` + "`" + ` = VALUES(` + "`" + `body` + "`" + `), ` + "`" + `update_time` + "`" + ` = VALUES(` + "`" + `update_time` + "`" + `);", m.Postid, m.Body, m.Views, now)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *postModelTable) UpsertPostUpsertByIDBulk(ctx context.Context, d sqldb.Executor, models []*PostUpsert) error {
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*4)
+	now := t.now()
+	for _, m := range models {
+		placeholders = append(placeholders, "(?, ?, ?, ?)")
+		args = append(args, m.Postid, m.Body, m.Views, now)
+	}
+	_, err := d.ExecContext(ctx, "INSERT INTO ` + "`" + `"+t.TableName+"` + "`" + ` (` + "`" + `postid` + "`" + `, ` + "`" + `body` + "`" + `, ` + "`" + `views` + "`" + `, ` + "`" + `update_time` + "`" + `) VALUES "+strings.Join(placeholders, ", ")+" ON DUPLICATE KEY UPDATE ` + "`" + `body` + "`" + ` = VALUES(` + "`" + `body` + "`" + `), ` + "`" + `update_time` + "`" + ` = VALUES(` + "`" + `update_time` + "`" + `);", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *postModelTable) PatchPostPatchByID(ctx context.Context, d sqldb.Executor, m *PostPatch, postid string) error {
+	now := t.now()
+	set := make([]string, 0, 2)
+	setArgs := make([]interface{}, 0, 2)
+	if m.Body != nil {
+		set = append(set, "` + "`" + `body` + "`" + ` = ?")
+		setArgs = append(setArgs, *m.Body)
+	}
+	set = append(set, "` + "`" + `update_time` + "`" + ` = ?")
+	setArgs = append(setArgs, now)
+	args := append(setArgs, postid)
+	_, err := d.ExecContext(ctx, "UPDATE ` + "`" + `"+t.TableName+"` + "`" + ` SET "+strings.Join(set, ", ")+" WHERE ` + "`" + `postid` + "`" + ` = ?;", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+`,
+			},
+		},
+		{
+			Name: "errors on invalid schema file",
+			Fsys: fstest.MapFS{
+				"model.json": &fstest.MapFile{
+					Data:    []byte(`"bogus"`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	Model struct {
+		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidSchema,
+		},
+		{
+			Name: "errors on no models",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidFile,
+		},
+		{
+			Name: "errors on model directive on non-typedef",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+//forge:model user
+const (
+	foo = "bar"
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidFile,
+		},
+		{
+			Name: "errors on model directive without prefix arg",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model
+	Model struct {
+		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidFile,
+		},
+		{
+			Name: "errors on model tag on multiple fields",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	Model struct {
+		Userid, Other string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidFile,
+		},
+		{
+			Name: "errors on malformed model tag",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	Model struct {
+		Userid string ` + "`" + `model:""` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on no model tags",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	Model struct {
+		Userid string
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on model directive on non-struct",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	Model []string
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidFile,
+		},
+		{
+			Name: "errors on duplicate model field",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	Model struct {
+		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on invalid model index opt field",
+			Fsys: fstest.MapFS{
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "model": {
+        "indicies": [
+          {
+            "columns": [{"col": "bogus"}, {"col": "userid"}]
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	Model struct {
+		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on missing model index opt columns",
+			Fsys: fstest.MapFS{
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "model": {
+        "indicies": [
+          {
+            "columns": []
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	Model struct {
+		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on missing model constraint opt kind",
+			Fsys: fstest.MapFS{
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "model": {
+        "constraints": [
+          {
+            "kind": "",
+            "columns": ["userid"]
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	Model struct {
+		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on missing model constraint opt columns",
+			Fsys: fstest.MapFS{
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "model": {
+        "constraints": [
+          {
+            "kind": "UNIQUE",
+            "columns": []
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	Model struct {
+		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on invalid model constraint opt field",
+			Fsys: fstest.MapFS{
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "model": {
+        "constraints": [
+          {
+            "kind": "UNIQUE",
+            "columns": ["bogus", "userid"]
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	Model struct {
+		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on query directive without prefix arg",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query
+	Model struct {
+		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidFile,
+		},
+		{
+			Name: "errors on query directive without model def",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query dne
+	Model struct {
+		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidFile,
+		},
+		{
+			Name: "errors on query directive on non-typedef",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	Model struct {
+		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+	}
+)
+
+//forge:model:query user
+const (
+	foo = "bar"
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidFile,
+		},
+		{
+			Name: "errors on query directive on non-struct",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	Model struct {
+		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+	}
+
+	//forge:model:query user
+	Info []string
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidFile,
+		},
+		{
+			Name: "errors on query without fields",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	Model struct {
+		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+	}
+
+	//forge:model:query user
+	Info struct {
+		Userid string
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on query tag on multiple fields",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	Model struct {
+		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+	}
+
+  //forge:model:query user
+	Info struct {
+		Userid, Other string ` + "`" + `model:"userid"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidFile,
+		},
+		{
+			Name: "errors on malformed query tag",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	Model struct {
+		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+	}
+
+	//forge:model:query user
+	Info struct {
+		Userid string ` + "`" + `model:""` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on invalid query tag field",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	Model struct {
+		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+	}
+
+	//forge:model:query user
+	Info struct {
+		Userid string ` + "`" + `model:"bogus"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on no queries",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data:    []byte(`{}`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on missing query name",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "getoneeq",
+            "name": "",
+            "conditions": [
+              {"col": "userid"}
+            ]
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on invalid query kind",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "bogus",
+            "name": "ByID",
+            "conditions": [
+              {"col": "userid"}
+            ]
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on missing required query conditions",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "getoneeq",
+            "name": "ByID"
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on providing conditions when not accepted",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "getgroup",
+            "name": "ByID",
+            "conditions": [
+              {"col": "userid"}
+            ]
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on invalid query cond",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "getoneeq",
+            "name": "ByID",
+            "conditions": [
+              {"col": "userid", "cond": "bogus"}
+            ]
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on invalid query cond field",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "getoneeq",
+            "name": "ByID",
+            "conditions": [
+              {"col": "bogus"}
+            ]
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on providing query order when not accepted",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "getoneeq",
+            "name": "ByID",
+            "conditions": [
+              {"col": "userid"}
+            ],
+            "order": [
+              {"col": "userid"}
+            ]
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on invalid query order field",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "getgroup",
+            "name": "All",
+            "order": [
+              {"col": "bogus"}
+            ]
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on missing upsert conflict",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "upsert",
+            "name": "ByID"
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on invalid upsert conflict field",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "upsert",
+            "name": "ByID",
+            "conflict": ["bogus"]
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on invalid upsert update field",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "upsert",
+            "name": "ByID",
+            "conflict": ["userid"],
+            "update": ["bogus"]
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on providing conflict when not accepted",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "getoneeq",
+            "name": "ByID",
+            "conditions": [
+              {"col": "userid"}
+            ],
+            "conflict": ["userid"]
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on missing keyset order",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "getgroupkeyset",
+            "name": "Page"
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on keyset order field not in query",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+	}
+
+	//forge:model:query user
+	Info struct {
+		Username string ` + "`" + `model:"username"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Info": [
+          {
+            "kind": "getgroupkeyset",
+            "name": "Page",
+            "order": [
+              {"col": "userid"}
+            ]
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on invalid keyset order dir",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "getgroupkeyset",
+            "name": "Page",
+            "order": [
+              {"col": "userid", "dir": "DESC NULLS LAST"}
+            ]
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on providing stream when not accepted",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "getoneeq",
+            "name": "ByID",
+            "conditions": [
+              {"col": "userid"}
+            ],
+            "stream": true
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on unknown model returning field",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "model": {
+        "returning": ["bogus"]
+      },
+      "queries": {
+        "Model": [
+          {
+            "kind": "getoneeq",
+            "name": "ByID",
+            "conditions": [
+              {"col": "userid"}
+            ]
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on unknown returning query struct",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "deleq",
+            "name": "ByID",
+            "conditions": [
+              {"col": "userid"}
+            ],
+            "returning": "Bogus"
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on providing returning when not accepted",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "getoneeq",
+            "name": "ByID",
+            "conditions": [
+              {"col": "userid"}
+            ],
+            "returning": "Model"
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name:    "errors on returning for unsupported dialect",
+			Dialect: DialectMySQL{},
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "deleq",
+            "name": "ByID",
+            "conditions": [
+              {"col": "userid"}
+            ],
+            "returning": "Model"
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidDialect,
+		},
+		{
+			Name: "errors on invalid query affected",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "deleq",
+            "name": "ByID",
+            "conditions": [
+              {"col": "userid"}
+            ],
+            "affected": "bogus"
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on providing affected with returning",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "deleq",
+            "name": "ByID",
+            "conditions": [
+              {"col": "userid"}
+            ],
+            "returning": "Model",
+            "affected": "count"
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on providing affected when not accepted",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "getoneeq",
+            "name": "ByID",
+            "conditions": [
+              {"col": "userid"}
+            ],
+            "affected": "count"
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on optional query fields when not accepted",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+	}
+
+	//forge:model:query user
+	Patch struct {
+		Userid   string  ` + "`" + `model:"userid"` + "`" + `
+		Username *string ` + "`" + `model:"username"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Patch": [
+          {
+            "kind": "updeq",
+            "name": "ByID",
+            "conditions": [
+              {"col": "userid"}
+            ]
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on empty condition group",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "getoneeq",
+            "name": "ByID",
+            "conditions": [
+              {"col": "userid"},
+              {
+                "any": []
+              }
+            ]
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on condition group with field",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "getoneeq",
+            "name": "ByID",
+            "conditions": [
+              {
+                "col": "userid",
+                "all": [
+                  {"col": "username"}
+                ]
+              }
+            ]
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on invalid condition group field",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "getoneeq",
+            "name": "ByID",
+            "conditions": [
+              {
+                "any": [
+                  {"col": "userid"},
+                  {"col": "bogus"}
+                ]
+              }
+            ]
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on like condition on non-string field",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+		Age      int    ` + "`" + `model:"age,INT NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "counteq",
+            "name": "Age",
+            "conditions": [
+              {"col": "age", "cond": "prefix"}
+            ]
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on array condition on non-slice field",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "counteq",
+            "name": "Username",
+            "conditions": [
+              {"col": "username", "cond": "arrcontains"}
+            ]
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name:    "errors on array condition unsupported by dialect",
+			Dialect: DialectSQLite{},
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string   ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string   ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+		Tags     []string ` + "`" + `model:"tags,TEXT[] NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "counteq",
+            "name": "Tags",
+            "conditions": [
+              {"col": "tags", "cond": "arroverlap"}
+            ]
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidDialect,
+		},
+		{
+			Name: "errors on providing sort when not accepted",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "getoneeq",
+            "name": "ByID",
+            "conditions": [
+              {"col": "userid"}
+            ],
+            "sort": [
+              {
+                "name": "Username",
+                "order": [
+                  {"col": "username"}
+                ]
+              }
+            ]
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on providing both order and sort",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "getgroup",
+            "name": "All",
+            "order": [
+              {"col": "userid"}
+            ],
+            "sort": [
+              {
+                "name": "Username",
+                "order": [
+                  {"col": "username"}
+                ]
+              }
+            ]
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on duplicate sort name",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "getgroup",
+            "name": "All",
+            "sort": [
+              {
+                "name": "Username",
+                "order": [
+                  {"col": "username"}
+                ]
+              },
+              {
+                "name": "Username",
+                "order": [
+                  {"col": "userid"}
+                ]
+              }
+            ]
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on invalid sort order field",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "getgroup",
+            "name": "All",
+            "sort": [
+              {
+                "name": "Bogus",
+                "order": [
+                  {"col": "bogus"}
+                ]
+              }
+            ]
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on join missing schema",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL UNIQUE"` + "`" + `
+	}
+
+	//forge:model profile
+	Profile struct {
+		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Bio    string ` + "`" + `model:"bio,VARCHAR(4095) NOT NULL"` + "`" + `
+	}
+
+	//forge:model:join userProfile
+	UserProfile struct {
+		Userid   string  ` + "`" + `model:"user.userid"` + "`" + `
+		Username string  ` + "`" + `model:"user.username"` + "`" + `
+		Bio      *string ` + "`" + `model:"profile.bio"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {},
+    "profile": {}
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on join unknown model",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL UNIQUE"` + "`" + `
+	}
+
+	//forge:model profile
+	Profile struct {
+		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Bio    string ` + "`" + `model:"bio,VARCHAR(4095) NOT NULL"` + "`" + `
+	}
+
+	//forge:model:join userProfile
+	UserProfile struct {
+		Userid   string  ` + "`" + `model:"user.userid"` + "`" + `
+		Username string  ` + "`" + `model:"user.username"` + "`" + `
+		Bio      *string ` + "`" + `model:"profile.bio"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {},
+    "profile": {}
+  },
+  "joins": {
+    "userProfile": {
+      "from": "user",
+      "join": [
+        {
+          "model": "post",
+          "on": [
+            {
+              "col": "profile.userid",
+              "ref": "user.userid"
+            }
+          ]
+        }
+      ],
+      "queries": [
+        {
+          "kind": "getoneeq",
+          "name": "ByID",
+          "conditions": [
+            {
+              "col": "user.userid"
+            }
+          ]
+        }
+      ]
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on join invalid kind",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL UNIQUE"` + "`" + `
+	}
+
+	//forge:model profile
+	Profile struct {
+		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Bio    string ` + "`" + `model:"bio,VARCHAR(4095) NOT NULL"` + "`" + `
+	}
+
+	//forge:model:join userProfile
+	UserProfile struct {
+		Userid   string  ` + "`" + `model:"user.userid"` + "`" + `
+		Username string  ` + "`" + `model:"user.username"` + "`" + `
+		Bio      *string ` + "`" + `model:"profile.bio"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {},
+    "profile": {}
+  },
+  "joins": {
+    "userProfile": {
+      "from": "user",
+      "join": [
+        {
+          "model": "profile",
+          "on": [
+            {
+              "col": "profile.userid",
+              "ref": "user.userid"
+            }
+          ],
+          "kind": "outer"
+        }
+      ],
+      "queries": [
+        {
+          "kind": "getoneeq",
+          "name": "ByID",
+          "conditions": [
+            {
+              "col": "user.userid"
+            }
+          ]
+        }
+      ]
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on join on field of another model",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL UNIQUE"` + "`" + `
+	}
+
+	//forge:model profile
+	Profile struct {
+		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Bio    string ` + "`" + `model:"bio,VARCHAR(4095) NOT NULL"` + "`" + `
+	}
+
+	//forge:model:join userProfile
+	UserProfile struct {
+		Userid   string  ` + "`" + `model:"user.userid"` + "`" + `
+		Username string  ` + "`" + `model:"user.username"` + "`" + `
+		Bio      *string ` + "`" + `model:"profile.bio"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {},
+    "profile": {}
+  },
+  "joins": {
+    "userProfile": {
+      "from": "user",
+      "join": [
+        {
+          "model": "profile",
+          "on": [
+            {
+              "col": "user.userid",
+              "ref": "profile.userid"
+            }
+          ]
+        }
+      ],
+      "queries": [
+        {
+          "kind": "getoneeq",
+          "name": "ByID",
+          "conditions": [
+            {
+              "col": "user.userid"
+            }
+          ]
+        }
+      ]
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on join missing on fields",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL UNIQUE"` + "`" + `
+	}
+
+	//forge:model profile
+	Profile struct {
+		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Bio    string ` + "`" + `model:"bio,VARCHAR(4095) NOT NULL"` + "`" + `
+	}
+
+	//forge:model:join userProfile
+	UserProfile struct {
+		Userid   string  ` + "`" + `model:"user.userid"` + "`" + `
+		Username string  ` + "`" + `model:"user.username"` + "`" + `
+		Bio      *string ` + "`" + `model:"profile.bio"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {},
+    "profile": {}
+  },
+  "joins": {
+    "userProfile": {
+      "from": "user",
+      "join": [
+        {
+          "model": "profile",
+          "on": []
+        }
+      ],
+      "queries": [
+        {
+          "kind": "getoneeq",
+          "name": "ByID",
+          "conditions": [
+            {
+              "col": "user.userid"
+            }
+          ]
+        }
+      ]
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on join unsupported query kind",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL UNIQUE"` + "`" + `
+	}
+
+	//forge:model profile
+	Profile struct {
+		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Bio    string ` + "`" + `model:"bio,VARCHAR(4095) NOT NULL"` + "`" + `
+	}
+
+	//forge:model:join userProfile
+	UserProfile struct {
+		Userid   string  ` + "`" + `model:"user.userid"` + "`" + `
+		Username string  ` + "`" + `model:"user.username"` + "`" + `
+		Bio      *string ` + "`" + `model:"profile.bio"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {},
+    "profile": {}
+  },
+  "joins": {
+    "userProfile": {
+      "from": "user",
+      "join": [
+        {
+          "model": "profile",
+          "on": [
+            {
+              "col": "profile.userid",
+              "ref": "user.userid"
+            }
+          ]
+        }
+      ],
+      "queries": [
+        {
+          "kind": "deleq",
+          "name": "ByID",
+          "conditions": [
+            {
+              "col": "user.userid"
+            }
+          ]
+        }
+      ]
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on join missing queries",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL UNIQUE"` + "`" + `
+	}
+
+	//forge:model profile
+	Profile struct {
+		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Bio    string ` + "`" + `model:"bio,VARCHAR(4095) NOT NULL"` + "`" + `
+	}
+
+	//forge:model:join userProfile
+	UserProfile struct {
+		Userid   string  ` + "`" + `model:"user.userid"` + "`" + `
+		Username string  ` + "`" + `model:"user.username"` + "`" + `
+		Bio      *string ` + "`" + `model:"profile.bio"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {},
+    "profile": {}
+  },
+  "joins": {
+    "userProfile": {
+      "from": "user",
+      "join": [
+        {
+          "model": "profile",
+          "on": [
+            {
+              "col": "profile.userid",
+              "ref": "user.userid"
+            }
+          ]
+        }
+      ],
+      "queries": []
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on join unknown field",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL UNIQUE"` + "`" + `
+	}
+
+	//forge:model profile
+	Profile struct {
+		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Bio    string ` + "`" + `model:"bio,VARCHAR(4095) NOT NULL"` + "`" + `
+	}
+
+	//forge:model:join userProfile
+	UserProfile struct {
+		Userid   string  ` + "`" + `model:"user.userid"` + "`" + `
+		Username string  ` + "`" + `model:"user.username"` + "`" + `
+		Bio      *string ` + "`" + `model:"profile.bioo"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {},
+    "profile": {}
+  },
+  "joins": {
+    "userProfile": {
+      "from": "user",
+      "join": [
+        {
+          "model": "profile",
+          "on": [
+            {
+              "col": "profile.userid",
+              "ref": "user.userid"
+            }
+          ]
+        }
+      ],
+      "queries": [
+        {
+          "kind": "getoneeq",
+          "name": "ByID",
+          "conditions": [
+            {
+              "col": "user.userid"
+            }
+          ]
+        }
+      ]
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on join prefix of a model",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL UNIQUE"` + "`" + `
+	}
+
+	//forge:model profile
+	Profile struct {
+		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Bio    string ` + "`" + `model:"bio,VARCHAR(4095) NOT NULL"` + "`" + `
+	}
+
+	//forge:model:join user
+	UserProfile struct {
+		Userid   string  ` + "`" + `model:"user.userid"` + "`" + `
+		Username string  ` + "`" + `model:"user.username"` + "`" + `
+		Bio      *string ` + "`" + `model:"profile.bio"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {},
+    "profile": {}
+  },
+  "joins": {
+    "userProfile": {
+      "from": "user",
+      "join": [
+        {
+          "model": "profile",
+          "on": [
+            {
+              "col": "profile.userid",
+              "ref": "user.userid"
+            }
+          ]
+        }
+      ],
+      "queries": [
+        {
+          "kind": "getoneeq",
+          "name": "ByID",
+          "conditions": [
+            {
+              "col": "user.userid"
+            }
+          ]
+        }
+      ]
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidFile,
+		},
+		{
+			Name: "errors on unknown aggregate",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+		Score    int64  ` + "`" + `model:"score,BIGINT NOT NULL"` + "`" + `
+	}
+
+	//forge:model:query user
+	Stats struct {
+		Username string ` + "`" + `model:"username"` + "`" + `
+		Count    int    ` + "`" + `model:"median(score)"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Stats": [
+          {
+            "kind": "aggregate",
+            "name": "ByName"
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on aggregate count of non-integer type",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+		Score    int64  ` + "`" + `model:"score,BIGINT NOT NULL"` + "`" + `
+	}
+
+	//forge:model:query user
+	Stats struct {
+		Username string ` + "`" + `model:"username"` + "`" + `
+		Count    string ` + "`" + `model:"count(*)"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Stats": [
+          {
+            "kind": "aggregate",
+            "name": "ByName"
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on aggregate sum of non-numeric field",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+		Score    int64  ` + "`" + `model:"score,BIGINT NOT NULL"` + "`" + `
+	}
+
+	//forge:model:query user
+	Stats struct {
+		Username string ` + "`" + `model:"username"` + "`" + `
+		Count    int    ` + "`" + `model:"sum(username)"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Stats": [
+          {
+            "kind": "aggregate",
+            "name": "ByName"
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on aggregate avg of non-float type",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+		Score    int64  ` + "`" + `model:"score,BIGINT NOT NULL"` + "`" + `
+	}
+
+	//forge:model:query user
+	Stats struct {
+		Username string ` + "`" + `model:"username"` + "`" + `
+		Count    int    ` + "`" + `model:"avg(score)"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Stats": [
+          {
+            "kind": "aggregate",
+            "name": "ByName"
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on aggregate max of different type",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+		Score    int64  ` + "`" + `model:"score,BIGINT NOT NULL"` + "`" + `
+	}
+
+	//forge:model:query user
+	Stats struct {
+		Username string ` + "`" + `model:"username"` + "`" + `
+		Count    int    ` + "`" + `model:"max(username)"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Stats": [
+          {
+            "kind": "aggregate",
+            "name": "ByName"
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on aggregate of unknown field",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+		Score    int64  ` + "`" + `model:"score,BIGINT NOT NULL"` + "`" + `
+	}
+
+	//forge:model:query user
+	Stats struct {
+		Username string ` + "`" + `model:"username"` + "`" + `
+		Count    int    ` + "`" + `model:"count(email)"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Stats": [
+          {
+            "kind": "aggregate",
+            "name": "ByName"
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on aggregate fields of other query kinds",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+		Score    int64  ` + "`" + `model:"score,BIGINT NOT NULL"` + "`" + `
+	}
+
+	//forge:model:query user
+	Stats struct {
+		Username string ` + "`" + `model:"username"` + "`" + `
+		Count    int    ` + "`" + `model:"count(*)"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Stats": [
+          {
+            "kind": "getgroup",
+            "name": "All"
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on aggregate without aggregate fields",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+		Score    int64  ` + "`" + `model:"score,BIGINT NOT NULL"` + "`" + `
+	}
+
+	//forge:model:query user
+	Stats struct {
+		Username string ` + "`" + `model:"username"` + "`" + `
+		Count    int    ` + "`" + `model:"count(*)"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "aggregate",
+            "name": "All"
+          }
+        ],
+        "Stats": [
+          {
+            "kind": "aggregate",
+            "name": "ByName"
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on aggregate condition in where",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+		Score    int64  ` + "`" + `model:"score,BIGINT NOT NULL"` + "`" + `
+	}
+
+	//forge:model:query user
+	Stats struct {
+		Username string ` + "`" + `model:"username"` + "`" + `
+		Count    int    ` + "`" + `model:"count(*)"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Stats": [
+          {
+            "kind": "aggregate",
+            "name": "ByName",
+            "conditions": [
+              {
+                "col": "count(*)",
+                "cond": "gt"
+              }
+            ]
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on having of other query kinds",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+		Score    int64  ` + "`" + `model:"score,BIGINT NOT NULL"` + "`" + `
+	}
+
+	//forge:model:query user
+	Stats struct {
+		Username string ` + "`" + `model:"username"` + "`" + `
+		Count    int    ` + "`" + `model:"count(*)"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "getgroup",
+            "name": "All",
+            "having": [
+              {
+                "col": "userid"
+              }
+            ]
+          }
+        ],
+        "Stats": [
+          {
+            "kind": "aggregate",
+            "name": "ByName"
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on raw missing sql",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "raw",
+            "name": "All"
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on raw unknown substitution",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "raw",
+            "name": "All",
+            "sql": "SELECT {{columns}} FROM {{table}} WHERE {{email}} = :username"
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on raw unknown param field",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "raw",
+            "name": "All",
+            "sql": "SELECT {{columns}} FROM {{table}} WHERE {{username}} = :name"
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on raw unterminated quote",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "raw",
+            "name": "All",
+            "sql": "SELECT {{columns}} FROM {{table}} WHERE {{username}} = 'abc"
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on raw unused param",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "raw",
+            "name": "All",
+            "sql": "SELECT {{columns}} FROM {{table}}",
+            "params": [
+              {
+                "name": "name",
+                "col": "username"
+              }
+            ]
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on raw param of unknown field",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "raw",
+            "name": "All",
+            "sql": "SELECT {{columns}} FROM {{table}} WHERE {{username}} = :name",
+            "params": [
+              {
+                "name": "name",
+                "col": "email"
+              }
+            ]
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on sql of other query kinds",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "getgroup",
+            "name": "All",
+            "sql": "SELECT {{columns}} FROM {{table}}"
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on raw sql and sqlfile",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "raw",
+            "name": "All",
+            "sql": "SELECT {{columns}} FROM {{table}}",
+            "sqlfile": "all.sql"
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidSchema,
+		},
+		{
+			Name: "errors on getmapin missing in condition",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "getmapin",
+            "name": "ByIDs",
+            "conditions": [
+              {
+                "col": "userid"
+              }
+            ]
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on getmapin multiple in conditions",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "getmapin",
+            "name": "ByIDs",
+            "conditions": [
+              {
+                "col": "userid",
+                "cond": "in"
+              },
+              {
+                "col": "username",
+                "cond": "in"
+              }
+            ]
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on many of other query kinds",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "getgroup",
+            "name": "All",
+            "many": true
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on getmapin key not of query struct",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+	}
+
+	//forge:model:query user
+	Info struct {
+		Username string ` + "`" + `model:"username"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Info": [
+          {
+            "kind": "getmapin",
+            "name": "ByIDs",
+            "conditions": [
+              {
+                "col": "userid",
+                "cond": "in"
+              }
+            ]
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on invalid lock mode",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "getoneeq",
+            "name": "ByID",
+            "conditions": [
+              {
+                "col": "userid"
+              }
+            ],
+            "lock": {
+              "mode": "exclusive"
+            }
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
 				},
 			},
 			Err: ErrInvalidModel,
 		},
 		{
-			Name: "errors on invalid model index opt field",
+			Name: "errors on invalid lock wait",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "getoneeq",
+            "name": "ByID",
+            "conditions": [
+              {
+                "col": "userid"
+              }
+            ],
+            "lock": {
+              "mode": "update",
+              "wait": "forever"
+            }
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on lock of other query kinds",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "counteq",
+            "name": "ByID",
+            "conditions": [
+              {
+                "col": "userid"
+              }
+            ],
+            "lock": {
+              "mode": "update"
+            }
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name:    "errors on lock of sqlite dialect",
+			Dialect: DialectSQLite{},
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "getoneeq",
+            "name": "ByID",
+            "conditions": [
+              {
+                "col": "userid"
+              }
+            ],
+            "lock": {
+              "mode": "update"
+            }
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidDialect,
+		},
+		{
+			Name:    "errors on mysql share lock with wait",
+			Dialect: DialectMySQL{},
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "getoneeq",
+            "name": "ByID",
+            "conditions": [
+              {
+                "col": "userid"
+              }
+            ],
+            "lock": {
+              "mode": "share",
+              "wait": "skiplocked"
+            }
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidDialect,
+		},
+		{
+			Name: "errors on unknown soft delete field",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "model": {
+        "softDelete": "deleted_at"
+      },
+      "queries": {
+        "Model": [
+          {
+            "kind": "getoneeq",
+            "name": "ByID",
+            "conditions": [
+              {
+                "col": "userid"
+              }
+            ]
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on invalid soft delete field type",
+			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "model": {
+        "softDelete": "username"
+      },
+      "queries": {
+        "Model": [
+          {
+            "kind": "getoneeq",
+            "name": "ByID",
+            "conditions": [
+              {
+                "col": "userid"
+              }
+            ]
+          }
+        ]
+      }
+    }
+  }
+}
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on unknown version field",
 			Fsys: fstest.MapFS{
+				"stuff.go": &fstest.MapFile{
+					Data: []byte(`package somepackage
+
+type (
+	//forge:model user
+	//forge:model:query user
+	Model struct {
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+	}
+)
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
 				"model.json": &fstest.MapFile{
 					Data: []byte(`
 {
   "models": {
     "user": {
       "model": {
-        "indicies": [
+        "version": "updated"
+      },
+      "queries": {
+        "Model": [
           {
-            "columns": [{"col": "bogus"}, {"col": "userid"}]
+            "kind": "updeq",
+            "name": "ByID",
+            "conditions": [
+              {
+                "col": "userid"
+              }
+            ]
           }
         ]
       }
@@ -677,34 +16477,45 @@ type (
 					Mode:    filemode,
 					ModTime: now,
 				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on invalid version field type",
+			Fsys: fstest.MapFS{
 				"stuff.go": &fstest.MapFile{
 					Data: []byte(`package somepackage
 
 type (
 	//forge:model user
+	//forge:model:query user
 	Model struct {
-		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
 	}
 )
 `),
 					Mode:    filemode,
 					ModTime: now,
 				},
-			},
-			Err: ErrInvalidModel,
-		},
-		{
-			Name: "errors on missing model index opt columns",
-			Fsys: fstest.MapFS{
 				"model.json": &fstest.MapFile{
 					Data: []byte(`
 {
   "models": {
     "user": {
       "model": {
-        "indicies": [
+        "version": "username"
+      },
+      "queries": {
+        "Model": [
           {
-            "columns": []
+            "kind": "updeq",
+            "name": "ByID",
+            "conditions": [
+              {
+                "col": "userid"
+              }
+            ]
           }
         ]
       }
@@ -715,35 +16526,51 @@ type (
 					Mode:    filemode,
 					ModTime: now,
 				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on versioned update without version field",
+			Fsys: fstest.MapFS{
 				"stuff.go": &fstest.MapFile{
 					Data: []byte(`package somepackage
 
 type (
 	//forge:model user
+	//forge:model:query user
 	Model struct {
-		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+		Version  int64  ` + "`" + `model:"version,BIGINT NOT NULL"` + "`" + `
+	}
+
+	//forge:model:query user
+	Info struct {
+		Username string ` + "`" + `model:"username"` + "`" + `
 	}
 )
 `),
 					Mode:    filemode,
 					ModTime: now,
 				},
-			},
-			Err: ErrInvalidModel,
-		},
-		{
-			Name: "errors on missing model constraint opt kind",
-			Fsys: fstest.MapFS{
 				"model.json": &fstest.MapFile{
 					Data: []byte(`
 {
   "models": {
     "user": {
       "model": {
-        "constraints": [
+        "version": "version"
+      },
+      "queries": {
+        "Info": [
           {
-            "kind": "",
-            "columns": ["userid"]
+            "kind": "updeq",
+            "name": "ByID",
+            "conditions": [
+              {
+                "col": "userid"
+              }
+            ]
           }
         ]
       }
@@ -754,35 +16581,52 @@ type (
 					Mode:    filemode,
 					ModTime: now,
 				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on versioned update with affected",
+			Fsys: fstest.MapFS{
 				"stuff.go": &fstest.MapFile{
 					Data: []byte(`package somepackage
 
 type (
 	//forge:model user
+	//forge:model:query user
 	Model struct {
-		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+		Version  int64  ` + "`" + `model:"version,BIGINT NOT NULL"` + "`" + `
+	}
+
+	//forge:model:query user
+	Info struct {
+		Username string ` + "`" + `model:"username"` + "`" + `
 	}
 )
 `),
 					Mode:    filemode,
 					ModTime: now,
 				},
-			},
-			Err: ErrInvalidModel,
-		},
-		{
-			Name: "errors on missing model constraint opt columns",
-			Fsys: fstest.MapFS{
 				"model.json": &fstest.MapFile{
 					Data: []byte(`
 {
   "models": {
     "user": {
       "model": {
-        "constraints": [
+        "version": "version"
+      },
+      "queries": {
+        "Model": [
           {
-            "kind": "UNIQUE",
-            "columns": []
+            "kind": "updeq",
+            "name": "ByID",
+            "conditions": [
+              {
+                "col": "userid"
+              }
+            ],
+            "affected": "count"
           }
         ]
       }
@@ -793,54 +16637,55 @@ type (
 					Mode:    filemode,
 					ModTime: now,
 				},
+			},
+			Err: ErrInvalidModel,
+		},
+		{
+			Name: "errors on unknown auto time field",
+			Fsys: fstest.MapFS{
 				"stuff.go": &fstest.MapFile{
 					Data: []byte(`package somepackage
 
 type (
 	//forge:model user
+	//forge:model:query user
 	Model struct {
-		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
 	}
 )
 `),
 					Mode:    filemode,
 					ModTime: now,
 				},
-			},
-			Err: ErrInvalidModel,
-		},
-		{
-			Name: "errors on invalid model constraint opt field",
-			Fsys: fstest.MapFS{
 				"model.json": &fstest.MapFile{
 					Data: []byte(`
 {
   "models": {
     "user": {
       "model": {
-        "constraints": [
+        "autoTime": {
+          "insert": [
+            "creation_time"
+          ]
+        }
+      },
+      "queries": {
+        "Model": [
           {
-            "kind": "UNIQUE",
-            "columns": ["bogus", "userid"]
+            "kind": "getoneeq",
+            "name": "ByID",
+            "conditions": [
+              {
+                "col": "userid"
+              }
+            ]
           }
         ]
       }
     }
   }
 }
-`),
-					Mode:    filemode,
-					ModTime: now,
-				},
-				"stuff.go": &fstest.MapFile{
-					Data: []byte(`package somepackage
-
-type (
-	//forge:model user
-	Model struct {
-		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
-	}
-)
 `),
 					Mode:    filemode,
 					ModTime: now,
@@ -849,108 +16694,177 @@ type (
 			Err: ErrInvalidModel,
 		},
 		{
-			Name: "errors on query directive without prefix arg",
+			Name: "errors on invalid auto time field type",
 			Fsys: fstest.MapFS{
 				"stuff.go": &fstest.MapFile{
 					Data: []byte(`package somepackage
 
+import (
+	"time"
+)
+
 type (
 	//forge:model user
-	//forge:model:query
+	//forge:model:query user
 	Model struct {
-		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Userid       string    ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username     string    ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+		CreationTime time.Time ` + "`" + `model:"creation_time,TIMESTAMP NOT NULL"` + "`" + `
+		UpdateTime   int64     ` + "`" + `model:"update_time,BIGINT NOT NULL"` + "`" + `
 	}
 )
 `),
 					Mode:    filemode,
 					ModTime: now,
 				},
-			},
-			Err: ErrInvalidFile,
-		},
-		{
-			Name: "errors on query directive without model def",
-			Fsys: fstest.MapFS{
-				"stuff.go": &fstest.MapFile{
-					Data: []byte(`package somepackage
-
-type (
-	//forge:model user
-	//forge:model:query dne
-	Model struct {
-		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
-	}
-)
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "model": {
+        "autoTime": {
+          "update": [
+            "username"
+          ]
+        }
+      },
+      "queries": {
+        "Model": [
+          {
+            "kind": "getoneeq",
+            "name": "ByID",
+            "conditions": [
+              {
+                "col": "userid"
+              }
+            ]
+          }
+        ]
+      }
+    }
+  }
+}
 `),
 					Mode:    filemode,
 					ModTime: now,
 				},
 			},
-			Err: ErrInvalidFile,
+			Err: ErrInvalidModel,
 		},
 		{
-			Name: "errors on query directive on non-typedef",
+			Name: "errors on unix auto time field with sql source",
 			Fsys: fstest.MapFS{
 				"stuff.go": &fstest.MapFile{
 					Data: []byte(`package somepackage
 
+import (
+	"time"
+)
+
 type (
 	//forge:model user
+	//forge:model:query user
 	Model struct {
-		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Userid       string    ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username     string    ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+		CreationTime time.Time ` + "`" + `model:"creation_time,TIMESTAMP NOT NULL"` + "`" + `
+		UpdateTime   int64     ` + "`" + `model:"update_time,BIGINT NOT NULL"` + "`" + `
 	}
 )
-
-//forge:model:query user
-const (
-	foo = "bar"
-)
 `),
 					Mode:    filemode,
 					ModTime: now,
 				},
-			},
-			Err: ErrInvalidFile,
-		},
-		{
-			Name: "errors on query directive on non-struct",
-			Fsys: fstest.MapFS{
-				"stuff.go": &fstest.MapFile{
-					Data: []byte(`package somepackage
-
-type (
-	//forge:model user
-	Model struct {
-		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
-	}
-
-	//forge:model:query user
-	Info []string
-)
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "model": {
+        "autoTime": {
+          "update": [
+            "update_time"
+          ],
+          "source": "sql"
+        }
+      },
+      "queries": {
+        "Model": [
+          {
+            "kind": "getoneeq",
+            "name": "ByID",
+            "conditions": [
+              {
+                "col": "userid"
+              }
+            ]
+          }
+        ]
+      }
+    }
+  }
+}
 `),
 					Mode:    filemode,
 					ModTime: now,
 				},
 			},
-			Err: ErrInvalidFile,
+			Err: ErrInvalidModel,
 		},
 		{
-			Name: "errors on query without fields",
+			Name: "errors on invalid auto time source",
 			Fsys: fstest.MapFS{
 				"stuff.go": &fstest.MapFile{
 					Data: []byte(`package somepackage
 
+import (
+	"time"
+)
+
 type (
 	//forge:model user
-	Model struct {
-		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
-	}
-
 	//forge:model:query user
-	Info struct {
-		Userid string
+	Model struct {
+		Userid       string    ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username     string    ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+		CreationTime time.Time ` + "`" + `model:"creation_time,TIMESTAMP NOT NULL"` + "`" + `
+		UpdateTime   int64     ` + "`" + `model:"update_time,BIGINT NOT NULL"` + "`" + `
 	}
 )
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "model": {
+        "autoTime": {
+          "insert": [
+            "creation_time"
+          ],
+          "source": "db"
+        }
+      },
+      "queries": {
+        "Model": [
+          {
+            "kind": "getoneeq",
+            "name": "ByID",
+            "conditions": [
+              {
+                "col": "userid"
+              }
+            ]
+          }
+        ]
+      }
+    }
+  }
+}
 `),
 					Mode:    filemode,
 					ModTime: now,
@@ -959,46 +16873,58 @@ type (
 			Err: ErrInvalidModel,
 		},
 		{
-			Name: "errors on query tag on multiple fields",
+			Name: "errors on duplicate auto time field",
 			Fsys: fstest.MapFS{
 				"stuff.go": &fstest.MapFile{
 					Data: []byte(`package somepackage
 
+import (
+	"time"
+)
+
 type (
 	//forge:model user
+	//forge:model:query user
 	Model struct {
-		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
-	}
-
-  //forge:model:query user
-	Info struct {
-		Userid, Other string ` + "`" + `model:"userid"` + "`" + `
+		Userid       string    ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username     string    ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+		CreationTime time.Time ` + "`" + `model:"creation_time,TIMESTAMP NOT NULL"` + "`" + `
+		UpdateTime   int64     ` + "`" + `model:"update_time,BIGINT NOT NULL"` + "`" + `
 	}
 )
 `),
 					Mode:    filemode,
 					ModTime: now,
 				},
-			},
-			Err: ErrInvalidFile,
-		},
-		{
-			Name: "errors on malformed query tag",
-			Fsys: fstest.MapFS{
-				"stuff.go": &fstest.MapFile{
-					Data: []byte(`package somepackage
-
-type (
-	//forge:model user
-	Model struct {
-		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
-	}
-
-	//forge:model:query user
-	Info struct {
-		Userid string ` + "`" + `model:""` + "`" + `
-	}
-)
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "model": {
+        "autoTime": {
+          "insert": [
+            "creation_time",
+            "creation_time"
+          ]
+        }
+      },
+      "queries": {
+        "Model": [
+          {
+            "kind": "getoneeq",
+            "name": "ByID",
+            "conditions": [
+              {
+                "col": "userid"
+              }
+            ]
+          }
+        ]
+      }
+    }
+  }
+}
 `),
 					Mode:    filemode,
 					ModTime: now,
@@ -1007,22 +16933,60 @@ type (
 			Err: ErrInvalidModel,
 		},
 		{
-			Name: "errors on invalid query tag field",
+			Name: "errors on returning auto time field",
 			Fsys: fstest.MapFS{
 				"stuff.go": &fstest.MapFile{
 					Data: []byte(`package somepackage
 
+import (
+	"time"
+)
+
 type (
 	//forge:model user
-	Model struct {
-		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
-	}
-
 	//forge:model:query user
-	Info struct {
-		Userid string ` + "`" + `model:"bogus"` + "`" + `
+	Model struct {
+		Userid       string    ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username     string    ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+		CreationTime time.Time ` + "`" + `model:"creation_time,TIMESTAMP NOT NULL"` + "`" + `
+		UpdateTime   int64     ` + "`" + `model:"update_time,BIGINT NOT NULL"` + "`" + `
 	}
 )
+`),
+					Mode:    filemode,
+					ModTime: now,
+				},
+				"model.json": &fstest.MapFile{
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "model": {
+        "autoTime": {
+          "insert": [
+            "creation_time"
+          ]
+        },
+        "returning": [
+          "creation_time"
+        ]
+      },
+      "queries": {
+        "Model": [
+          {
+            "kind": "getoneeq",
+            "name": "ByID",
+            "conditions": [
+              {
+                "col": "userid"
+              }
+            ]
+          }
+        ]
+      }
+    }
+  }
+}
 `),
 					Mode:    filemode,
 					ModTime: now,
@@ -1031,7 +16995,8 @@ type (
 			Err: ErrInvalidModel,
 		},
 		{
-			Name: "errors on no queries",
+			Name:    "errors on affected notfound update for mysql dialect",
+			Dialect: DialectMySQL{},
 			Fsys: fstest.MapFS{
 				"stuff.go": &fstest.MapFile{
 					Data: []byte(`package somepackage
@@ -1040,7 +17005,8 @@ type (
 	//forge:model user
 	//forge:model:query user
 	Model struct {
-		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
 	}
 )
 `),
@@ -1048,24 +17014,48 @@ type (
 					ModTime: now,
 				},
 				"model.json": &fstest.MapFile{
-					Data:    []byte(`{}`),
+					Data: []byte(`
+{
+  "models": {
+    "user": {
+      "queries": {
+        "Model": [
+          {
+            "kind": "updeq",
+            "name": "ByID",
+            "conditions": [
+              {
+                "col": "userid"
+              }
+            ],
+            "affected": "notfound"
+          }
+        ]
+      }
+    }
+  }
+}
+`),
 					Mode:    filemode,
 					ModTime: now,
 				},
 			},
-			Err: ErrInvalidModel,
+			Err: ErrInvalidDialect,
 		},
 		{
-			Name: "errors on missing query name",
+			Name: "errors on eqany condition on slice field",
 			Fsys: fstest.MapFS{
 				"stuff.go": &fstest.MapFile{
 					Data: []byte(`package somepackage
 
 type (
-	//forge:model user
-	//forge:model:query user
+	//forge:model post
+	//forge:model:query post
 	Model struct {
-		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Postid string   ` + "`" + `model:"postid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Status string   ` + "`" + `model:"status,VARCHAR(31) NOT NULL"` + "`" + `
+		Tags   []string ` + "`" + `model:"tags,TEXT[] NOT NULL"` + "`" + `
+		Meta   []byte   ` + "`" + `model:"meta,BYTEA NOT NULL"` + "`" + `
 	}
 )
 `),
@@ -1076,14 +17066,17 @@ type (
 					Data: []byte(`
 {
   "models": {
-    "user": {
+    "post": {
       "queries": {
         "Model": [
           {
-            "kind": "getoneeq",
-            "name": "",
+            "kind": "counteq",
+            "name": "ByCond",
             "conditions": [
-              {"col": "userid"}
+              {
+                "col": "tags",
+                "cond": "eqany"
+              }
             ]
           }
         ]
@@ -1099,16 +17092,19 @@ type (
 			Err: ErrInvalidModel,
 		},
 		{
-			Name: "errors on invalid query kind",
+			Name: "errors on jsoncontains condition on non-json field",
 			Fsys: fstest.MapFS{
 				"stuff.go": &fstest.MapFile{
 					Data: []byte(`package somepackage
 
 type (
-	//forge:model user
-	//forge:model:query user
+	//forge:model post
+	//forge:model:query post
 	Model struct {
-		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Postid string   ` + "`" + `model:"postid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Status string   ` + "`" + `model:"status,VARCHAR(31) NOT NULL"` + "`" + `
+		Tags   []string ` + "`" + `model:"tags,TEXT[] NOT NULL"` + "`" + `
+		Meta   []byte   ` + "`" + `model:"meta,BYTEA NOT NULL"` + "`" + `
 	}
 )
 `),
@@ -1119,14 +17115,17 @@ type (
 					Data: []byte(`
 {
   "models": {
-    "user": {
+    "post": {
       "queries": {
         "Model": [
           {
-            "kind": "bogus",
-            "name": "ByID",
+            "kind": "counteq",
+            "name": "ByCond",
             "conditions": [
-              {"col": "userid"}
+              {
+                "col": "meta",
+                "cond": "jsoncontains"
+              }
             ]
           }
         ]
@@ -1142,16 +17141,19 @@ type (
 			Err: ErrInvalidModel,
 		},
 		{
-			Name: "errors on missing required query conditions",
+			Name: "errors on jsonhaskey condition on non-json field",
 			Fsys: fstest.MapFS{
 				"stuff.go": &fstest.MapFile{
 					Data: []byte(`package somepackage
 
 type (
-	//forge:model user
-	//forge:model:query user
+	//forge:model post
+	//forge:model:query post
 	Model struct {
-		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Postid string   ` + "`" + `model:"postid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Status string   ` + "`" + `model:"status,VARCHAR(31) NOT NULL"` + "`" + `
+		Tags   []string ` + "`" + `model:"tags,TEXT[] NOT NULL"` + "`" + `
+		Meta   []byte   ` + "`" + `model:"meta,BYTEA NOT NULL"` + "`" + `
 	}
 )
 `),
@@ -1162,12 +17164,18 @@ type (
 					Data: []byte(`
 {
   "models": {
-    "user": {
+    "post": {
       "queries": {
         "Model": [
           {
-            "kind": "getoneeq",
-            "name": "ByID"
+            "kind": "counteq",
+            "name": "ByCond",
+            "conditions": [
+              {
+                "col": "status",
+                "cond": "jsonhaskey"
+              }
+            ]
           }
         ]
       }
@@ -1182,7 +17190,7 @@ type (
 			Err: ErrInvalidModel,
 		},
 		{
-			Name: "errors on providing conditions when not accepted",
+			Name: "errors on incremental update of versioned model",
 			Fsys: fstest.MapFS{
 				"stuff.go": &fstest.MapFile{
 					Data: []byte(`package somepackage
@@ -1191,7 +17199,9 @@ type (
 	//forge:model user
 	//forge:model:query user
 	Model struct {
-		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Userid  string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Score   int    ` + "`" + `model:"score,INT NOT NULL"` + "`" + `
+		Version int64  ` + "`" + `model:"version,BIGINT NOT NULL"` + "`" + `
 	}
 )
 `),
@@ -1203,10 +17213,13 @@ type (
 {
   "models": {
     "user": {
+      "model": {
+        "version": "version"
+      },
       "queries": {
         "Model": [
           {
-            "kind": "getgroup",
+            "kind": "increq",
             "name": "ByID",
             "conditions": [
               {"col": "userid"}
@@ -1225,16 +17238,23 @@ type (
 			Err: ErrInvalidModel,
 		},
 		{
-			Name: "errors on invalid query cond",
+			Name: "errors on patch update of versioned model",
 			Fsys: fstest.MapFS{
 				"stuff.go": &fstest.MapFile{
 					Data: []byte(`package somepackage
 
 type (
 	//forge:model user
-	//forge:model:query user
 	Model struct {
-		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+		Version  int64  ` + "`" + `model:"version,BIGINT NOT NULL"` + "`" + `
+	}
+
+	//forge:model:query user
+	Patch struct {
+		Userid   string  ` + "`" + `model:"userid"` + "`" + `
+		Username *string ` + "`" + `model:"username"` + "`" + `
 	}
 )
 `),
@@ -1246,13 +17266,16 @@ type (
 {
   "models": {
     "user": {
+      "model": {
+        "version": "version"
+      },
       "queries": {
-        "Model": [
+        "Patch": [
           {
-            "kind": "getoneeq",
+            "kind": "patcheq",
             "name": "ByID",
             "conditions": [
-              {"col": "userid", "cond": "bogus"}
+              {"col": "userid"}
             ]
           }
         ]
@@ -1268,16 +17291,23 @@ type (
 			Err: ErrInvalidModel,
 		},
 		{
-			Name: "errors on invalid query cond field",
+			Name: "errors on increment of non numeric field",
 			Fsys: fstest.MapFS{
 				"stuff.go": &fstest.MapFile{
 					Data: []byte(`package somepackage
 
 type (
-	//forge:model user
-	//forge:model:query user
+	//forge:model post
 	Model struct {
-		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Postid string ` + "`" + `model:"postid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Userid string ` + "`" + `model:"userid,VARCHAR(31) NOT NULL"` + "`" + `
+		Views  int64  ` + "`" + `model:"views,BIGINT NOT NULL"` + "`" + `
+	}
+
+	//forge:model:query post
+	Views struct {
+		Userid string ` + "`" + `model:"userid"` + "`" + `
+		Views  int64  ` + "`" + `model:"views"` + "`" + `
 	}
 )
 `),
@@ -1288,14 +17318,14 @@ type (
 					Data: []byte(`
 {
   "models": {
-    "user": {
+    "post": {
       "queries": {
-        "Model": [
+        "Views": [
           {
-            "kind": "getoneeq",
+            "kind": "increq",
             "name": "ByID",
             "conditions": [
-              {"col": "bogus"}
+              {"col": "postid"}
             ]
           }
         ]
@@ -1311,16 +17341,21 @@ type (
 			Err: ErrInvalidModel,
 		},
 		{
-			Name: "errors on providing query order when not accepted",
+			Name: "errors on increment of condition field",
 			Fsys: fstest.MapFS{
 				"stuff.go": &fstest.MapFile{
 					Data: []byte(`package somepackage
 
 type (
-	//forge:model user
-	//forge:model:query user
+	//forge:model post
 	Model struct {
-		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Postid string ` + "`" + `model:"postid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Views  int64  ` + "`" + `model:"views,BIGINT NOT NULL"` + "`" + `
+	}
+
+	//forge:model:query post
+	Views struct {
+		Views int64 ` + "`" + `model:"views"` + "`" + `
 	}
 )
 `),
@@ -1331,17 +17366,15 @@ type (
 					Data: []byte(`
 {
   "models": {
-    "user": {
+    "post": {
       "queries": {
-        "Model": [
+        "Views": [
           {
-            "kind": "getoneeq",
-            "name": "ByID",
+            "kind": "increq",
+            "name": "ByViews",
             "conditions": [
-              {"col": "userid"}
-            ],
-            "order": [
-              {"col": "userid"}
+              {"col": "postid"},
+              {"col": "views", "cond": "lt"}
             ]
           }
         ]
@@ -1357,7 +17390,7 @@ type (
 			Err: ErrInvalidModel,
 		},
 		{
-			Name: "errors on invalid query order field",
+			Name: "errors on upsert of versioned model",
 			Fsys: fstest.MapFS{
 				"stuff.go": &fstest.MapFile{
 					Data: []byte(`package somepackage
@@ -1366,7 +17399,9 @@ type (
 	//forge:model user
 	//forge:model:query user
 	Model struct {
-		Userid string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Userid   string ` + "`" + `model:"userid,VARCHAR(31) PRIMARY KEY"` + "`" + `
+		Username string ` + "`" + `model:"username,VARCHAR(255) NOT NULL"` + "`" + `
+		Version  int64  ` + "`" + `model:"version,BIGINT NOT NULL"` + "`" + `
 	}
 )
 `),
@@ -1378,14 +17413,15 @@ type (
 {
   "models": {
     "user": {
+      "model": {
+        "version": "version"
+      },
       "queries": {
         "Model": [
           {
-            "kind": "getgroup",
-            "name": "All",
-            "order": [
-              {"col": "bogus"}
-            ]
+            "kind": "upsert",
+            "name": "ByID",
+            "conflict": ["userid"]
           }
         ]
       }
@@ -1415,8 +17451,10 @@ type (
 				Ignore:            `_again\.go$`,
 				ModelDirective:    "forge:model",
 				QueryDirective:    "forge:model:query",
+				JoinDirective:     "forge:model:join",
 				ModelTag:          "model",
 				PlaceholderPrefix: "$",
+				Dialect:           tc.Dialect,
 			}, ExecEnv{
 				GoPackage: "somepackage",
 			})
@@ -1459,6 +17497,7 @@ type (
 				Ignore:            `_again\.go$`,
 				ModelDirective:    "forge:model",
 				QueryDirective:    "forge:model:query",
+				JoinDirective:     "forge:model:join",
 				ModelTag:          "model",
 				PlaceholderPrefix: "$",
 			}, ExecEnv{
@@ -1482,6 +17521,7 @@ type (
 				Ignore:            `\y`,
 				ModelDirective:    "forge:model",
 				QueryDirective:    "forge:model:query",
+				JoinDirective:     "forge:model:join",
 				ModelTag:          "model",
 				PlaceholderPrefix: "$",
 			}, ExecEnv{
@@ -1521,6 +17561,7 @@ type (
 			Ignore:            "",
 			ModelDirective:    "forge:model",
 			QueryDirective:    "forge:model:query",
+			JoinDirective:     "forge:model:join",
 			ModelTag:          "model",
 			PlaceholderPrefix: "$",
 		}, ExecEnv{
@@ -1530,6 +17571,83 @@ type (
 	})
 }
 
+type (
+	testBracketDialect struct {
+		DialectSQLite
+	}
+)
+
+func (d testBracketDialect) Name() string {
+	return "bracket"
+}
+
+func (d testBracketDialect) Placeholder() string {
+	return ":%d"
+}
+
+func (d testBracketDialect) InListElem() string {
+	return ":%d"
+}
+
+func (d testBracketDialect) Ident(name string) string {
+	return "[" + name + "]"
+}
+
+func (d testBracketDialect) Setup(table string, defs []string, indicies []SQLIndex) []string {
+	return setupCreateIndex(d, table, defs, indicies)
+}
+
+func TestParseDialect(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		Name    string
+		Prefix  string
+		Dialect Dialect
+		Err     error
+	}{
+		{
+			Name:    "",
+			Prefix:  "$",
+			Dialect: DialectPostgres{PlaceholderPrefix: "$"},
+		},
+		{
+			Name:    "postgres",
+			Prefix:  "$",
+			Dialect: DialectPostgres{PlaceholderPrefix: "$"},
+		},
+		{
+			Name:    "sqlite",
+			Dialect: DialectSQLite{},
+		},
+		{
+			Name:    "mysql",
+			Dialect: DialectMySQL{},
+		},
+		{
+			Name: "bogus",
+			Err:  ErrInvalidDialect,
+		},
+	} {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			assert := require.New(t)
+
+			d, err := ParseDialect(tc.Name, tc.Prefix)
+			if tc.Err != nil {
+				assert.ErrorIs(err, tc.Err)
+				return
+			}
+			assert.NoError(err)
+			assert.Equal(tc.Dialect, d)
+			if tc.Name != "" {
+				assert.Equal(tc.Name, d.Name())
+			}
+		})
+	}
+}
+
 func TestQueryKindString(t *testing.T) {
 	t.Parallel()
 
@@ -1559,6 +17677,46 @@ func TestQueryKindString(t *testing.T) {
 			Kind:   queryKindDelEq,
 			String: "deleq",
 		},
+		{
+			Kind:   queryKindUpsert,
+			String: "upsert",
+		},
+		{
+			Kind:   queryKindCount,
+			String: "count",
+		},
+		{
+			Kind:   queryKindCountEq,
+			String: "counteq",
+		},
+		{
+			Kind:   queryKindExistsEq,
+			String: "existseq",
+		},
+		{
+			Kind:   queryKindGetGroupKeyset,
+			String: "getgroupkeyset",
+		},
+		{
+			Kind:   queryKindPatchEq,
+			String: "patcheq",
+		},
+		{
+			Kind:   queryKindIncrEq,
+			String: "increq",
+		},
+		{
+			Kind:   queryKindAggregate,
+			String: "aggregate",
+		},
+		{
+			Kind:   queryKindRaw,
+			String: "raw",
+		},
+		{
+			Kind:   queryKindGetMapIn,
+			String: "getmapin",
+		},
 		{
 			Kind:   queryKindUnknown,
 			String: "unknown",
@@ -1593,6 +17751,10 @@ func TestError(t *testing.T) {
 			Err:    ErrInvalidModel,
 			String: "Invalid model",
 		},
+		{
+			Err:    ErrInvalidDialect,
+			String: "Invalid dialect",
+		},
 	} {
 		assert.Equal(tc.String, tc.Err.Error())
 	}
@@ -1,36 +1,60 @@
 package model
 
 const templateUpdEq = `
-func (t *{{.Prefix}}ModelTable) Upd{{.ModelIdent}}{{.Name}}(ctx context.Context, d sqldb.Executor, m *{{.ModelIdent}}, {{.SQLCond.IdentParams}}) error {
-	{{- if .SQLCond.ArrIdentArgs }}
-	paramCount := {{.SQLCond.ParamCount}}
-	args := make([]interface{}, 0, paramCount{{with .SQLCond.ArrIdentArgsLen}}+{{.}}{{end}})
-	args = append(args, {{.SQL.Idents}}{{if .SQLCond.IdentArgs}}, {{.SQLCond.IdentArgs}}{{end}})
+{{- define "updatetime" }}
+	{{- if .SQLUpdate.Clock }}
+	now := t.now()
+	{{- range .SQLUpdate.Now }}
+	m.{{.Ident}} = {{.Value}}
 	{{- end }}
-	{{- range .SQLCond.ArrIdentArgs }}
-	var placeholders{{.}} string
-	{
-		placeholders := make([]string, 0, len({{.}}))
-		for _, i := range {{.}} {
-			paramCount++
-			placeholders = append(placeholders, fmt.Sprintf("($%d)", paramCount))
-			args = append(args, i)
-		}
-		placeholders{{.}} = strings.Join(placeholders, ", ")
-	}
 	{{- end }}
-	_, err := d.ExecContext(ctx, "UPDATE "+t.TableName+" SET {{if eq .SQL.NumDBNames 1 -}}
-	{{.SQL.DBNames -}}
-	{{else -}}
-	({{.SQL.DBNames}})
-	{{- end}} = {{if eq .SQL.NumDBNames 1 -}}
-	{{.SQL.Placeholders -}}
-	{{else -}}
-	({{.SQL.Placeholders}})
-	{{- end}} WHERE {{.SQLCond.DBCond}};", {{if .SQLCond.ArrIdentArgs}}args...{{else}}{{.SQL.Idents}}, {{.SQLCond.IdentArgs}}{{end}})
+{{- end }}
+{{- if .SQLReturn.Ident }}
+func (t *{{.Prefix}}ModelTable) Upd{{.ModelIdent}}{{.Name}}(ctx context.Context, d sqldb.Executor, m *{{.ModelIdent}}{{template "condparams" .}}) (_ []{{.SQLReturn.Ident}}, retErr error) {
+	{{- template "updatetime" . }}
+	{{- template "condargs" . }}
+	rows, err := d.QueryContext(ctx, "UPDATE {{.SQL.Table}} SET {{.SQL.UpdateSet}} WHERE {{.SQLCond.DBCond}}{{.SQLReturn.Returning}};"{{template "condexecargs" .}})
+	{{- template "returningrows" . }}
+}
+{{- else if eq .Affected "count" }}
+func (t *{{.Prefix}}ModelTable) Upd{{.ModelIdent}}{{.Name}}(ctx context.Context, d sqldb.Executor, m *{{.ModelIdent}}{{template "condparams" .}}) (int64, error) {
+	{{- template "updatetime" . }}
+	{{- template "condargs" . }}
+	res, err := d.ExecContext(ctx, "UPDATE {{.SQL.Table}} SET {{.SQL.UpdateSet}} WHERE {{.SQLCond.DBCond}};"{{template "condexecargs" .}})
+	{{- template "affectedcount" . }}
+}
+{{- else if .SQLUpdate.Version }}
+func (t *{{.Prefix}}ModelTable) Upd{{.ModelIdent}}{{.Name}}(ctx context.Context, d sqldb.Executor, m *{{.ModelIdent}}{{template "condparams" .}}) error {
+	{{- template "updatetime" . }}
+	{{- template "condargs" . }}
+	res, err := d.ExecContext(ctx, "UPDATE {{.SQL.Table}} SET {{.SQL.UpdateSet}} WHERE {{.SQLCond.DBCond}};"{{template "condexecargs" .}})
 	if err != nil {
 		return err
 	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return sqldb.ErrConflict
+	}
+	m.{{.SQLUpdate.Version}}++
 	return nil
 }
+{{- else }}
+func (t *{{.Prefix}}ModelTable) Upd{{.ModelIdent}}{{.Name}}(ctx context.Context, d sqldb.Executor, m *{{.ModelIdent}}{{template "condparams" .}}) error {
+	{{- template "updatetime" . }}
+	{{- template "condargs" . }}
+	{{- if eq .Affected "notfound" }}
+	res, err := d.ExecContext(ctx, "UPDATE {{.SQL.Table}} SET {{.SQL.UpdateSet}} WHERE {{.SQLCond.DBCond}};"{{template "condexecargs" .}})
+	{{- template "affectednotfound" . }}
+	{{- else }}
+	_, err := d.ExecContext(ctx, "UPDATE {{.SQL.Table}} SET {{.SQL.UpdateSet}} WHERE {{.SQLCond.DBCond}};"{{template "condexecargs" .}})
+	if err != nil {
+		return err
+	}
+	return nil
+	{{- end }}
+}
+{{- end }}
 `
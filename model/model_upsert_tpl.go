@@ -0,0 +1,48 @@
+package model
+
+const templateUpsert = `
+func (t *{{.Prefix}}ModelTable) Upsert{{.ModelIdent}}{{.Name}}(ctx context.Context, d sqldb.Executor, m *{{.ModelIdent}}) error {
+	{{- if .SQLUpsert.Clock }}
+	now := t.now()
+	{{- range .SQLUpsert.Now }}
+	m.{{.Ident}} = {{.Value}}
+	{{- end }}
+	{{- end }}
+	_, err := d.ExecContext(ctx, "INSERT INTO {{.SQL.Table}} ({{.SQLUpsert.DBNames}}) VALUES ({{.SQLUpsert.Placeholders}}){{.SQLUpsert.DBConflict}};", {{.SQLUpsert.Idents}})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *{{.Prefix}}ModelTable) Upsert{{.ModelIdent}}{{.Name}}Bulk(ctx context.Context, d sqldb.Executor, models []*{{.ModelIdent}}) error {
+	placeholders := make([]string, 0, len(models))
+	args := make([]interface{}, 0, len(models)*{{.SQLUpsert.ColNum}})
+	{{- if .SQLUpsert.Clock }}
+	now := t.now()
+	{{- end }}
+	{{- if .SQL.Positional }}
+	for _, m := range models {
+		{{- range .SQLUpsert.Now }}
+		m.{{.Ident}} = {{.Value}}
+		{{- end }}
+		placeholders = append(placeholders, "({{.SQLUpsert.PlaceholderTpl}})")
+		args = append(args, {{.SQLUpsert.Idents}})
+	}
+	{{- else }}
+	for c, m := range models {
+		{{- range .SQLUpsert.Now }}
+		m.{{.Ident}} = {{.Value}}
+		{{- end }}
+		n := c * {{.SQLUpsert.ColNum}}
+		placeholders = append(placeholders, fmt.Sprintf("({{.SQLUpsert.PlaceholderTpl}})", {{.SQLUpsert.PlaceholderCount}}))
+		args = append(args, {{.SQLUpsert.Idents}})
+	}
+	{{- end }}
+	_, err := d.ExecContext(ctx, "INSERT INTO {{.SQL.Table}} ({{.SQLUpsert.DBNames}}) VALUES "+strings.Join(placeholders, ", ")+"{{.SQLUpsert.DBConflict}};", args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+`
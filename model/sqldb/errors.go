@@ -0,0 +1,22 @@
+package sqldb
+
+var (
+	// ErrNotFound is returned when a query affects no rows
+	ErrNotFound errNotFound
+	// ErrConflict is returned when a versioned update affects no rows because
+	// the version of the row has changed
+	ErrConflict errConflict
+)
+
+type (
+	errNotFound struct{}
+	errConflict struct{}
+)
+
+func (e errNotFound) Error() string {
+	return "Not found"
+}
+
+func (e errConflict) Error() string {
+	return "Version conflict"
+}
@@ -0,0 +1,24 @@
+package sqldb
+
+import (
+	"strings"
+)
+
+const (
+	// LikeEscape is the escape character of patterns escaped by [EscapeLike].
+	// A backslash is not used since it is also an escape character in MySQL
+	// string literals.
+	LikeEscape = "!"
+)
+
+var likeEscaper = strings.NewReplacer(
+	LikeEscape, LikeEscape+LikeEscape,
+	"%", LikeEscape+"%",
+	"_", LikeEscape+"_",
+)
+
+// EscapeLike escapes the wildcards of s such that it matches literally in a
+// LIKE pattern with an ESCAPE of [LikeEscape]
+func EscapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}